@@ -0,0 +1,13 @@
+package config
+
+import "os"
+
+// provider pembayaran untuk metode QRIS: "qris" atau "mock" (untuk pengujian lokal, harus diset eksplisit).
+// Kosong berarti pembayaran QRIS belum tersedia.
+var PaymentProvider string = os.Getenv("PAYMENT_PROVIDER")
+
+// payload QRIS statis merchant yang diubah menjadi QRIS dinamis per order
+var QRISStaticPayload string = os.Getenv("QRIS_STATIC_PAYLOAD")
+
+// secret HMAC untuk memverifikasi callback dari payment gateway
+var PaymentCallbackSecret string = os.Getenv("PAYMENT_CALLBACK_SECRET")
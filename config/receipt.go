@@ -0,0 +1,13 @@
+package config
+
+import "os"
+
+// identitas toko yang dicetak di kepala struk
+var ShopName string = "Logic Coffee"
+
+var ShopAddress string = os.Getenv("SHOP_ADDRESS")
+
+var ShopPhone string = os.Getenv("SHOP_PHONE")
+
+// halaman status pesanan di frontend, nomor order ditambahkan di belakang url ini untuk QR code struk
+var OrderStatusURL string = "https://logiccoffee.id.biz.id/status/?order="
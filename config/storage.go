@@ -0,0 +1,61 @@
+package config
+
+import "os"
+
+// backend penyimpanan file upload: "github" (default), "local" atau "s3"
+var StorageBackend string = os.Getenv("STORAGE_BACKEND")
+
+// url publik file, untuk local berisi alamat route /files (misalnya https://api.logiccoffee.id/files),
+// untuk s3 opsional jika bucket dilayani lewat CDN
+var StoragePublicURL string = os.Getenv("STORAGE_PUBLIC_URL")
+
+// repository GitHub tempat gambar disimpan, token memakai GHAccessToken
+var StorageGitHubOrg string = envOr("STORAGE_GITHUB_ORG", "logiccoffee")
+
+var StorageGitHubRepo string = envOr("STORAGE_GITHUB_REPO", "img")
+
+// repository GitHub untuk foto profil dan cover buku, terpisah dari gambar menu seperti sebelumnya
+var StorageGitHubProjectOrg string = envOr("STORAGE_GITHUB_PROJECT_ORG", "penerbitbukupedia")
+
+var StorageGitHubProfileRepo string = envOr("STORAGE_GITHUB_PROFILE_REPO", "profile")
+
+var StorageGitHubKatalogRepo string = envOr("STORAGE_GITHUB_KATALOG_REPO", "katalog")
+
+// author commit upload, kosong berarti atas nama pemilik token
+var StorageGitHubAuthorName string = os.Getenv("STORAGE_GITHUB_AUTHOR_NAME")
+
+var StorageGitHubAuthorEmail string = os.Getenv("STORAGE_GITHUB_AUTHOR_EMAIL")
+
+// folder penyimpanan untuk backend local
+var StorageLocalDir string = envOr("STORAGE_LOCAL_DIR", "uploads")
+
+// bucket S3 atau MinIO, endpoint contoh: http://localhost:9000
+var StorageS3Endpoint string = os.Getenv("STORAGE_S3_ENDPOINT")
+
+var StorageS3Region string = envOr("STORAGE_S3_REGION", "us-east-1")
+
+var StorageS3Bucket string = os.Getenv("STORAGE_S3_BUCKET")
+
+var StorageS3AccessKey string = os.Getenv("STORAGE_S3_ACCESS_KEY")
+
+var StorageS3SecretKey string = os.Getenv("STORAGE_S3_SECRET_KEY")
+
+// penyimpanan dokumen privat (draft buku, SPK, SPI): "github" (default), "local" atau "s3".
+// Dokumen hanya dibuka lewat /download/draft setelah cek akses, tidak pernah lewat /files.
+var StorageDocumentBackend string = os.Getenv("STORAGE_DOCUMENT_BACKEND")
+
+// repository GitHub privat untuk dokumen, org memakai StorageGitHubProjectOrg
+var StorageDocumentGitHubRepo string = envOr("STORAGE_DOCUMENT_GITHUB_REPO", "draft")
+
+// folder dokumen untuk backend local, harus di luar StorageLocalDir
+var StorageDocumentLocalDir string = envOr("STORAGE_DOCUMENT_LOCAL_DIR", "documents")
+
+// bucket privat untuk backend s3, endpoint dan kunci akses sama dengan bucket gambar
+var StorageDocumentS3Bucket string = os.Getenv("STORAGE_DOCUMENT_S3_BUCKET")
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,4 @@
+package config
+
+// halaman pesan dine-in di frontend, token QR meja ditambahkan di belakang url ini
+var TableOrderURL string = envOr("TABLE_ORDER_URL", "https://logiccoffee.id.biz.id/meja/?token=")
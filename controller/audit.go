@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetAuditLogs - Riwayat perubahan data per halaman, terbaru dulu.
+// Query: ?entity=menu&entity_id=&entity_key=&action=update&actor=&from=2024-10-01&to=2024-10-31&page=&limit=&cursor=
+func GetAuditLogs(respw http.ResponseWriter, req *http.Request) {
+	params := req.URL.Query()
+	q, err := atdb.ParsePageQuery(params, "created_at", true, "created_at")
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Parameter Tidak Valid"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusBadRequest, respn)
+		return
+	}
+	from, to, err := atdb.ParseDateRange(params)
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Format tanggal harus YYYY-MM-DD"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusBadRequest, respn)
+		return
+	}
+
+	filter := bson.M{}
+	for _, field := range []string{"entity", "entity_key", "action", "role"} {
+		if value := params.Get(field); value != "" {
+			filter[field] = value
+		}
+	}
+	if entityID := params.Get("entity_id"); entityID != "" {
+		objectID, err := primitive.ObjectIDFromHex(entityID)
+		if err != nil {
+			var respn model.Response
+			respn.Status = "Error: entity_id tidak valid"
+			at.WriteJSON(respw, http.StatusBadRequest, respn)
+			return
+		}
+		filter["entity_id"] = objectID
+	}
+	atdb.MatchText(filter, params.Get("actor"), "actor")
+	atdb.MatchDateRange(filter, "created_at", from, to)
+
+	logs, page, err := atdb.GetPagedDocs[model.AuditLog](config.Mongoconn, audit.Collection, filter, q)
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Gagal mengambil audit log"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusInternalServerError, respn)
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":     "success",
+		"message":    "Audit log berhasil diambil",
+		"data":       logs,
+		"pagination": page,
+	})
+}
+
+// softDeleteDoc - Soft delete dokumen :id di collection (menu, category, banner) lalu mencatatnya di audit log.
+// label dipakai untuk pesan respons, misalnya "Menu".
+func softDeleteDoc[T any](respw http.ResponseWriter, req *http.Request, collection, label string) {
+	user, _ := rbac.CurrentUser(req)
+
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: ID " + label + " tidak valid"
+		at.WriteJSON(respw, http.StatusBadRequest, respn)
+		return
+	}
+	filter := bson.M{"_id": objectID}
+	before, err := atdb.GetOneDoc[T](config.Mongoconn, collection, atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: " + label + " tidak ditemukan"
+		at.WriteJSON(respw, http.StatusNotFound, respn)
+		return
+	}
+
+	result, err := atdb.SoftDeleteOneDoc(config.Mongoconn, collection, filter, user.Name)
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Gagal menghapus " + label
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusInternalServerError, respn)
+		return
+	}
+	if result.MatchedCount == 0 {
+		var respn model.Response
+		respn.Status = "Error: " + label + " tidak ditemukan"
+		at.WriteJSON(respw, http.StatusNotFound, respn)
+		return
+	}
+	after, _ := atdb.GetOneDoc[T](config.Mongoconn, collection, filter)
+	audit.Record(config.Mongoconn, req, model.AuditDelete, collection, objectID, before, after)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": label + " berhasil dihapus, bisa dikembalikan lewat restore",
+		"user":    user.Name,
+		"data":    map[string]interface{}{"id": objectID.Hex()},
+	})
+}
+
+// restoreDoc - Mengembalikan dokumen :id yang sudah di-soft delete lalu mencatatnya di audit log
+func restoreDoc[T any](respw http.ResponseWriter, req *http.Request, collection, label string) {
+	user, _ := rbac.CurrentUser(req)
+
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: ID " + label + " tidak valid"
+		at.WriteJSON(respw, http.StatusBadRequest, respn)
+		return
+	}
+	filter := bson.M{"_id": objectID}
+	before, err := atdb.GetOneDoc[T](config.Mongoconn, collection, filter)
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: " + label + " tidak ditemukan"
+		at.WriteJSON(respw, http.StatusNotFound, respn)
+		return
+	}
+
+	result, err := atdb.RestoreOneDoc(config.Mongoconn, collection, filter)
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Gagal mengembalikan " + label
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusInternalServerError, respn)
+		return
+	}
+	if result.MatchedCount == 0 {
+		var respn model.Response
+		respn.Status = "Error: " + label + " tidak sedang dihapus"
+		at.WriteJSON(respw, http.StatusConflict, respn)
+		return
+	}
+	after, err := atdb.GetOneDoc[T](config.Mongoconn, collection, filter)
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Gagal mengambil " + label
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusInternalServerError, respn)
+		return
+	}
+	audit.Record(config.Mongoconn, req, model.AuditRestore, collection, objectID, before, after)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": label + " berhasil dikembalikan",
+		"user":    user.Name,
+		"data":    after,
+	})
+}
@@ -7,24 +7,17 @@ import (
 	"github.com/gocroot/config"
 	"github.com/gocroot/helper/at"
 	"github.com/gocroot/helper/atdb"
-	"github.com/gocroot/helper/watoken"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/imageproc"
+	"github.com/gocroot/helper/rbac"
 	"github.com/gocroot/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func CreateBanner(respw http.ResponseWriter, req *http.Request) {
-	// Verifikasi Token Pengguna
-	payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: Token Tidak Valid"
-		respn.Info = at.GetSecretFromHeader(req)
-		respn.Location = "Decode Token Error"
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusForbidden, respn)
-		return
-	}
+	// User sudah divalidasi dan disimpan ke context oleh PermissionMiddleware
+	user, _ := rbac.CurrentUser(req)
 
 	var banner model.Banner
 	if err := json.NewDecoder(req.Body).Decode(&banner); err != nil {
@@ -36,7 +29,7 @@ func CreateBanner(respw http.ResponseWriter, req *http.Request) {
 	}
 
 	// Simpan Banner ke Database
-	_, err = atdb.InsertOneDoc(config.Mongoconn, "banner", banner)
+	insertedID, err := atdb.InsertOneDoc(config.Mongoconn, "banner", banner)
 	if err != nil {
 		var respn model.Response
 		respn.Status = "Error: Gagal Insert Database"
@@ -44,19 +37,21 @@ func CreateBanner(respw http.ResponseWriter, req *http.Request) {
 		at.WriteJSON(respw, http.StatusNotModified, respn)
 		return
 	}
+	banner.ID = insertedID
+	audit.Record(config.Mongoconn, req, model.AuditCreate, "banner", banner.ID, nil, banner)
 
 	// Response sukses
 	response := map[string]interface{}{
 		"status":  "success",
 		"message": "Banner berhasil ditambahkan",
-		"name":    payload.Alias,
+		"name":    user.Name,
 		"data":    banner,
 	}
 	at.WriteJSON(respw, http.StatusOK, response)
 }
 
 func GetAllBanner(respw http.ResponseWriter, req *http.Request) {
-	data, err := atdb.GetAllDoc[[]model.Banner](config.Mongoconn, "banner", primitive.M{})
+	data, err := atdb.GetAllDoc[[]model.Banner](config.Mongoconn, "banner", atdb.ExcludeDeleted(bson.M{}))
 	if err != nil {
 		var respn model.Response
 		respn.Status = "Error: Banner tidak ditemukan"
@@ -76,9 +71,10 @@ func GetAllBanner(respw http.ResponseWriter, req *http.Request) {
 	var banners []map[string]interface{}
 	for _, banner := range data {
 		banners = append(banners, map[string]interface{}{
-			"id":    banner.ID,
-			"name":  banner.Name,
-			"image": banner.Image,
+			"id":             banner.ID,
+			"name":           banner.Name,
+			"image":          banner.Image,
+			"image_variants": banner.ImageVariants,
 		})
 	}
 
@@ -106,9 +102,8 @@ func GetBannerByID(respw http.ResponseWriter, req *http.Request) {
 	}
 
 	// Ambil Data Banner dari Database
-	var banner model.Banner
-	filter := bson.M{"_id": objectID}
-	_, err = atdb.GetOneDoc[model.Banner](config.Mongoconn, "banner", filter)
+	filter := atdb.ExcludeDeleted(bson.M{"_id": objectID})
+	banner, err := atdb.GetOneDoc[model.Banner](config.Mongoconn, "banner", filter)
 	if err != nil {
 		var respn model.Response
 		respn.Status = "Error: Banner tidak ditemukan"
@@ -126,17 +121,8 @@ func GetBannerByID(respw http.ResponseWriter, req *http.Request) {
 }
 
 func UpdateBanner(respw http.ResponseWriter, req *http.Request) {
-	// Verifikasi Token Pengguna
-	payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: Token Tidak Valid"
-		respn.Info = at.GetSecretFromHeader(req)
-		respn.Location = "Decode Token Error"
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusForbidden, respn)
-		return
-	}
+	// User sudah divalidasi dan disimpan ke context oleh PermissionMiddleware
+	user, _ := rbac.CurrentUser(req)
 
 	// Ambil ID dari query string
 	bannerID := req.URL.Query().Get("id")
@@ -156,9 +142,9 @@ func UpdateBanner(respw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Ambil Data Banner dari Database
+	// Ambil Data Banner dari Database, disimpan untuk audit log
 	filter := bson.M{"_id": objectID}
-	_, err = atdb.GetOneDoc[model.Banner](config.Mongoconn, "banner", filter)
+	oldBanner, err := atdb.GetOneDoc[model.Banner](config.Mongoconn, "banner", atdb.ExcludeDeleted(bson.M{"_id": objectID}))
 	if err != nil {
 		var respn model.Response
 		respn.Status = "Error: Banner tidak ditemukan"
@@ -179,12 +165,10 @@ func UpdateBanner(respw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Update Data Banner
+	// Update Data Banner, UpdateOneDoc sudah membungkus dengan $set
 	updateData := bson.M{
-		"$set": bson.M{
-			"name":  requestBody.Name,
-			"image": requestBody.Image,
-		},
+		"name":  requestBody.Name,
+		"image": requestBody.Image,
 	}
 	_, err = atdb.UpdateOneDoc(config.Mongoconn, "banner", filter, updateData)
 	if err != nil {
@@ -194,71 +178,31 @@ func UpdateBanner(respw http.ResponseWriter, req *http.Request) {
 		at.WriteJSON(respw, http.StatusNotModified, respn)
 		return
 	}
+	if newBanner, err := atdb.GetOneDoc[model.Banner](config.Mongoconn, "banner", filter); err == nil {
+		audit.Record(config.Mongoconn, req, model.AuditUpdate, "banner", objectID, oldBanner, newBanner)
+	}
 
 	// Response dengan Data Banner yang Diperbarui
 	response := map[string]interface{}{
 		"status":  "success",
 		"message": "Banner berhasil diupdate",
 		"data":    requestBody,
-		"name":    payload.Alias,
+		"name":    user.Name,
 	}
 	at.WriteJSON(respw, http.StatusOK, response)
 }
 
-func DeleteBanner(respw http.ResponseWriter, req *http.Request) {
-	// Verifikasi Token Pengguna
-	payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: Token Tidak Valid"
-		respn.Info = at.GetSecretFromHeader(req)
-		respn.Location = "Decode Token Error"
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusForbidden, respn)
-		return
-	}
-
-	// Ambil ID dari query string
-	bannerID := req.URL.Query().Get("id")
-	if bannerID == "" {
-		var respn model.Response
-		respn.Status = "Error: ID Banner tidak ditemukan"
-		at.WriteJSON(respw, http.StatusBadRequest, respn)
-		return
-	}
-
-	// Convert ID ke ObjectID
-	objectID, err := primitive.ObjectIDFromHex(bannerID)
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: ID Banner tidak valid"
-		at.WriteJSON(respw, http.StatusBadRequest, respn)
-		return
-	}
-
-	// Hapus Data Banner dari Database
-	filter := bson.M{"_id": objectID}
-	deleteResult, err := atdb.DeleteOneDoc(config.Mongoconn, "banner", filter)
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: Gagal menghapus banner"
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusNotModified, respn)
-		return
-	}
+// UploadBannerImage - Upload gambar banner (form field "image"), dibuat variant thumb dan banner 3:1 dalam WebP
+func UploadBannerImage(respw http.ResponseWriter, req *http.Request) {
+	replaceImage(respw, req, "banner", "bannerImages", imageproc.BannerVariants)
+}
 
-	// Response Sukses
-	if deleteResult.DeletedCount == 0 {
-		var respn model.Response
-		respn.Status = "Error: Banner tidak ditemukan"
-		at.WriteJSON(respw, http.StatusNotFound, respn)
-		return
-	}
+// DeleteBanner - Soft delete banner, bisa dikembalikan dengan RestoreBanner
+func DeleteBanner(respw http.ResponseWriter, req *http.Request) {
+	softDeleteDoc[model.Banner](respw, req, "banner", "Banner")
+}
 
-	response := map[string]interface{}{
-		"status":  "success",
-		"message": "Banner berhasil dihapus",
-		"name":    payload.Alias,
-	}
-	at.WriteJSON(respw, http.StatusOK, response)
-}
\ No newline at end of file
+// RestoreBanner - Mengembalikan banner yang sudah dihapus
+func RestoreBanner(respw http.ResponseWriter, req *http.Request) {
+	restoreDoc[model.Banner](respw, req, "banner", "Banner")
+}
@@ -8,36 +8,19 @@ import (
 	"github.com/gocroot/config"
 	"github.com/gocroot/helper/at"
 	"github.com/gocroot/helper/atdb"
-	"github.com/gocroot/helper/watoken"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/imageproc"
+	"github.com/gocroot/helper/kitchen"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
 	"github.com/gocroot/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func CreateCategory(respw http.ResponseWriter, req *http.Request) {
-    // Decode token untuk validasi
-    // payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	// if err != nil {
-	// 	var respn model.Response
-	// 	respn.Status = "Error : Token Tidak Valid "
-	// 	respn.Info = config.PublicKeyWhatsAuth
-	// 	respn.Location = "Decode Token Error: " + at.GetLoginFromHeader(req)
-	// 	respn.Response = err.Error()
-	// 	at.WriteJSON(respw, http.StatusForbidden, respn)
-	// 	return
-	// }
-
-    // try change payload
-    payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		at.WriteJSON(respw, http.StatusForbidden, model.Response{
-			Status:   "Error: Token Tidak Valid",
-			Location: "Decode Token Error",
-			Response: err.Error(),
-		})
-		return
-	}
-
+    // User sudah divalidasi dan disimpan ke context oleh PermissionMiddleware
+    user, _ := rbac.CurrentUser(req)
 
     // Decode body untuk mendapatkan data kategori
     var category model.Category
@@ -49,10 +32,20 @@ func CreateCategory(respw http.ResponseWriter, req *http.Request) {
         return
     }
 
+    // Stasiun kosong berarti menu kategori ini dibuat di bar
+    if category.Station != "" && !kitchen.ValidStation(category.Station) {
+        var respn model.Response
+        respn.Status = "Error: Stasiun Tidak Dikenal"
+        respn.Response = "Stasiun harus salah satu dari " + strings.Join(kitchen.Stations, ", ")
+        at.WriteJSON(respw, http.StatusBadRequest, respn)
+        return
+    }
+
     // Siapkan kategori baru tanpa ID
     newCategory := model.Category{
-        Name:  category.Name,
-        Image: category.Image,
+        Name:    category.Name,
+        Image:   category.Image,
+        Station: category.Station,
     }
 
     // Masukkan kategori ke dalam database
@@ -67,63 +60,72 @@ func CreateCategory(respw http.ResponseWriter, req *http.Request) {
 
     // Gunakan hasil insertResult langsung (karena bertipe primitive.ObjectID)
     newCategory.ID = insertResult
+    audit.Record(config.Mongoconn, req, model.AuditCreate, "category", newCategory.ID, nil, newCategory)
 
     // Siapkan respons
     response := map[string]interface{}{
         "status":  "success",
         "message": "Kategori berhasil ditambahkan",
-        "name":    payload.Alias,
+        "name":    user.Name,
         "data": map[string]interface{}{
             "id":    newCategory.ID.Hex(), // Convert ObjectID to string
-            "name":  newCategory.Name,
-            "image": newCategory.Image,
+            "name":    newCategory.Name,
+            "image":   newCategory.Image,
+            "station": newCategory.Station,
         },
     }
     at.WriteJSON(respw, http.StatusOK, response)
 }
 
+// GetAllCategory - Ambil Data Kategori per halaman. Query: ?page=&limit=&cursor=&sort=name&order=asc|desc&q=
 func GetAllCategory(respw http.ResponseWriter, req *http.Request) {
-	// Ambil semua data kategori dari koleksi
-	data, err := atdb.GetAllDoc[[]model.Category](config.Mongoconn, "category", bson.M{})
+	params := req.URL.Query()
+	q, err := atdb.ParsePageQuery(params, "name", false, "name")
 	if err != nil {
 		var respn model.Response
-		respn.Status = "Error: Data kategori tidak ditemukan"
+		respn.Status = "Error: Parameter Tidak Valid"
 		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusNotFound, respn)
+		at.WriteJSON(respw, http.StatusBadRequest, respn)
 		return
 	}
+	filter := bson.M{}
+	atdb.MatchText(filter, params.Get("q"), "name")
 
-	// Cek apakah data kosong
-	if len(data) == 0 {
+	// Ambil data kategori dari koleksi
+	data, page, err := atdb.GetPagedDocs[model.Category](config.Mongoconn, "category", atdb.ExcludeDeleted(filter), q)
+	if err != nil {
 		var respn model.Response
-		respn.Status = "Error: Data kategori kosong"
-		at.WriteJSON(respw, http.StatusNotFound, respn)
+		respn.Status = "Error: Data kategori tidak ditemukan"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusInternalServerError, respn)
 		return
 	}
 
 	// Format hasil sebagai slice of map dengan ID dalam bentuk string
-	var categories []map[string]interface{}
+	categories := make([]map[string]interface{}, 0, len(data))
 	for _, category := range data {
 		categories = append(categories, map[string]interface{}{
-			"id":    category.ID.Hex(), // Konversi ObjectID ke string
-			"name":  category.Name,
-			"image": category.Image,
+			"id":             category.ID.Hex(), // Konversi ObjectID ke string
+			"name":           category.Name,
+			"image":          category.Image,
+			"image_variants": category.ImageVariants,
+			"station":        category.Station,
 		})
 	}
 
 	// Kirim data kategori dalam format JSON
 	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
-		"status":  "success",
-		"message": "Data kategori berhasil diambil",
-		"data":    categories,
+		"status":     "success",
+		"message":    "Data kategori berhasil diambil",
+		"data":       categories,
+		"pagination": page,
 	})
 }
 
 
 func GetCategoryByID(respw http.ResponseWriter, req *http.Request) {
-    // Ambil ID kategori dari URL menggunakan Split
-    pathParts := strings.Split(req.URL.Path, "/")
-    categoryID := pathParts[len(pathParts)-1] // Ambil bagian terakhir dari URL
+    // Ambil ID kategori dari URL
+    categoryID := router.Param(req, "id")
     if categoryID == "" {
         var respn model.Response
         respn.Status = "Error: ID Category tidak ditemukan di URL"
@@ -141,7 +143,7 @@ func GetCategoryByID(respw http.ResponseWriter, req *http.Request) {
     }
 
     // Query ke database untuk mengambil kategori berdasarkan ObjectID
-    filter := bson.M{"_id": objectID}
+    filter := atdb.ExcludeDeleted(bson.M{"_id": objectID})
     category, err := atdb.GetOneDoc[model.Category](config.Mongoconn, "category", filter)
     if err != nil {
         var respn model.Response
@@ -161,21 +163,11 @@ func GetCategoryByID(respw http.ResponseWriter, req *http.Request) {
 }
 
 func UpdateCategory(respw http.ResponseWriter, req *http.Request) {
-    // Ambil token dari header untuk validasi
-    payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error : Token Tidak Valid "
-		respn.Info = config.PublicKeyWhatsAuth
-		respn.Location = "Decode Token Error: " + at.GetLoginFromHeader(req)
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusForbidden, respn)
-		return
-	}
+    // User sudah divalidasi dan disimpan ke context oleh PermissionMiddleware
+    user, _ := rbac.CurrentUser(req)
 
-    // Ambil ID kategori dari URL menggunakan Split
-    pathParts := strings.Split(req.URL.Path, "/")
-    categoryID := pathParts[len(pathParts)-1] // Ambil bagian terakhir dari URL
+    // Ambil ID kategori dari URL
+    categoryID := router.Param(req, "id")
     if categoryID == "" {
         var respn model.Response
         respn.Status = "Error: ID Category tidak ditemukan di URL"
@@ -192,6 +184,16 @@ func UpdateCategory(respw http.ResponseWriter, req *http.Request) {
         return
     }
 
+    // Data kategori sebelum diubah, untuk audit log
+    oldCategory, err := atdb.GetOneDoc[model.Category](config.Mongoconn, "category", atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+    if err != nil {
+        var respn model.Response
+        respn.Status = "Error: Category tidak ditemukan"
+        respn.Response = err.Error()
+        at.WriteJSON(respw, http.StatusNotFound, respn)
+        return
+    }
+
     // Decode body langsung ke map
     var requestBody map[string]interface{}
     err = json.NewDecoder(req.Body).Decode(&requestBody)
@@ -219,6 +221,17 @@ func UpdateCategory(respw http.ResponseWriter, req *http.Request) {
     if image, exists := requestBody["image"]; exists && image != "" {
         updateData["image"] = image
     }
+    if station, exists := requestBody["station"]; exists {
+        name, _ := station.(string)
+        if name != "" && !kitchen.ValidStation(name) {
+            var respn model.Response
+            respn.Status = "Error: Stasiun Tidak Dikenal"
+            respn.Response = "Stasiun harus salah satu dari " + strings.Join(kitchen.Stations, ", ")
+            at.WriteJSON(respw, http.StatusBadRequest, respn)
+            return
+        }
+        updateData["station"] = name
+    }
 
     // Jika tidak ada perubahan data, beri respon error
     if len(updateData) == 0 {
@@ -245,6 +258,9 @@ func UpdateCategory(respw http.ResponseWriter, req *http.Request) {
         at.WriteJSON(respw, http.StatusNotModified, respn)
         return
     }
+    if newCategory, err := atdb.GetOneDoc[model.Category](config.Mongoconn, "category", bson.M{"_id": objectID}); err == nil {
+        audit.Record(config.Mongoconn, req, model.AuditUpdate, "category", objectID, oldCategory, newCategory)
+    }
 
     // Respons sukses
     response := map[string]interface{}{
@@ -254,67 +270,22 @@ func UpdateCategory(respw http.ResponseWriter, req *http.Request) {
             "id":    objectID.Hex(),
             "updatedFields": updateData,
         },
-        "updatedBy": payload.Alias,
+        "updatedBy": user.Name,
     }
     at.WriteJSON(respw, http.StatusOK, response)
 }
 
-func DeleteCategory(respw http.ResponseWriter, req *http.Request) {
-	// Ambil token dari header
-	payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error : Token Tidak Valid "
-		respn.Info = config.PublicKeyWhatsAuth
-		respn.Location = "Decode Token Error: " + at.GetLoginFromHeader(req)
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusForbidden, respn)
-		return
-	}
-
-	// Ambil ID kategori dari URL
-	pathParts := strings.Split(req.URL.Path, "/")
-	categoryID := pathParts[len(pathParts)-1] // Ambil bagian terakhir dari URL
-	if categoryID == "" {
-		var respn model.Response
-		respn.Status = "Error: ID Category tidak ditemukan di URL"
-		at.WriteJSON(respw, http.StatusBadRequest, respn)
-		return
-	}
-
-	// Konversi ID kategori ke ObjectID MongoDB
-	objectID, err := primitive.ObjectIDFromHex(categoryID)
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: ID Category tidak valid"
-		at.WriteJSON(respw, http.StatusBadRequest, respn)
-		return
-	}
-
-	// Hapus data Category berdasarkan ID
-	filter := bson.M{"_id": objectID}
-	deleteResult, err := atdb.DeleteOneDoc(config.Mongoconn, "category", filter)
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: Gagal menghapus Category"
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusInternalServerError, respn)
-		return
-	}
+// UploadCategoryImage - Upload gambar kategori (form field "image"), dibuat variant thumb dan card dalam WebP
+func UploadCategoryImage(respw http.ResponseWriter, req *http.Request) {
+	replaceImage(respw, req, "category", "categoryImages", imageproc.CategoryVariants)
+}
 
-	if deleteResult.DeletedCount == 0 {
-		var respn model.Response
-		respn.Status = "Error: Category tidak ditemukan"
-		at.WriteJSON(respw, http.StatusNotFound, respn)
-		return
-	}
+// DeleteCategory - Soft delete kategori, bisa dikembalikan dengan RestoreCategory
+func DeleteCategory(respw http.ResponseWriter, req *http.Request) {
+	softDeleteDoc[model.Category](respw, req, "category", "Category")
+}
 
-	// Berhasil menghapus kategori
-	response := map[string]interface{}{
-		"status":  "success",
-		"message": "Category berhasil dihapus",
-		"user":    payload.Alias,
-		"data":    deleteResult,
-	}
-	at.WriteJSON(respw, http.StatusOK, response)
-}
\ No newline at end of file
+// RestoreCategory - Mengembalikan kategori yang sudah dihapus
+func RestoreCategory(respw http.ResponseWriter, req *http.Request) {
+	restoreDoc[model.Category](respw, req, "category", "Category")
+}
@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/checkout"
+	"github.com/gocroot/helper/delivery"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/rupiah"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QuoteDelivery - Cek alamat antar sebelum checkout: POST /data/delivery/quote dengan body {"long": .., "lat": ..}.
+// Mengembalikan zona, jarak, ongkir dan wilayah, atau ditolak jika di luar area layanan.
+func QuoteDelivery(respw http.ResponseWriter, req *http.Request) {
+	var input model.OrderDelivery
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	quote, ok := quoteDelivery(respw, input)
+	if !ok {
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Alamat masuk area antar " + quote.ZoneName,
+		"fee":     rupiah.Format(quote.Fee),
+		"data":    quote,
+	})
+}
+
+// quoteDelivery - Menghitung zona dan ongkir, respons error sudah dikirim jika ok false
+func quoteDelivery(respw http.ResponseWriter, input model.OrderDelivery) (quote model.OrderDelivery, ok bool) {
+	quote, err := delivery.Quote(config.Mongoconn, config.MongoconnGeo, input)
+	if err = checkout.DeliveryError(err); err != nil {
+		writeCheckoutError(respw, err)
+		return
+	}
+	return quote, true
+}
+
+// GetDeliverySettings - Titik toko dan tarif ongkir per jarak (admin)
+func GetDeliverySettings(respw http.ResponseWriter, req *http.Request) {
+	settings, err := delivery.GetSettings(config.Mongoconn)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil pengaturan antar",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Pengaturan antar berhasil diambil",
+		"data":    settings,
+	})
+}
+
+// UpdateDeliverySettings - Simpan titik toko dan tarif ongkir (admin). Band dikosongkan untuk mematikan layanan antar,
+// pesanan yang sudah dibuat tetap memakai ongkir saat pesanan dibuat.
+func UpdateDeliverySettings(respw http.ResponseWriter, req *http.Request) {
+	user, _ := rbac.CurrentUser(req)
+
+	var input model.DeliverySettings
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	if input.Bands == nil {
+		input.Bands = []model.DeliveryBand{}
+	}
+	if err := delivery.ValidateSettings(input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Pengaturan Antar Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+	before, err := delivery.GetSettings(config.Mongoconn)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil pengaturan antar",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.UpdatedBy = user.Name
+	input.UpdatedAt = time.Now()
+	settings, err := delivery.SaveSettings(config.Mongoconn, input)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menyimpan pengaturan antar",
+			Response: err.Error(),
+		})
+		return
+	}
+	audit.RecordKey(config.Mongoconn, req, model.AuditUpdate, delivery.SettingsCollection, settings.ID, before, settings)
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Pengaturan antar berhasil disimpan",
+		"data":    settings,
+	})
+}
+
+// GetAllDeliveryZone - Ambil semua zona antar yang belum dihapus (admin)
+func GetAllDeliveryZone(respw http.ResponseWriter, req *http.Request) {
+	data, err := atdb.GetManyDocs[model.DeliveryZone](config.Mongoconn, delivery.Collection, atdb.ExcludeDeleted(bson.M{}))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil data zona antar",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Data zona antar berhasil diambil",
+		"data":    data,
+	})
+}
+
+// GetDeliveryZoneByID - Ambil satu zona antar: /data/delivery/zone/:id
+func GetDeliveryZoneByID(respw http.ResponseWriter, req *http.Request) {
+	zone, ok := deliveryZoneFromParam(respw, req)
+	if !ok {
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Zona antar ditemukan",
+		"data":    zone,
+	})
+}
+
+// CreateDeliveryZone - Tambah zona antar (admin), area berupa GeoJSON Polygon dengan koordinat [long, lat].
+// Zona baru langsung aktif jika active tidak diisi false.
+func CreateDeliveryZone(respw http.ResponseWriter, req *http.Request) {
+	input := model.DeliveryZone{Active: true}
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := delivery.ValidateZone(input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Data Zona Antar Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	input.ID = primitive.NilObjectID
+	input.CreatedAt = time.Now()
+	input.UpdatedAt = time.Time{}
+	input.DeletedAt, input.DeletedBy = time.Time{}, ""
+	insertedID, err := atdb.InsertOneDoc(config.Mongoconn, delivery.Collection, input)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menyimpan zona antar",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.ID = insertedID
+	audit.Record(config.Mongoconn, req, model.AuditCreate, delivery.Collection, insertedID, nil, input)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Zona antar berhasil ditambahkan",
+		"data":    input,
+	})
+}
+
+// UpdateDeliveryZone - Ubah nama, area dan status aktif zona antar (admin): /data/delivery/zone/:id
+func UpdateDeliveryZone(respw http.ResponseWriter, req *http.Request) {
+	before, ok := deliveryZoneFromParam(respw, req)
+	if !ok {
+		return
+	}
+	var input model.DeliveryZone
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := delivery.ValidateZone(input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Data Zona Antar Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	update := bson.M{"$set": bson.M{
+		"name":       input.Name,
+		"area":       input.Area,
+		"active":     input.Active,
+		"updated_at": time.Now(),
+	}}
+	after, err := atdb.FindOneAndUpdateDoc[model.DeliveryZone](config.Mongoconn, delivery.Collection, atdb.ExcludeDeleted(bson.M{"_id": before.ID}), update)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Zona antar tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	audit.Record(config.Mongoconn, req, model.AuditUpdate, delivery.Collection, after.ID, before, after)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Zona antar berhasil diupdate",
+		"data":    after,
+	})
+}
+
+// DeleteDeliveryZone - Soft delete zona antar (admin), alamat di zona ini langsung ditolak
+func DeleteDeliveryZone(respw http.ResponseWriter, req *http.Request) {
+	softDeleteDoc[model.DeliveryZone](respw, req, delivery.Collection, "Zona antar")
+}
+
+// RestoreDeliveryZone - Mengembalikan zona antar yang dihapus
+func RestoreDeliveryZone(respw http.ResponseWriter, req *http.Request) {
+	restoreDoc[model.DeliveryZone](respw, req, delivery.Collection, "Zona antar")
+}
+
+// deliveryZoneFromParam - Mengambil zona antar :id yang belum dihapus, respons error sudah dikirim jika ok false
+func deliveryZoneFromParam(respw http.ResponseWriter, req *http.Request) (zone model.DeliveryZone, ok bool) {
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Zona antar tidak valid",
+		})
+		return
+	}
+	zone, err = atdb.GetOneDoc[model.DeliveryZone](config.Mongoconn, delivery.Collection, atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Zona antar tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	return zone, true
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"io"
 	"net/http"
+	"path"
 	"strings"
 
 	"github.com/gocroot/config"
@@ -11,6 +12,7 @@ import (
 	"github.com/gocroot/helper/atdb"
 	"github.com/gocroot/helper/dokped"
 	"github.com/gocroot/helper/ghupload"
+	"github.com/gocroot/helper/storage"
 	"github.com/gocroot/helper/watoken"
 	"github.com/gocroot/model"
 	"go.mongodb.org/mongo-driver/bson"
@@ -65,12 +67,10 @@ func AksesFileRepoDraft(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	githubOrg := "penerbitbukupedia"
-	githubRepo := "draft"
-	filecontent, err := ghupload.GithubGetFile(config.GHAccessToken, githubOrg, githubRepo, pathFile)
+	filecontent, source, err := getDocument(pathFile)
 	if err != nil {
-		respn.Status = "Error : Data tidak bisa diambil dari github"
-		respn.Info = githubOrg + "/" + githubRepo
+		respn.Status = "Error : Data tidak bisa diambil dari penyimpanan dokumen"
+		respn.Info = source
 		respn.Location = pathFile
 		respn.Response = err.Error()
 		at.WriteJSON(w, http.StatusBadRequest, respn)
@@ -286,51 +286,24 @@ func UploadProfilePictureHandler(w http.ResponseWriter, r *http.Request) {
 		at.WriteJSON(w, http.StatusNotImplemented, respn)
 		return
 	}
-	file, header, err := r.FormFile("profpic")
-	if err != nil {
-		respn.Status = "Error : File tidak ada"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusBadRequest, respn)
-		return
-	}
-	defer file.Close()
-	// Read the file content
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa dibaca"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	// Calculate hash of the file content
-	//hashedFileName := ghupload.CalculateHash(fileContent)
-
-	// Get GitHub credentials and other details from the request or environment variables
-	GitHubAccessToken := config.GHAccessToken
-	GitHubAuthorName := "Rolly Maulana Awangga"
-	GitHubAuthorEmail := "awangga@gmail.com"
-	githubOrg := "penerbitbukupedia"
-	githubRepo := "profile"
-	pathFile := "picture/" + userdoc.ID.Hex() + "/" + userdoc.ID.Hex() + header.Filename[strings.LastIndex(header.Filename, "."):] // Append the original file extension
-	replace := true
-
-	// Use GithubUpload function to upload the file to GitHub
-	content, _, err := ghupload.GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail, fileContent, githubOrg, githubRepo, pathFile, replace)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa diupload ke github"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
+	store, obj, ok := uploadFormFile(w, r, ProfileStorage, "profpic", "picture/"+userdoc.ID.Hex()+"/"+userdoc.ID.Hex())
+	if !ok {
 		return
 	}
-	//update data profpic
-	userdoc.ProfilePicture = "https://raw.githubusercontent.com/" + githubOrg + "/" + githubRepo + "/main/" + *content.Content.Path
+	//update data profpic, file lama dengan ekstensi berbeda dihapus
+	oldKey := userdoc.ProfilePictureKey
+	userdoc.ProfilePicture = obj.URL
+	userdoc.ProfilePictureKey = obj.Key
 	atdb.ReplaceOneDoc(config.Mongoconn, "user", bson.M{"_id": userdoc.ID}, userdoc)
+	if oldKey != "" && oldKey != obj.Key {
+		deleteStoredFiles(store, oldKey)
+	}
 
 	// Respond with success message
 	respn.Info = userdoc.ID.Hex()
 	respn.Location = userdoc.ProfilePicture
-	respn.Response = *content.Content.URL
-	respn.Status = *content.Content.HTMLURL
+	respn.Response = obj.Key
+	respn.Status = "Berhasil diupload ke " + store.Name()
 	at.WriteJSON(w, http.StatusOK, respn)
 }
 
@@ -362,48 +335,22 @@ func FileUploadWithParamFileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, header, err := r.FormFile("profpic")
-	if err != nil {
-		respn.Status = "Error : File tidak ada"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusBadRequest, respn)
-		return
-	}
-	defer file.Close()
-	// Read the file content
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa dibaca"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
+	fileContent, ext, ok := readFormFile(w, r, "profpic")
+	if !ok {
 		return
 	}
-	// Calculate hash of the file content
+	// nama file dari hash isi file, upload ulang file yang sama tidak membuat file baru
 	hashedFileName := ghupload.CalculateHash(fileContent)
-
-	// Get GitHub credentials and other details from the request or environment variables
-	GitHubAccessToken := config.GHAccessToken
-	GitHubAuthorName := "Rolly Maulana Awangga"
-	GitHubAuthorEmail := "awangga@gmail.com"
-	githubOrg := "penerbitbukupedia"
-	githubRepo := "img"
-	pathFile := prj.Name + "/menu/" + hashedFileName + header.Filename[strings.LastIndex(header.Filename, "."):] // Append the original file extension
-	replace := true
-
-	// Use GithubUpload function to upload the file to GitHub
-	content, _, err := ghupload.GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail, fileContent, githubOrg, githubRepo, pathFile, replace)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa diupload ke github"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
+	store, obj, ok := putUploadedFile(w, ActiveStorage, prj.Name+"/menu/"+hashedFileName+ext, fileContent)
+	if !ok {
 		return
 	}
 
 	// Respond with success message
 	respn.Info = hashedFileName
-	respn.Location = "/" + githubRepo + "/" + *content.Content.Path
-	respn.Response = *content.Content.URL
-	respn.Status = *content.Content.HTMLURL
+	respn.Location = obj.URL
+	respn.Response = obj.Key
+	respn.Status = "Berhasil diupload ke " + store.Name()
 	at.WriteJSON(w, http.StatusOK, respn)
 }
 
@@ -442,51 +389,24 @@ func UploadCoverBukuWithParamFileHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	file, header, err := r.FormFile("coverbuku")
-	if err != nil {
-		respn.Status = "Error : File tidak ada"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusBadRequest, respn)
-		return
-	}
-	defer file.Close()
-	// Read the file content
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa dibaca"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	// Calculate hash of the file content
-	//hashedFileName := ghupload.CalculateHash(fileContent)
-
-	// Get GitHub credentials and other details from the request or environment variables
-	GitHubAccessToken := config.GHAccessToken
-	GitHubAuthorName := "Rolly Maulana Awangga"
-	GitHubAuthorEmail := "awangga@gmail.com"
-	githubOrg := "penerbitbukupedia"
-	githubRepo := "katalog"
-	pathFile := prj.Name + "/cover/" + prj.ID.Hex() + header.Filename[strings.LastIndex(header.Filename, "."):] // Append the original file extension
-	replace := true
-
-	// Use GithubUpload function to upload the file to GitHub
-	content, _, err := ghupload.GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail, fileContent, githubOrg, githubRepo, pathFile, replace)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa diupload ke github"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
+	store, obj, ok := uploadFormFile(w, r, KatalogStorage, "coverbuku", prj.Name+"/cover/"+prj.ID.Hex())
+	if !ok {
 		return
 	}
-	//update data profpic
-	prj.CoverBuku = "https://raw.githubusercontent.com/" + githubOrg + "/" + githubRepo + "/main/" + *content.Content.Path
+	//update data cover, file lama dengan ekstensi berbeda dihapus
+	oldKey := prj.CoverBukuKey
+	prj.CoverBuku = obj.URL
+	prj.CoverBukuKey = obj.Key
 	atdb.ReplaceOneDoc(config.Mongoconn, "project", bson.M{"_id": prj.ID}, prj)
+	if oldKey != "" && oldKey != obj.Key {
+		deleteStoredFiles(store, oldKey)
+	}
 
 	// Respond with success message
 	respn.Info = prj.ID.Hex()
 	respn.Location = prj.CoverBuku
-	respn.Response = *content.Content.URL
-	respn.Status = *content.Content.HTMLURL
+	respn.Response = obj.Key
+	respn.Status = "Berhasil diupload ke " + store.Name()
 	at.WriteJSON(w, http.StatusOK, respn)
 }
 
@@ -525,52 +445,18 @@ func UploadDraftBukuWithParamFileHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	file, header, err := r.FormFile("draftbuku")
-	if err != nil {
-		respn.Status = "Error : File tidak ada"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusBadRequest, respn)
+	store, obj, ok := uploadFormFile(w, r, DocumentStorage, "draftbuku", prj.Name+"/draft/"+prj.ID.Hex())
+	if !ok {
 		return
 	}
-	defer file.Close()
-	// Read the file content
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa dibaca"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	// Calculate hash of the file content
-	//hashedFileName := ghupload.CalculateHash(fileContent)
-
-	// Get GitHub credentials and other details from the request or environment variables
-	GitHubAccessToken := config.GHAccessToken
-	GitHubAuthorName := "Rolly Maulana Awangga"
-	GitHubAuthorEmail := "awangga@gmail.com"
-	githubOrg := "penerbitbukupedia"
-	githubRepo := "draft"
-	pathFile := prj.Name + "/draft/" + prj.ID.Hex() + header.Filename[strings.LastIndex(header.Filename, "."):] // Append the original file extension
-	replace := true
-
-	// Use GithubUpload function to upload the file to GitHub
-	content, _, err := ghupload.GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail, fileContent, githubOrg, githubRepo, pathFile, replace)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa diupload ke github"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	//update data profpic
-	//prj.DraftBuku = "https://raw.githubusercontent.com/" + githubOrg + "/" + githubRepo + "/main/" + *content.Content.Path
-	prj.DraftBuku = *content.Content.Path //karena repo private
+	prj.DraftBuku = obj.Key //dokumen privat, dibuka lewat /download/draft
 	atdb.ReplaceOneDoc(config.Mongoconn, "project", bson.M{"_id": prj.ID}, prj)
 
 	// Respond with success message
 	respn.Info = prj.ID.Hex()
 	respn.Location = prj.DraftBuku
-	respn.Response = *content.Content.URL
-	respn.Status = *content.Content.HTMLURL
+	respn.Response = obj.Key
+	respn.Status = "Berhasil diupload ke " + store.Name()
 	at.WriteJSON(w, http.StatusOK, respn)
 }
 
@@ -609,51 +495,18 @@ func UploadDraftBukuPDFWithParamFileHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	file, header, err := r.FormFile("draftpdfbuku")
-	if err != nil {
-		respn.Status = "Error : File tidak ada"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusBadRequest, respn)
+	store, obj, ok := uploadFormFile(w, r, DocumentStorage, "draftpdfbuku", prj.Name+"/pdf/"+prj.ID.Hex())
+	if !ok {
 		return
 	}
-	defer file.Close()
-	// Read the file content
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa dibaca"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	// Calculate hash of the file content
-	//hashedFileName := ghupload.CalculateHash(fileContent)
-
-	// Get GitHub credentials and other details from the request or environment variables
-	GitHubAccessToken := config.GHAccessToken
-	GitHubAuthorName := "Rolly Maulana Awangga"
-	GitHubAuthorEmail := "awangga@gmail.com"
-	githubOrg := "penerbitbukupedia"
-	githubRepo := "draft"
-	pathFile := prj.Name + "/pdf/" + prj.ID.Hex() + header.Filename[strings.LastIndex(header.Filename, "."):] // Append the original file extension
-	replace := true
-
-	// Use GithubUpload function to upload the file to GitHub
-	content, _, err := ghupload.GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail, fileContent, githubOrg, githubRepo, pathFile, replace)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa diupload ke github"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	//update data profpic
-	prj.DraftPDFBuku = *content.Content.Path
+	prj.DraftPDFBuku = obj.Key //dokumen privat, dibuka lewat /download/draft
 	atdb.ReplaceOneDoc(config.Mongoconn, "project", bson.M{"_id": prj.ID}, prj)
 
 	// Respond with success message
 	respn.Info = prj.ID.Hex()
 	respn.Location = prj.DraftPDFBuku
-	respn.Response = *content.Content.URL
-	respn.Status = *content.Content.HTMLURL
+	respn.Response = obj.Key
+	respn.Status = "Berhasil diupload ke " + store.Name()
 	at.WriteJSON(w, http.StatusOK, respn)
 }
 
@@ -692,51 +545,18 @@ func UploadSPKPDFWithParamFileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, header, err := r.FormFile("spk")
-	if err != nil {
-		respn.Status = "Error : File tidak ada"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusBadRequest, respn)
+	store, obj, ok := uploadFormFile(w, r, DocumentStorage, "spk", prj.Name+"/spk/"+prj.ID.Hex())
+	if !ok {
 		return
 	}
-	defer file.Close()
-	// Read the file content
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa dibaca"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	// Calculate hash of the file content
-	//hashedFileName := ghupload.CalculateHash(fileContent)
-
-	// Get GitHub credentials and other details from the request or environment variables
-	GitHubAccessToken := config.GHAccessToken
-	GitHubAuthorName := "Rolly Maulana Awangga"
-	GitHubAuthorEmail := "awangga@gmail.com"
-	githubOrg := "penerbitbukupedia"
-	githubRepo := "draft"
-	pathFile := prj.Name + "/spk/" + prj.ID.Hex() + header.Filename[strings.LastIndex(header.Filename, "."):] // Append the original file extension
-	replace := true
-
-	// Use GithubUpload function to upload the file to GitHub
-	content, _, err := ghupload.GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail, fileContent, githubOrg, githubRepo, pathFile, replace)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa diupload ke github"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	//update data profpic
-	prj.SPK = *content.Content.Path
+	prj.SPK = obj.Key //dokumen privat, dibuka lewat /download/draft
 	atdb.ReplaceOneDoc(config.Mongoconn, "project", bson.M{"_id": prj.ID}, prj)
 
 	// Respond with success message
 	respn.Info = prj.ID.Hex()
 	respn.Location = prj.SPK
-	respn.Response = *content.Content.URL
-	respn.Status = *content.Content.HTMLURL
+	respn.Response = obj.Key
+	respn.Status = "Berhasil diupload ke " + store.Name()
 	at.WriteJSON(w, http.StatusOK, respn)
 }
 
@@ -775,51 +595,18 @@ func UploadSPIPDFWithParamFileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, header, err := r.FormFile("spi")
-	if err != nil {
-		respn.Status = "Error : File tidak ada"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusBadRequest, respn)
+	store, obj, ok := uploadFormFile(w, r, DocumentStorage, "spi", prj.Name+"/spi/"+prj.ID.Hex())
+	if !ok {
 		return
 	}
-	defer file.Close()
-	// Read the file content
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa dibaca"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	// Calculate hash of the file content
-	//hashedFileName := ghupload.CalculateHash(fileContent)
-
-	// Get GitHub credentials and other details from the request or environment variables
-	GitHubAccessToken := config.GHAccessToken
-	GitHubAuthorName := "Rolly Maulana Awangga"
-	GitHubAuthorEmail := "awangga@gmail.com"
-	githubOrg := "penerbitbukupedia"
-	githubRepo := "draft"
-	pathFile := prj.Name + "/spi/" + prj.ID.Hex() + header.Filename[strings.LastIndex(header.Filename, "."):] // Append the original file extension
-	replace := true
-
-	// Use GithubUpload function to upload the file to GitHub
-	content, _, err := ghupload.GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail, fileContent, githubOrg, githubRepo, pathFile, replace)
-	if err != nil {
-		respn.Status = "Error : File tidak bisa diupload ke github"
-		respn.Response = err.Error()
-		at.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
-	}
-	//update data profpic
-	prj.SPI = *content.Content.Path
+	prj.SPI = obj.Key //dokumen privat, dibuka lewat /download/draft
 	atdb.ReplaceOneDoc(config.Mongoconn, "project", bson.M{"_id": prj.ID}, prj)
 
 	// Respond with success message
 	respn.Info = prj.ID.Hex()
 	respn.Location = prj.SPI
-	respn.Response = *content.Content.URL
-	respn.Status = *content.Content.HTMLURL
+	respn.Response = obj.Key
+	respn.Status = "Berhasil diupload ke " + store.Name()
 	at.WriteJSON(w, http.StatusOK, respn)
 }
 
@@ -858,7 +645,35 @@ func UploadSampulBukuPDFWithParamFileHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	file, header, err := r.FormFile("sampulpdfbuku")
+	store, obj, ok := uploadFormFile(w, r, DocumentStorage, "sampulpdfbuku", prj.Name+"/sampul/"+prj.ID.Hex())
+	if !ok {
+		return
+	}
+	prj.SampulPDFBuku = obj.Key //dokumen privat, dibuka lewat /download/draft
+	atdb.ReplaceOneDoc(config.Mongoconn, "project", bson.M{"_id": prj.ID}, prj)
+
+	// Respond with success message
+	respn.Info = prj.ID.Hex()
+	respn.Location = prj.SampulPDFBuku
+	respn.Response = obj.Key
+	respn.Status = "Berhasil diupload ke " + store.Name()
+	at.WriteJSON(w, http.StatusOK, respn)
+}
+
+// uploadFormFile - Membaca file dari form field lalu menyimpannya ke backend dari open dengan key ditambah ekstensi file asli.
+// Respons error sudah dikirim jika ok false.
+func uploadFormFile(w http.ResponseWriter, r *http.Request, open func() (storage.Storage, error), field, key string) (store storage.Storage, obj storage.Object, ok bool) {
+	fileContent, ext, ok := readFormFile(w, r, field)
+	if !ok {
+		return
+	}
+	return putUploadedFile(w, open, key+ext, fileContent)
+}
+
+// readFormFile - Membaca isi file dari form field beserta ekstensinya, respons error sudah dikirim jika ok false
+func readFormFile(w http.ResponseWriter, r *http.Request, field string) (fileContent []byte, ext string, ok bool) {
+	var respn model.Response
+	file, header, err := r.FormFile(field)
 	if err != nil {
 		respn.Status = "Error : File tidak ada"
 		respn.Response = err.Error()
@@ -866,42 +681,33 @@ func UploadSampulBukuPDFWithParamFileHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	defer file.Close()
-	// Read the file content
-	fileContent, err := io.ReadAll(file)
+	fileContent, err = io.ReadAll(file)
 	if err != nil {
 		respn.Status = "Error : File tidak bisa dibaca"
 		respn.Response = err.Error()
 		at.WriteJSON(w, http.StatusInternalServerError, respn)
 		return
 	}
-	// Calculate hash of the file content
-	//hashedFileName := ghupload.CalculateHash(fileContent)
-
-	// Get GitHub credentials and other details from the request or environment variables
-	GitHubAccessToken := config.GHAccessToken
-	GitHubAuthorName := "Rolly Maulana Awangga"
-	GitHubAuthorEmail := "awangga@gmail.com"
-	githubOrg := "penerbitbukupedia"
-	githubRepo := "draft"
-	pathFile := prj.Name + "/sampul/" + prj.ID.Hex() + header.Filename[strings.LastIndex(header.Filename, "."):] // Append the original file extension
-	replace := true
+	return fileContent, strings.ToLower(path.Ext(header.Filename)), true
+}
 
-	// Use GithubUpload function to upload the file to GitHub
-	content, _, err := ghupload.GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail, fileContent, githubOrg, githubRepo, pathFile, replace)
+// putUploadedFile - Menyimpan file ke backend dari open (ActiveStorage, ProfileStorage, KatalogStorage atau DocumentStorage),
+// respons error sudah dikirim jika ok false
+func putUploadedFile(w http.ResponseWriter, open func() (storage.Storage, error), key string, fileContent []byte) (store storage.Storage, obj storage.Object, ok bool) {
+	var respn model.Response
+	store, err := open()
 	if err != nil {
-		respn.Status = "Error : File tidak bisa diupload ke github"
+		respn.Status = "Error : Penyimpanan file belum diatur"
 		respn.Response = err.Error()
 		at.WriteJSON(w, http.StatusInternalServerError, respn)
 		return
 	}
-	//update data profpic
-	prj.SampulPDFBuku = *content.Content.Path
-	atdb.ReplaceOneDoc(config.Mongoconn, "project", bson.M{"_id": prj.ID}, prj)
-
-	// Respond with success message
-	respn.Info = prj.ID.Hex()
-	respn.Location = prj.SampulPDFBuku
-	respn.Response = *content.Content.URL
-	respn.Status = *content.Content.HTMLURL
-	at.WriteJSON(w, http.StatusOK, respn)
+	obj, err = store.Put(key, fileContent, http.DetectContentType(fileContent))
+	if err != nil {
+		respn.Status = "Error : File tidak bisa diupload ke " + store.Name()
+		respn.Response = err.Error()
+		at.WriteJSON(w, http.StatusInternalServerError, respn)
+		return
+	}
+	return store, obj, true
 }
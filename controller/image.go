@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/ghupload"
+	"github.com/gocroot/helper/imageproc"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/storage"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// storedImage - Gambar yang sudah diproses dan disimpan: gambar utama dan variant-nya
+type storedImage struct {
+	URL      string
+	Key      string
+	Variants map[string]model.ImageVariant
+}
+
+// Keys - Semua key file gambar, untuk dihapus saat gambar diganti
+func (img storedImage) Keys() []string {
+	return imageKeys(img.Key, img.Variants)
+}
+
+// storeImage - Menyimpan gambar utama (tanpa EXIF, maksimal imageproc.MaxSide) dan variant 1x dan 2x ke backend aktif.
+// Variant disimpan sebagai JPEG (PNG jika transparan) dan WebP jika lebih kecil. Nama file dari hash isi file asli:
+// folder/<hash>.jpg, folder/<hash>_card.jpg, folder/<hash>_card@2x.jpg, folder/<hash>_card.webp
+func storeImage(folder string, content []byte, img imageproc.Image, variants []imageproc.Variant) (store storage.Storage, stored storedImage, err error) {
+	store, err = ActiveStorage()
+	if err != nil {
+		return
+	}
+	base := folder + "/" + ghupload.CalculateHash(content)
+	var saved []string
+	put := func(key string, data []byte, contentType string) (storage.Object, error) {
+		obj, err := store.Put(key, data, contentType)
+		if err == nil {
+			saved = append(saved, obj.Key)
+		}
+		return obj, err
+	}
+	// file yang sudah terupload dihapus lagi jika salah satu langkah gagal
+	defer func() {
+		if err != nil {
+			deleteStoredFiles(store, saved...)
+		}
+	}()
+
+	encoded, contentType, ext, err := img.Encode()
+	if err != nil {
+		return
+	}
+	obj, err := put(base+ext, encoded, contentType)
+	if err != nil {
+		return
+	}
+	stored = storedImage{URL: obj.URL, Key: obj.Key, Variants: make(map[string]model.ImageVariant, len(variants))}
+
+	type webpFile struct {
+		key, width string
+		content    []byte
+	}
+	for _, v := range variants {
+		variant := model.ImageVariant{Width: v.Width, Height: v.Height}
+		var srcset, webpSrcset []string
+		var webps []webpFile
+		webpSmaller := true
+		for scale := 1; scale <= 2; scale++ {
+			resized, ok := img.Resize(v, scale)
+			if !ok {
+				continue
+			}
+			key := base + "_" + v.Name
+			if scale > 1 {
+				key += "@" + strconv.Itoa(scale) + "x"
+			}
+			width := strconv.Itoa(v.Width*scale) + "w"
+			if encoded, contentType, ext, err = imageproc.Encode(resized); err != nil {
+				return
+			}
+			if obj, err = put(key+ext, encoded, contentType); err != nil {
+				return
+			}
+			if scale == 1 {
+				variant.URL = obj.URL
+			}
+			variant.Keys = append(variant.Keys, obj.Key)
+			srcset = append(srcset, obj.URL+" "+width)
+
+			// WebP lossless hanya disimpan jika lebih kecil dari JPEG/PNG di semua ukuran
+			if !webpSmaller {
+				continue
+			}
+			var webp []byte
+			if webp, err = imageproc.EncodeWebP(resized); err != nil {
+				return
+			}
+			webpSmaller = len(webp) < len(encoded)
+			webps = append(webps, webpFile{key: key + ".webp", width: width, content: webp})
+		}
+		for _, f := range webps {
+			if !webpSmaller {
+				break
+			}
+			if obj, err = put(f.key, f.content, "image/webp"); err != nil {
+				return
+			}
+			variant.Keys = append(variant.Keys, obj.Key)
+			webpSrcset = append(webpSrcset, obj.URL+" "+f.width)
+		}
+		variant.Srcset = strings.Join(srcset, ", ")
+		variant.WebPSrcset = strings.Join(webpSrcset, ", ")
+		stored.Variants[v.Name] = variant
+	}
+	return store, stored, nil
+}
+
+// imageKeys - Key gambar utama dan semua variant-nya yang tersimpan di dokumen
+func imageKeys(key string, variants map[string]model.ImageVariant) []string {
+	var keys []string
+	if key != "" {
+		keys = append(keys, key)
+	}
+	for _, v := range variants {
+		keys = append(keys, v.Keys...)
+	}
+	return keys
+}
+
+// staleImageKeys - Key gambar lama yang tidak dipakai lagi oleh gambar baru (upload ulang file yang sama menghasilkan key yang sama)
+func staleImageKeys(old, current []string) []string {
+	keep := make(map[string]bool, len(current))
+	for _, key := range current {
+		keep[key] = true
+	}
+	var stale []string
+	for _, key := range old {
+		if !keep[key] {
+			stale = append(stale, key)
+		}
+	}
+	return stale
+}
+
+// imageFields - Field gambar yang sama pada menu, banner dan category
+type imageFields struct {
+	Image         string                        `bson:"image,omitempty"`
+	ImageKey      string                        `bson:"image_key,omitempty"`
+	ImageVariants map[string]model.ImageVariant `bson:"image_variants,omitempty"`
+}
+
+// replaceImage - Upload gambar baru (form field "image") untuk dokumen :id di collection, lalu menghapus file gambar lama
+func replaceImage(respw http.ResponseWriter, req *http.Request, collection, folder string, variants []imageproc.Variant) {
+	user, _ := rbac.CurrentUser(req)
+
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: ID tidak valid"
+		at.WriteJSON(respw, http.StatusBadRequest, respn)
+		return
+	}
+	filter := bson.M{"_id": objectID}
+	old, err := atdb.GetOneDoc[imageFields](config.Mongoconn, collection, atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Data tidak ditemukan"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusNotFound, respn)
+		return
+	}
+
+	// Batasi ukuran body supaya file besar ditolak sebelum dibaca seluruhnya
+	req.Body = http.MaxBytesReader(respw, req.Body, imageproc.MaxUploadSize+1<<20)
+	file, _, err := req.FormFile("image")
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: File gambar tidak ada atau lebih dari 10 MB"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusBadRequest, respn)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Gagal Membaca File Gambar"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusInternalServerError, respn)
+		return
+	}
+	img, err := imageproc.Decode(content)
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: File gambar harus berupa JPG, PNG atau WebP maksimal 10 MB."
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusBadRequest, respn)
+		return
+	}
+
+	store, image, err := storeImage(folder, content, img, variants)
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Gagal Mengupload Gambar"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusInternalServerError, respn)
+		return
+	}
+	updateData := bson.M{"image": image.URL, "image_key": image.Key, "image_variants": image.Variants}
+	if _, err = atdb.UpdateOneDoc(config.Mongoconn, collection, filter, updateData); err != nil {
+		deleteStoredFiles(store, image.Keys()...)
+		var respn model.Response
+		respn.Status = "Error: Gagal menyimpan gambar"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusInternalServerError, respn)
+		return
+	}
+	deleteStoredFiles(store, staleImageKeys(imageKeys(old.ImageKey, old.ImageVariants), image.Keys())...)
+	audit.Record(config.Mongoconn, req, model.AuditUpdate, collection, objectID, old, updateData)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Gambar berhasil diupdate",
+		"data": map[string]interface{}{
+			"id":             objectID.Hex(),
+			"image":          image.URL,
+			"image_variants": image.Variants,
+		},
+		"updatedBy": user.Name,
+	})
+}
@@ -0,0 +1,301 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/inventory"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetAllIngredient - Ambil semua bahan beserta stoknya, low_stock true jika stok di bawah batas minimum
+func GetAllIngredient(respw http.ResponseWriter, req *http.Request) {
+	data, err := atdb.GetAllDoc[[]model.Ingredient](config.Mongoconn, "ingredient", bson.M{})
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Data bahan tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	var ingredients []map[string]interface{}
+	for _, ingredient := range data {
+		ingredients = append(ingredients, map[string]interface{}{
+			"id":         ingredient.ID.Hex(),
+			"name":       ingredient.Name,
+			"unit":       ingredient.Unit,
+			"stock":      ingredient.Stock,
+			"min_stock":  ingredient.MinStock,
+			"low_stock":  ingredient.Stock <= ingredient.MinStock,
+			"updated_at": ingredient.UpdatedAt,
+		})
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Data bahan berhasil diambil",
+		"data":    ingredients,
+	})
+}
+
+// CreateIngredient - Tambah bahan baru, stok awal dicatat sebagai pergerakan stok
+func CreateIngredient(respw http.ResponseWriter, req *http.Request) {
+	// User sudah divalidasi dan disimpan ke context oleh PermissionMiddleware
+	user, _ := rbac.CurrentUser(req)
+
+	var ingredient model.Ingredient
+	if err := json.NewDecoder(req.Body).Decode(&ingredient); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	if ingredient.Name == "" || ingredient.Unit == "" || ingredient.Stock < 0 || ingredient.MinStock < 0 {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Data Bahan Tidak Valid",
+			Response: "Nama dan satuan wajib diisi, stok tidak boleh minus",
+		})
+		return
+	}
+
+	initialStock := ingredient.Stock
+	ingredient.Stock = 0
+	ingredient.UpdatedAt = time.Now()
+	var err error
+	ingredient.ID, err = atdb.InsertOneDoc(config.Mongoconn, "ingredient", ingredient)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menyimpan bahan",
+			Response: err.Error(),
+		})
+		return
+	}
+	if initialStock > 0 {
+		if ingredient, err = inventory.Adjust(config.Mongoconn, ingredient.ID, initialStock, "stok awal", user.Name); err != nil {
+			at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+				Status:   "Error: Gagal mencatat stok awal",
+				Response: err.Error(),
+			})
+			return
+		}
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Bahan berhasil ditambahkan",
+		"data":    ingredient,
+	})
+}
+
+// UpdateIngredient - Ubah nama, satuan dan batas minimum bahan. Stok hanya bisa diubah lewat penyesuaian stok.
+func UpdateIngredient(respw http.ResponseWriter, req *http.Request) {
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Bahan tidak valid",
+		})
+		return
+	}
+
+	var input model.Ingredient
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	if input.Name == "" || input.Unit == "" || input.MinStock < 0 {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Data Bahan Tidak Valid",
+			Response: "Nama dan satuan wajib diisi, batas minimum tidak boleh minus",
+		})
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"name": input.Name, "unit": input.Unit, "min_stock": input.MinStock, "updated_at": time.Now()}}
+	ingredient, err := atdb.FindOneAndUpdateDoc[model.Ingredient](config.Mongoconn, "ingredient", bson.M{"_id": objectID}, update)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Bahan tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Bahan berhasil diupdate",
+		"data":    ingredient,
+	})
+}
+
+// AdjustIngredientStock - Penyesuaian stok manual: /data/ingredient/:id/adjust {"change": 1000, "reason": "restock"}
+func AdjustIngredientStock(respw http.ResponseWriter, req *http.Request) {
+	// User sudah divalidasi dan disimpan ke context oleh PermissionMiddleware
+	user, _ := rbac.CurrentUser(req)
+
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Bahan tidak valid",
+		})
+		return
+	}
+
+	var input struct {
+		Change float64 `json:"change"`
+		Reason string  `json:"reason"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	ingredient, err := inventory.Adjust(config.Mongoconn, objectID, input.Change, strings.TrimSpace(input.Reason), user.Name)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, inventory.ErrInsufficientStock) {
+			status = http.StatusConflict
+		}
+		at.WriteJSON(respw, status, model.Response{
+			Status:   "Error: Gagal menyesuaikan stok",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Stok bahan berhasil disesuaikan",
+		"data":    ingredient,
+	})
+}
+
+// GetIngredientMovements - Riwayat pergerakan stok sebuah bahan, terbaru di atas: /data/ingredient/:id/movements
+func GetIngredientMovements(respw http.ResponseWriter, req *http.Request) {
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Bahan tidak valid",
+		})
+		return
+	}
+
+	opts := options.Find().SetSort(bson.M{"at": -1})
+	cursor, err := config.Mongoconn.Collection("stock_movement").Find(req.Context(), bson.M{"ingredient_id": objectID}, opts)
+	var movements []model.StockMovement
+	if err == nil {
+		err = cursor.All(req.Context(), &movements)
+	}
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil riwayat stok",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Riwayat stok berhasil diambil",
+		"data":    movements,
+	})
+}
+
+// UpdateMenuRecipe - Atur resep satu porsi menu: /data/menu/:id/recipe {"recipe": [{"ingredient_id": "...", "quantity": 18}]}
+// Status menu yang punya resep diatur otomatis dari stok bahan.
+func UpdateMenuRecipe(respw http.ResponseWriter, req *http.Request) {
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Menu tidak valid",
+		})
+		return
+	}
+
+	var input struct {
+		Recipe []model.RecipeItem `json:"recipe"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	// Pastikan semua bahan ada dan takarannya lebih dari 0
+	var ingredientIDs []primitive.ObjectID
+	for _, r := range input.Recipe {
+		if r.Quantity <= 0 {
+			at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+				Status:   "Error: Takaran Tidak Valid",
+				Response: "Takaran bahan " + r.IngredientID.Hex() + " harus lebih dari 0",
+			})
+			return
+		}
+		ingredientIDs = append(ingredientIDs, r.IngredientID)
+	}
+	if len(ingredientIDs) > 0 {
+		count, err := atdb.GetCountDoc(config.Mongoconn, "ingredient", bson.M{"_id": bson.M{"$in": ingredientIDs}})
+		if err != nil || int(count) != len(ingredientIDs) {
+			at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+				Status:   "Error: Bahan Tidak Valid",
+				Response: "Ada bahan yang tidak ditemukan atau tercantum lebih dari sekali",
+			})
+			return
+		}
+	}
+
+	oldMenu, err := atdb.GetOneDoc[model.Menu](config.Mongoconn, "menu", atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status: "Error: Menu tidak ditemukan",
+		})
+		return
+	}
+	result, err := atdb.UpdateDoc(config.Mongoconn, "menu", bson.M{"_id": objectID}, bson.M{"$set": bson.M{"recipe": input.Recipe}})
+	if err != nil || result.MatchedCount == 0 {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status: "Error: Menu tidak ditemukan",
+		})
+		return
+	}
+	// Stok menu dihitung ulang dari resep barunya, termasuk saat bahan yang membuat menu habis dihapus dari resep
+	menu, err := atdb.GetOneDoc[model.Menu](config.Mongoconn, "menu", bson.M{"_id": objectID})
+	if err == nil {
+		err = inventory.RefreshMenu(config.Mongoconn, menu)
+	}
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal memperbarui status menu",
+			Response: err.Error(),
+		})
+		return
+	}
+	menu, _ = atdb.GetOneDoc[model.Menu](config.Mongoconn, "menu", bson.M{"_id": objectID})
+	audit.Record(config.Mongoconn, req, model.AuditUpdate, "menu", objectID, oldMenu, menu)
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Resep menu berhasil disimpan",
+		"data":    menu,
+	})
+}
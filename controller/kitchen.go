@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/kitchen"
+	"github.com/gocroot/helper/notif"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetStationTickets - Layar stasiun: tiket pesanan yang sedang diproses untuk satu stasiun, paling lama di atas.
+// /data/kitchen/:station?late_after=<menit>&done=true. Tiket yang semua barisnya sudah siap hanya tampil dengan done=true.
+func GetStationTickets(respw http.ResponseWriter, req *http.Request) {
+	station := router.Param(req, "station")
+	if !kitchen.ValidStation(station) {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Stasiun Tidak Dikenal",
+			Response: "Stasiun harus salah satu dari " + strings.Join(kitchen.Stations, ", "),
+		})
+		return
+	}
+	params := req.URL.Query()
+	var lateAfter time.Duration
+	if value := params.Get("late_after"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes <= 0 {
+			at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+				Status:   "Error: Parameter Tidak Valid",
+				Response: "late_after harus berupa jumlah menit lebih dari 0",
+			})
+			return
+		}
+		lateAfter = time.Duration(minutes) * time.Minute
+	}
+
+	orders, err := atdb.GetManyDocs[model.Order](config.Mongoconn, "orders", bson.M{"status": model.OrderStatusDiproses})
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil pesanan",
+			Response: err.Error(),
+		})
+		return
+	}
+	// Pesanan lama yang dibuat sebelum kategori dipetakan belum punya stasiun per item
+	for i := range orders {
+		if orders[i].Orders, err = kitchen.AssignStations(config.Mongoconn, orders[i].Orders); err != nil {
+			at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+				Status:   "Error: Gagal mengambil stasiun menu",
+				Response: err.Error(),
+			})
+			return
+		}
+	}
+
+	tickets := kitchen.Tickets(orders, station, time.Now(), lateAfter, params.Get("done") == "true")
+	late := 0
+	for _, ticket := range tickets {
+		if ticket.Late {
+			late++
+		}
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Tiket stasiun " + station + " berhasil diambil",
+		"station": station,
+		"late":    late,
+		"data":    tickets,
+	})
+}
+
+// BumpOrderItem - Tandai satu item pesanan siap dari layar stasiun: POST /data/order/:id/item/:index/ready.
+// Jika semua item pesanan sudah siap, pesanan otomatis berpindah ke status "siap diambil".
+func BumpOrderItem(respw http.ResponseWriter, req *http.Request) {
+	setOrderItemReady(respw, req, true)
+}
+
+// UnbumpOrderItem - Batalkan tanda siap item yang salah tekan: DELETE /data/order/:id/item/:index/ready
+func UnbumpOrderItem(respw http.ResponseWriter, req *http.Request) {
+	setOrderItemReady(respw, req, false)
+}
+
+func setOrderItemReady(respw http.ResponseWriter, req *http.Request, ready bool) {
+	user, _ := rbac.CurrentUser(req)
+
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Order tidak valid",
+		})
+		return
+	}
+	index, err := strconv.Atoi(router.Param(req, "index"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: Indeks item tidak valid",
+		})
+		return
+	}
+	order, err := atdb.GetOneDoc[model.Order](config.Mongoconn, "orders", bson.M{"_id": objectID})
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Order tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	updated, err := kitchen.SetReady(config.Mongoconn, order, index, ready, user.Name, time.Now())
+	if errors.Is(err, kitchen.ErrLineInvalid) {
+		at.WriteJSON(respw, http.StatusConflict, model.Response{
+			Status:   "Error: Item Tidak Dapat Diubah",
+			Response: err.Error(),
+		})
+		return
+	} else if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengupdate item pesanan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	// Item terakhir yang siap memindahkan pesanan ke "siap diambil", kegagalan di sini tidak membatalkan tanda siap item
+	if ready && kitchen.AllReady(updated.Orders) {
+		advanced, err := TransitionOrder(updated, model.OrderStatusSiapDiambil, user, "semua item siap")
+		if err != nil {
+			log.Println("Gagal memindahkan pesanan " + updated.OrderNumber + " ke siap diambil: " + err.Error())
+		} else {
+			audit.Record(config.Mongoconn, req, model.AuditUpdate, "orders", objectID, updated, advanced)
+			notif.SendOrderStatusAsync(advanced, config.Mongoconn)
+			updated = advanced
+		}
+	}
+
+	message := "Item ditandai siap"
+	if !ready {
+		message = "Tanda siap item dibatalkan"
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": message,
+		"user":    user.Name,
+		"data": map[string]interface{}{
+			"id":          objectID.Hex(),
+			"orderNumber": updated.OrderNumber,
+			"status":      updated.Status,
+			"orders":      updated.Orders,
+		},
+	})
+}
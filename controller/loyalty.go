@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/loyalty"
+	"github.com/gocroot/helper/phone"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/rupiah"
+	"github.com/gocroot/model"
+)
+
+// GetUserPoints - Saldo poin loyalitas, tier dan riwayat poin pengguna yang login: /data/user/points
+func GetUserPoints(respw http.ResponseWriter, req *http.Request) {
+	user, _ := rbac.CurrentUser(req)
+
+	customer := phone.NormalizePhoneNumber(user.PhoneNumber)
+	account, err := loyalty.GetAccount(config.Mongoconn, customer)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil saldo poin",
+			Response: err.Error(),
+		})
+		return
+	}
+	history, err := loyalty.GetLedger(config.Mongoconn, customer)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil riwayat poin",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	tier, next := loyalty.TierFor(account.Lifetime)
+	data := map[string]interface{}{
+		"balance":          account.Balance,
+		"balance_value":    rupiah.Format(float64(account.Balance * loyalty.PointValue)),
+		"lifetime":         account.Lifetime,
+		"tier":             tier,
+		"rupiah_per_point": loyalty.RupiahPerPoint,
+		"history":          history,
+	}
+	if next != nil {
+		data["next_tier"] = next
+		data["points_to_next_tier"] = next.MinLifetime - account.Lifetime
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Poin loyalitas berhasil diambil",
+		"data":    data,
+	})
+}
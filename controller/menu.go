@@ -9,34 +9,24 @@ import (
 	"github.com/gocroot/config"
 	"github.com/gocroot/helper/at"
 	"github.com/gocroot/helper/atdb"
-	"github.com/gocroot/helper/ghupload"
-	"github.com/gocroot/helper/watoken"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/imageproc"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/rupiah"
 	"github.com/gocroot/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 )
 
-func formatRupiah(price float64) string {
-	formatter := message.NewPrinter(language.Indonesian)
-	return formatter.Sprintf("Rp %.2f", price)
-}
-
 // upload menu sekalian sama fotonya.
 func CreateMenu(respw http.ResponseWriter, req *http.Request) {
-	payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		at.WriteJSON(respw, http.StatusForbidden, model.Response{
-			Status:   "Error: Token Tidak Valid",
-			Location: "Decode Token Error",
-			Response: err.Error(),
-		})
-		return
-	}
+	// User sudah divalidasi dan disimpan ke context oleh PermissionMiddleware
+	user, _ := rbac.CurrentUser(req)
 
 	// Parse multipart form for menu data and image
-	err = req.ParseMultipartForm(10 << 20) // Max 10 MB
+	err := req.ParseMultipartForm(10 << 20) // Max 10 MB
 	if err != nil {
 		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
 			Status:   "Error: Gagal Memproses Form Data",
@@ -71,8 +61,8 @@ func CreateMenu(respw http.ResponseWriter, req *http.Request) {
 	}
 
 	// Handle file upload
-	var menuImageURL string
-	file, header, err := req.FormFile("menuImage")
+	var menuImage storedImage
+	file, _, err := req.FormFile("menuImage")
 	if err == nil {
 		defer file.Close()
 		fileContent, err := io.ReadAll(file)
@@ -84,27 +74,25 @@ func CreateMenu(respw http.ResponseWriter, req *http.Request) {
 			return
 		}
 
-		// Upload image to GitHub
-		hashedFileName := ghupload.CalculateHash(fileContent) + header.Filename[strings.LastIndex(header.Filename, "."):]
-		pathFile := "menuImages/" + hashedFileName
-		content, _, err := ghupload.GithubUpload(
-			config.GHAccessToken,
-			"Audyardha Nasywa Andini",
-			"audyardhaandini@gmail.com",
-			fileContent,
-			"logiccoffee",
-			"img",
-			pathFile,
-			true,
-		)
+		// Validasi isi file (JPEG, PNG atau WebP), buang EXIF dan perkecil
+		img, err := imageproc.Decode(fileContent)
+		if err != nil {
+			at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+				Status:   "Error: Gambar Tidak Valid",
+				Response: err.Error(),
+			})
+			return
+		}
+
+		// Upload gambar dan variant-nya ke storage (GitHub, local atau S3 sesuai config)
+		_, menuImage, err = storeImage("menuImages", fileContent, img, imageproc.MenuVariants)
 		if err != nil {
 			at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
-				Status:   "Error: Gagal Mengupload Gambar Ke GitHub",
+				Status:   "Error: Gagal Mengupload Gambar",
 				Response: err.Error(),
 			})
 			return
 		}
-		menuImageURL = *content.Content.HTMLURL
 	}
 
 	// Sanitasi input harga untuk menghapus "Rp", ".", atau ","
@@ -125,12 +113,14 @@ func CreateMenu(respw http.ResponseWriter, req *http.Request) {
 
 	// Buat menu baru
 	newMenu := model.Menu{
-		CategoryID:  categoryObjectID,
-		Name:        name,
-		Description: description,
-		Image:       menuImageURL,
-		Price:       menuPrice,
-		Status:      status,
+		CategoryID:    categoryObjectID,
+		Name:          name,
+		Description:   description,
+		Image:         menuImage.URL,
+		ImageKey:      menuImage.Key,
+		ImageVariants: menuImage.Variants,
+		Price:         menuPrice,
+		Status:        status,
 	}
 
 	// Simpan ke database
@@ -144,66 +134,95 @@ func CreateMenu(respw http.ResponseWriter, req *http.Request) {
 	}
 
 	newMenu.ID = insertResult
+	audit.Record(config.Mongoconn, req, model.AuditCreate, "menu", newMenu.ID, nil, newMenu)
 	response := map[string]interface{}{
 		"status":  "success",
 		"message": "Menu berhasil ditambahkan",
-		"user":    payload.Alias,
+		"user":    user.Name,
 		"data": map[string]interface{}{
-			"id":          newMenu.ID.Hex(),
-			"category_id": newMenu.CategoryID.Hex(),
-			"name":        newMenu.Name,
-			"description": newMenu.Description,
-			"image":       newMenu.Image,
-			"price":       formatRupiah(newMenu.Price),
-			"status":      newMenu.Status,
+			"id":             newMenu.ID.Hex(),
+			"category_id":    newMenu.CategoryID.Hex(),
+			"name":           newMenu.Name,
+			"description":    newMenu.Description,
+			"image":          newMenu.Image,
+			"image_variants": newMenu.ImageVariants,
+			"price":          rupiah.Format(newMenu.Price),
+			"status":         newMenu.Status,
 		},
 	}
 
 	at.WriteJSON(respw, http.StatusOK, response)
 }
 
-// GetAllMenu - Ambil Semua Data Menu
+// GetAllMenu - Ambil Data Menu per halaman.
+// Query: ?page=&limit=&cursor=&sort=name|price&order=asc|desc&category_id=&status=&q=
 func GetAllMenu(respw http.ResponseWriter, req *http.Request) {
-	data, err := atdb.GetAllDoc[[]model.Menu](config.Mongoconn, "menu", bson.M{})
+	params := req.URL.Query()
+	q, err := atdb.ParsePageQuery(params, "name", false, "name", "price")
 	if err != nil {
-		at.WriteJSON(respw, http.StatusNotFound, model.Response{
-			Status:   "Error: Data menu tidak ditemukan",
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Parameter Tidak Valid",
 			Response: err.Error(),
 		})
 		return
 	}
+	filter := bson.M{}
+	if categoryID := params.Get("category_id"); categoryID != "" {
+		objectID, err := primitive.ObjectIDFromHex(categoryID)
+		if err != nil {
+			at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+				Status: "Error: ID Category tidak valid",
+			})
+			return
+		}
+		filter["category_id"] = objectID
+	}
+	if status := params.Get("status"); status != "" {
+		filter["status"] = status
+		// menu yang stok bahannya habis tidak ikut daftar menu yang bisa dipesan
+		if pesanan.IsMenuAvailable(status) {
+			filter["out_of_stock"] = bson.M{"$ne": true}
+		}
+	}
+	atdb.MatchText(filter, params.Get("q"), "name", "description")
 
-	if len(data) == 0 {
-		at.WriteJSON(respw, http.StatusNotFound, model.Response{
-			Status: "Error: Data menu kosong",
+	data, page, err := atdb.GetPagedDocs[model.Menu](config.Mongoconn, "menu", atdb.ExcludeDeleted(filter), q)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Data menu tidak ditemukan",
+			Response: err.Error(),
 		})
 		return
 	}
 
-	var menus []map[string]interface{}
+	menus := make([]map[string]interface{}, 0, len(data))
 	for _, menu := range data {
 		menus = append(menus, map[string]interface{}{
-			"id":          menu.ID.Hex(),
-			"category_id": menu.CategoryID.Hex(),
-			"name":        menu.Name,
-			"description": menu.Description,
-			"image":       menu.Image,
-			"price":       formatRupiah(menu.Price),
-			"status":      menu.Status,
+			"id":             menu.ID.Hex(),
+			"category_id":    menu.CategoryID.Hex(),
+			"name":           menu.Name,
+			"description":    menu.Description,
+			"image":          menu.Image,
+			"image_variants": menu.ImageVariants,
+			"price":          rupiah.Format(menu.Price),
+			"status":         menu.Status,
+			"out_of_stock":   menu.OutOfStock,
+			"modifiers":      menu.Modifiers,
+			"points_price":   menu.PointsPrice,
 		})
 	}
 
 	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
-		"status":  "success",
-		"message": "Data menu berhasil diambil",
-		"data":    menus,
+		"status":     "success",
+		"message":    "Data menu berhasil diambil",
+		"data":       menus,
+		"pagination": page,
 	})
 }
 
 // GetMenuByID - Ambil Menu Berdasarkan ID
 func GetMenuByID(respw http.ResponseWriter, req *http.Request) {
-	pathParts := strings.Split(req.URL.Path, "/")
-	menuID := pathParts[len(pathParts)-1]
+	menuID := router.Param(req, "id")
 	if menuID == "" {
 		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
 			Status: "Error: ID Menu tidak ditemukan di URL",
@@ -219,7 +238,7 @@ func GetMenuByID(respw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	menu, err := atdb.GetOneDoc[model.Menu](config.Mongoconn, "menu", bson.M{"_id": objectID})
+	menu, err := atdb.GetOneDoc[model.Menu](config.Mongoconn, "menu", atdb.ExcludeDeleted(bson.M{"_id": objectID}))
 	if err != nil {
 		at.WriteJSON(respw, http.StatusNotFound, model.Response{
 			Status:   "Error: Menu tidak ditemukan",
@@ -232,34 +251,28 @@ func GetMenuByID(respw http.ResponseWriter, req *http.Request) {
 		"status":  "success",
 		"message": "Menu ditemukan",
 		"data": map[string]interface{}{
-			"id":          menu.ID.Hex(),
-			"category_id": menu.CategoryID.Hex(),
-			"name":        menu.Name,
-			"description": menu.Description,
-			"image":       menu.Image,
-			"price":       formatRupiah(menu.Price),
-			"status":      menu.Status,
+			"id":             menu.ID.Hex(),
+			"category_id":    menu.CategoryID.Hex(),
+			"name":           menu.Name,
+			"description":    menu.Description,
+			"image":          menu.Image,
+			"image_variants": menu.ImageVariants,
+			"price":          rupiah.Format(menu.Price),
+			"status":         menu.Status,
+			"out_of_stock":   menu.OutOfStock,
+			"modifiers":      menu.Modifiers,
+			"points_price":   menu.PointsPrice,
 		},
 	}
 	at.WriteJSON(respw, http.StatusOK, response)
 }
 
 func UpdateMenu(respw http.ResponseWriter, req *http.Request) {
-	// Ambil token dari header dan decode menggunakan public key
-	payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: Token Tidak Valid"
-		respn.Info = at.GetSecretFromHeader(req)
-		respn.Location = "Decode Token Error"
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusForbidden, respn)
-		return
-	}
+	// User sudah divalidasi dan disimpan ke context oleh PermissionMiddleware
+	user, _ := rbac.CurrentUser(req)
 
 	// Ambil ID menu dari URL
-	pathParts := strings.Split(req.URL.Path, "/")
-	menuID := pathParts[len(pathParts)-1] // Ambil bagian terakhir dari URL
+	menuID := router.Param(req, "id")
 	if menuID == "" {
 		var respn model.Response
 		respn.Status = "Error: ID Menu tidak ditemukan di URL"
@@ -276,6 +289,16 @@ func UpdateMenu(respw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Data menu sebelum diubah, untuk audit log dan menghapus gambar lama
+	oldMenu, err := atdb.GetOneDoc[model.Menu](config.Mongoconn, "menu", atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+	if err != nil {
+		var respn model.Response
+		respn.Status = "Error: Menu tidak ditemukan"
+		respn.Response = err.Error()
+		at.WriteJSON(respw, http.StatusNotFound, respn)
+		return
+	}
+
 	// Menyiapkan FormData
 	err = req.ParseMultipartForm(10 << 20) // 10 MB max size for file uploads
 	if err != nil {
@@ -319,9 +342,29 @@ func UpdateMenu(respw http.ResponseWriter, req *http.Request) {
 			at.WriteJSON(respw, http.StatusBadRequest, respn)
 			return
 		}
+		// harga baru tidak boleh membuat opsi modifier yang sudah ada bernilai minus
+		if err := pesanan.ValidateModifierGroups(oldMenu.Modifiers, priceFloat); err != nil {
+			var respn model.Response
+			respn.Status = "Error: Harga terlalu kecil untuk modifier menu"
+			respn.Response = err.Error()
+			at.WriteJSON(respw, http.StatusBadRequest, respn)
+			return
+		}
 		updateData["price"] = priceFloat
 	}
 
+	// Poin untuk menukar satu porsi gratis, 0 berarti menu tidak bisa ditukar
+	if pointsPrice := req.FormValue("points_price"); pointsPrice != "" {
+		points, err := strconv.Atoi(pointsPrice)
+		if err != nil || points < 0 {
+			var respn model.Response
+			respn.Status = "Error: Poin harus berupa angka dan tidak boleh minus"
+			at.WriteJSON(respw, http.StatusBadRequest, respn)
+			return
+		}
+		updateData["points_price"] = points
+	}
+
 	// Setting status
 	validStatuses := map[string]bool{
 		"tersedia":       true,
@@ -348,7 +391,8 @@ func UpdateMenu(respw http.ResponseWriter, req *http.Request) {
 	updateData["category_id"] = catObjectID
 
 	// Handle file upload if "menuImage" is provided in request
-	if file, header, err := req.FormFile("menuImage"); err == nil {
+	var replacedImage func() // hapus gambar lama, dijalankan setelah menu berhasil diupdate
+	if file, _, err := req.FormFile("menuImage"); err == nil {
 		defer file.Close()
 
 		// Baca konten file terlebih dahulu
@@ -361,46 +405,32 @@ func UpdateMenu(respw http.ResponseWriter, req *http.Request) {
 			return
 		}
 
-		// Validasi tipe file berdasarkan ekstensi
-		if !strings.HasSuffix(strings.ToLower(header.Filename), ".jpg") &&
-			!strings.HasSuffix(strings.ToLower(header.Filename), ".png") {
-			var respn model.Response
-			respn.Status = "Error: File gambar harus berupa JPG atau PNG."
-			at.WriteJSON(respw, http.StatusBadRequest, respn)
-			return
-		}
-
-		// Validasi ukuran file
-		if len(fileContent) > 2*1024*1024 { // 2 MB
+		// Validasi isi file (JPEG, PNG atau WebP, maksimal 10 MB), buang EXIF dan perkecil
+		img, err := imageproc.Decode(fileContent)
+		if err != nil {
 			var respn model.Response
-			respn.Status = "Error: Ukuran file gambar tidak boleh lebih dari 2 MB."
+			respn.Status = "Error: File gambar harus berupa JPG, PNG atau WebP maksimal 10 MB."
+			respn.Response = err.Error()
 			at.WriteJSON(respw, http.StatusBadRequest, respn)
 			return
 		}
 
-		// Proses upload gambar ke GitHub
-		hashedFileName := ghupload.CalculateHash(fileContent) + header.Filename[strings.LastIndex(header.Filename, "."):]
-		pathFile := "menuImages/" + hashedFileName
-		content, _, err := ghupload.GithubUpload(
-			config.GHAccessToken,
-			"Audyardha Nasywa Andini",
-			"audyardhaandini@gmail.com",
-			fileContent,
-			"logiccoffee",
-			"img",
-			pathFile,
-			true,
-		)
+		// Proses upload gambar dan variant-nya ke storage
+		store, image, err := storeImage("menuImages", fileContent, img, imageproc.MenuVariants)
 		if err != nil {
 			var respn model.Response
-			respn.Status = "Error: Gagal Mengupload Gambar Ke GitHub"
+			respn.Status = "Error: Gagal Mengupload Gambar"
 			respn.Response = err.Error()
 			at.WriteJSON(respw, http.StatusInternalServerError, respn)
 			return
 		}
 
-		// Simpan URL gambar ke data update
-		updateData["image"] = *content.Content.HTMLURL
+		// Simpan URL, key dan variant gambar ke data update
+		updateData["image"] = image.URL
+		updateData["image_key"] = image.Key
+		updateData["image_variants"] = image.Variants
+		stale := staleImageKeys(imageKeys(oldMenu.ImageKey, oldMenu.ImageVariants), image.Keys())
+		replacedImage = func() { deleteStoredFiles(store, stale...) }
 	}
 
 	// Jika tidak ada perubahan data, beri respon error
@@ -428,6 +458,12 @@ func UpdateMenu(respw http.ResponseWriter, req *http.Request) {
 		at.WriteJSON(respw, http.StatusNotModified, respn)
 		return
 	}
+	if replacedImage != nil {
+		replacedImage()
+	}
+	if newMenu, err := atdb.GetOneDoc[model.Menu](config.Mongoconn, "menu", bson.M{"_id": objectID}); err == nil {
+		audit.Record(config.Mongoconn, req, model.AuditUpdate, "menu", objectID, oldMenu, newMenu)
+	}
 
 	// Respons sukses
 	response := map[string]interface{}{
@@ -437,67 +473,17 @@ func UpdateMenu(respw http.ResponseWriter, req *http.Request) {
 			"id":            objectID.Hex(),
 			"updatedFields": updateData,
 		},
-		"updatedBy": payload.Alias,
+		"updatedBy": user.Name,
 	}
 	at.WriteJSON(respw, http.StatusOK, response)
 }
 
+// DeleteMenu - Soft delete menu, menu tidak tampil di daftar dan tidak bisa dipesan tetapi bisa dikembalikan
 func DeleteMenu(respw http.ResponseWriter, req *http.Request) {
-	// Ambil token dari header
-	payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: Token Tidak Valid"
-		respn.Info = at.GetSecretFromHeader(req)
-		respn.Location = "Decode Token Error"
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusForbidden, respn)
-		return
-	}
-
-	// Ambil ID menu dari URL
-	pathParts := strings.Split(req.URL.Path, "/")
-	menuID := pathParts[len(pathParts)-1] // Ambil bagian terakhir dari URL
-	if menuID == "" {
-		var respn model.Response
-		respn.Status = "Error: ID Menu tidak ditemukan di URL"
-		at.WriteJSON(respw, http.StatusBadRequest, respn)
-		return
-	}
-
-	// Konversi ID menu ke ObjectID MongoDB
-	objectID, err := primitive.ObjectIDFromHex(menuID)
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: ID Menu tidak valid"
-		at.WriteJSON(respw, http.StatusBadRequest, respn)
-		return
-	}
-
-	// Hapus data menu berdasarkan ID
-	filter := bson.M{"_id": objectID}
-	deleteResult, err := atdb.DeleteOneDoc(config.Mongoconn, "menu", filter)
-	if err != nil {
-		var respn model.Response
-		respn.Status = "Error: Gagal menghapus menu"
-		respn.Response = err.Error()
-		at.WriteJSON(respw, http.StatusInternalServerError, respn)
-		return
-	}
-
-	if deleteResult.DeletedCount == 0 {
-		var respn model.Response
-		respn.Status = "Error: Menu tidak ditemukan"
-		at.WriteJSON(respw, http.StatusNotFound, respn)
-		return
-	}
+	softDeleteDoc[model.Menu](respw, req, "menu", "Menu")
+}
 
-	// Berhasil menghapus menu
-	response := map[string]interface{}{
-		"status":  "success",
-		"message": "Menu berhasil dihapus",
-		"user":    payload.Alias,
-		"data":    deleteResult,
-	}
-	at.WriteJSON(respw, http.StatusOK, response)
+// RestoreMenu - Mengembalikan menu yang sudah dihapus
+func RestoreMenu(respw http.ResponseWriter, req *http.Request) {
+	restoreDoc[model.Menu](respw, req, "menu", "Menu")
 }
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/menusearch"
+	"github.com/gocroot/helper/rupiah"
+	"github.com/gocroot/model"
+)
+
+// SearchMenu - Cari menu dengan toleransi salah ketik: /data/menu/search?q=kopsu&limit=10
+// Hasil diurutkan dari yang paling cocok, suggestion berisi koreksi query untuk "mungkin maksud anda"
+func SearchMenu(respw http.ResponseWriter, req *http.Request) {
+	query := strings.TrimSpace(req.URL.Query().Get("q"))
+	if query == "" {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Parameter Tidak Valid",
+			Response: "Kata pencarian (q) harus diisi",
+		})
+		return
+	}
+	limit := 10
+	if s := req.URL.Query().Get("limit"); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 {
+			at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+				Status:   "Error: Parameter Tidak Valid",
+				Response: "limit harus angka mulai dari 1",
+			})
+			return
+		}
+	}
+
+	results, suggestion, err := menusearch.Search(config.Mongoconn, query, limit)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mencari menu",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	menus := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		menus = append(menus, map[string]interface{}{
+			"id":             result.Menu.ID.Hex(),
+			"category_id":    result.Menu.CategoryID.Hex(),
+			"category":       result.Category,
+			"name":           result.Menu.Name,
+			"description":    result.Menu.Description,
+			"image":          result.Menu.Image,
+			"image_variants": result.Menu.ImageVariants,
+			"price":          rupiah.Format(result.Menu.Price),
+			"status":         result.Menu.Status,
+			"out_of_stock":   result.Menu.OutOfStock,
+			"score":          math.Round(result.Score*100) / 100,
+		})
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":     "success",
+		"message":    "Pencarian menu berhasil",
+		"data":       menus,
+		"suggestion": suggestion,
+	})
+}
@@ -2,41 +2,132 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gocroot/config"
 	"github.com/gocroot/helper/at"
 	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/table"
 	"github.com/gocroot/helper/watoken"
 	"github.com/gocroot/model"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// RoleMiddleware - Membatasi route hanya untuk role tertentu, untuk route baru gunakan PermissionMiddleware
 func RoleMiddleware(allowedRoles []string) func(http.HandlerFunc) http.HandlerFunc {
-    return func(next http.HandlerFunc) http.HandlerFunc {
-        return func(w http.ResponseWriter, r *http.Request) {
-            payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(r))
-            if err != nil {
-                http.Error(w, "Forbidden: Invalid Token", http.StatusForbidden)
-                return
-            }
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+			user, _ := rbac.CurrentUser(r)
+			for _, allowedRole := range allowedRoles {
+				if user.Role == allowedRole {
+					next(w, r)
+					return
+				}
+			}
+			at.WriteJSON(w, http.StatusForbidden, model.Response{
+				Status:   "Error: Akses Ditolak",
+				Response: "Role " + user.Role + " tidak memiliki akses",
+			})
+		})
+	}
+}
+
+// AuthMiddleware - Memastikan token valid dan user terdaftar, lalu menyimpan user ke context request
+// sehingga handler cukup memanggil rbac.CurrentUser tanpa query ulang ke koleksi user
+func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(r))
+		if err != nil {
+			at.WriteJSON(w, http.StatusForbidden, model.Response{
+				Status:   "Error: Token Tidak Valid",
+				Location: "Decode Token Error",
+				Response: err.Error(),
+			})
+			return
+		}
+		docuser, err := atdb.GetOneDoc[model.Userdomyikado](config.Mongoconn, "user", bson.M{"phonenumber": payload.Id})
+		if err != nil {
+			at.WriteJSON(w, http.StatusForbidden, model.Response{
+				Status:   "Error: Data Pengguna Tidak Ditemukan",
+				Response: err.Error(),
+			})
+			return
+		}
+		next(w, rbac.WithUser(r, docuser))
+	}
+}
 
-            docuser, err := atdb.GetOneDoc[model.Userdomyikado](config.Mongoconn, "user", bson.M{"phonenumber": payload.Id})
-            if err != nil || docuser.Role == "" {
-                http.Error(w, "Forbidden: Role not found", http.StatusForbidden)
-                return
-            }
+// TableOrAuthMiddleware - Untuk pesanan dine-in: header Table berisi token QR meja, mejanya disimpan ke context
+// dan pelanggan tidak perlu login. Tanpa header Table sama dengan AuthMiddleware, dengan keduanya (kasir
+// memesankan untuk meja) user dan meja sama-sama disimpan.
+func TableOrAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	auth := AuthMiddleware(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Table")
+		if token == "" {
+			auth(w, r)
+			return
+		}
+		t, err := table.Verify(config.Mongoconn, config.PublicKeyWhatsAuth, token)
+		if errors.Is(err, table.ErrTokenInvalid) {
+			at.WriteJSON(w, http.StatusForbidden, model.Response{
+				Status:   "Error: QR Meja Tidak Valid",
+				Response: err.Error(),
+			})
+			return
+		} else if err != nil {
+			at.WriteJSON(w, http.StatusInternalServerError, model.Response{
+				Status:   "Error: Gagal mengambil data meja",
+				Response: err.Error(),
+			})
+			return
+		}
+		r = table.WithTable(r, t)
+		if at.GetLoginFromHeader(r) != "" {
+			auth(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
 
-            for _, allowedRole := range allowedRoles {
-                if docuser.Role == allowedRole {
-                    next(w, r)
-                    return
-                }
-            }
+// StreamLoginMiddleware - EventSource di browser tidak bisa mengirim header Login, sehingga untuk route stream
+// token juga diterima dari cookie login atau query ?login=. Dipasang sebelum AuthMiddleware/PermissionMiddleware.
+// Utamakan cookie, token di query bisa tercatat di log akses.
+func StreamLoginMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if at.GetLoginFromHeader(r) == "" {
+			token := r.URL.Query().Get("login")
+			if cookie, err := r.Cookie("login"); err == nil && cookie.Value != "" {
+				token = cookie.Value
+			}
+			if token != "" {
+				r.Header.Set("Login", token)
+			}
+		}
+		next(w, r)
+	}
+}
 
-            http.Error(w, "Forbidden: Access Denied", http.StatusForbidden)
-        }
-    }
+// PermissionMiddleware - Seperti AuthMiddleware, ditambah cek role user memiliki semua permission yang diminta
+func PermissionMiddleware(permissions ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+			user, _ := rbac.CurrentUser(r)
+			for _, permission := range permissions {
+				if !rbac.Allowed(config.Mongoconn, user.Role, permission) {
+					at.WriteJSON(w, http.StatusForbidden, model.Response{
+						Status:   "Error: Akses Ditolak",
+						Response: "Permission " + permission + " dibutuhkan",
+					})
+					return
+				}
+			}
+			next(w, r)
+		})
+	}
 }
 
 // MenuHandler - Handler untuk halaman Menu, hanya untuk role user dan dosen
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UpdateMenuModifiers - Atur grup modifier menu: /data/menu/:id/modifiers {"modifiers": [{"name": "Ukuran", "type": "single", ...}]}
+func UpdateMenuModifiers(respw http.ResponseWriter, req *http.Request) {
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Menu tidak valid",
+		})
+		return
+	}
+
+	var input struct {
+		Modifiers []model.ModifierGroup `json:"modifiers"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	filter := atdb.ExcludeDeleted(bson.M{"_id": objectID})
+	oldMenu, err := atdb.GetOneDoc[model.Menu](config.Mongoconn, "menu", filter)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Menu tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	if err := pesanan.ValidateModifierGroups(input.Modifiers, oldMenu.Price); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Modifier Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+	update := bson.M{"$set": bson.M{"modifiers": input.Modifiers}}
+	menu, err := atdb.FindOneAndUpdateDoc[model.Menu](config.Mongoconn, "menu", filter, update)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Menu tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	audit.Record(config.Mongoconn, req, model.AuditUpdate, "menu", objectID, oldMenu, menu)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Modifier menu berhasil disimpan",
+		"data":    menu,
+	})
+}
@@ -2,29 +2,30 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"time"
 	"strings"
-	"sync"
-	"fmt"
 
 	"github.com/gocroot/config"
 	"github.com/gocroot/helper/at"
 	"github.com/gocroot/helper/atdb"
-	"github.com/gocroot/helper/watoken"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/checkout"
+	"github.com/gocroot/helper/notif"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/payment"
+	"github.com/gocroot/helper/phone"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/rupiah"
+	"github.com/gocroot/helper/table"
 	"github.com/gocroot/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 )
 
-// mengubah ke format rupiah
-func formatrupiah(price float64) string {
-	formatter := message.NewPrinter(language.Indonesian)
-	return formatter.Sprintf("Rp %.2f", price)
-}
-
 // ngubah ke format rupiah buat price yang di dalam array orderitem
 // TAPI GA DIPAKE DISIMPEN AJA SOALNYA KALI AJA BUTUH HAHAHA
 // func SendFormattedOrder(respw http.ResponseWriter, orders []model.OrderItem) {
@@ -34,7 +35,7 @@ func formatrupiah(price float64) string {
 // 			"menu_name":       item.MenuName,
 // 			"quantity":        item.Quantity,
 // 			"price":           item.Price, 
-// 			"price_formatted": formatrupiah(item.Price), 
+// 			"price_formatted": rupiah.Format(item.Price), 
 // 		}
 // 	}
 
@@ -53,57 +54,10 @@ func FormatToIndonesianTime(t time.Time) (string, error) {
 	return t.In(loc).Format("02-01-2006 15:04:05"), nil
 }
 
-// ini buat generate ordernumber sama orderqueue
-// Mutex untuk menghindari race condition saat mengakses nomor antrean
-var queueMutex sync.Mutex
-
-// Variabel untuk menyimpan antrean dan tanggal terakhir
-var dailyQueueNumber int
-var lastQueueDate string
-
-// GenerateOrderNumber - Menghasilkan nomor order baru
-func GenerateOrderNumber() (string, int) {
-	queueMutex.Lock()
-	defer queueMutex.Unlock()
-
-	// Mendapatkan tanggal hari ini
-	currentDate := time.Now().Format("20060102")
-
-	// Jika tanggal berubah, reset antrean
-	if currentDate != lastQueueDate {
-		lastQueueDate = currentDate
-		dailyQueueNumber = 0
-	}
-
-	// Increment antrean
-	dailyQueueNumber++
-
-	// Batas maksimum antrean
-	if dailyQueueNumber > 500 {
-		dailyQueueNumber = 1
-	}
-
-	// Mengambil waktu sekarang
-	currentTime := time.Now()
-
-	// Membuat nomor order dengan format: LGCYYYYMMDDHHMMSS + queueNumber
-	orderNumber := fmt.Sprintf("LGC%s%03d", currentTime.Format("20060102150405"), dailyQueueNumber)
-
-	return orderNumber, dailyQueueNumber
-}
-
 // CreateOrder - Membuat order baru
 func CreateOrder(respw http.ResponseWriter, req *http.Request) {
-	// Dekode token WhatsAuth untuk mengambil informasi pengguna
-	payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		at.WriteJSON(respw, http.StatusForbidden, model.Response{
-			Status:   "Error: Token Tidak Valid",
-			Location: "Decode Token Error",
-			Response: err.Error(),
-		})
-		return
-	}
+	// User dari context dipakai untuk mencatat CreatedBy dan CreatedByRole
+	user, _ := rbac.CurrentUser(req)
 
 	// Ambil data JSON dari body request
 	var order model.Order
@@ -115,138 +69,184 @@ func CreateOrder(respw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Dapatkan data user dari token untuk mencatat CreatedBy dan CreatedByRole
-	var user model.Userdomyikado
-	filter := bson.M{"phonenumber": payload.Id}
-	user, err = atdb.GetOneDoc[model.Userdomyikado](config.Mongoconn, "user", filter)
-	if err != nil {
-		at.WriteJSON(respw, http.StatusNotFound, model.Response{
-			Status:   "Error: Data Pengguna Tidak Ditemukan",
-			Response: err.Error(),
-		})
-		return
+	// Pesanan dari QR meja tidak perlu login, pembuat order dicatat dari nama pelanggan
+	t, fromTable := table.CurrentTable(req)
+	order.TableID, order.TableNumber = t.ID, t.Number
+	if fromTable && user.ID.IsZero() {
+		user = model.Userdomyikado{Name: order.UserInfo.Name, Role: "meja"}
 	}
 
-	// Pastikan UserInfo diisi manual di backend berdasarkan data request
-	if order.UserInfo.Name == "" || order.UserInfo.Whatsapp == "" {
-		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
-			Status:   "Error: Bad Request",
-			Response: "UserInfo harus berisi Name dan Whatsapp",
-		})
-		return
-	}
+	submitOrder(respw, req, order, user)
+}
 
+// submitOrder - Memproses order dari client lewat checkout.Submit lalu mengirim respons.
+// Dipakai CreateOrder dan order lapak (HandleOrder), bot WhatsApp memakai checkout.Submit yang sama.
+func submitOrder(respw http.ResponseWriter, req *http.Request, order model.Order, user model.Userdomyikado) {
 	// Validasi PaymentMethod
-	allowedPaymentMethods := map[string]bool{"Cash": true}
-	if !allowedPaymentMethods[order.PaymentMethod] {
+	usesProvider, allowed := paymentMethods[order.PaymentMethod]
+	if !allowed {
 		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
 			Status:   "Error: Invalid Payment Method",
-			Response: "Metode pembayaran hanya diperbolehkan 'Cash'",
+			Response: "Metode pembayaran hanya diperbolehkan 'Cash' atau 'QRIS'",
 		})
 		return
 	}
-
-	// Ambil waktu dalam format Indonesia
-	location, err := time.LoadLocation("Asia/Jakarta")
-	if err != nil {
-		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
-			Status:   "Error: Gagal memuat zona waktu Indonesia",
-			Response: err.Error(),
-		})
-		return
+	var provider payment.Provider
+	if usesProvider {
+		var err error
+		provider, err = ActivePaymentProvider()
+		if err != nil {
+			at.WriteJSON(respw, http.StatusServiceUnavailable, model.Response{
+				Status:   "Error: Pembayaran " + order.PaymentMethod + " Belum Tersedia",
+				Response: err.Error(),
+			})
+			return
+		}
 	}
-	currentTimeInID := time.Now().In(location)
 
-	// Format waktu Indonesia untuk respons
-	orderDateInID, err := FormatToIndonesianTime(currentTimeInID)
+	// pesanan dari meja dan lapak tidak login, pelaku audit dicatat dari pembuat pesanan
+	actor := audit.ActorOf(req)
+	actor.ID, actor.Name, actor.Role = user.ID, user.Name, user.Role
+	result, err := checkout.Submit(config.Mongoconn, config.MongoconnGeo, checkout.Input{
+		Order:    order,
+		User:     user,
+		Customer: verifiedCustomer(user),
+		Staff:    IsOrderStaff(user.Role),
+		Provider: provider,
+		Actor:    actor,
+	})
 	if err != nil {
-		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
-			Status:   "Error: Gagal memformat waktu",
-			Response: err.Error(),
-		})
+		writeCheckoutError(respw, err)
 		return
 	}
+	newOrder := result.Order
 
-	// Menghasilkan OrderNumber dan QueueNumber
-	OrderNumber, QueueNumber := GenerateOrderNumber()
-
-	// Membuat order baru berdasarkan data dari frontend
-	newOrder := model.Order{
-		OrderNumber:   OrderNumber,
-		QueueNumber:   QueueNumber,
-		OrderDate:     currentTimeInID, // Gunakan waktu Indonesia
-		UserID:        user.ID,         // UserID hanya untuk referensi
-		UserInfo: model.UserInfo{
-			Name:     order.UserInfo.Name,
-			Whatsapp: order.UserInfo.Whatsapp,
-			Note:     order.UserInfo.Note, // Note opsional
-		},
-		Orders:        order.Orders,
-		Total:         order.Total,
-		PaymentMethod: order.PaymentMethod,
-		Status:        "terkirim",
-		CreatedBy:     user.Name, // Dari data user
-		CreatedByRole: user.Role, // Dari data user
-	}
-
-	// Simpan order baru ke database
-	insertResult, err := atdb.InsertOneDoc(config.Mongoconn, "orders", newOrder)
+	// Format waktu Indonesia untuk respons
+	orderDateInID, err := FormatToIndonesianTime(newOrder.OrderDate)
 	if err != nil {
-		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
-			Status:   "Error: Gagal Insert Database",
-			Response: err.Error(),
-		})
-		return
+		orderDateInID = newOrder.OrderDate.String()
 	}
 
-	// Tambahkan ID yang dihasilkan ke newOrder
-	newOrder.ID = insertResult
-
 	// Membuat response lengkap
 	response := map[string]interface{}{
 		"status":         "success",
 		"message":        "Order berhasil dibuat",
 		"formatted_date": orderDateInID, // Tanggal dalam format Indonesia
 		"data":           newOrder,
-		"total":          formatRupiah(newOrder.Total),
+		"total":          rupiah.Format(newOrder.Total),
+	}
+	// Laporkan harga yang berubah supaya frontend bisa memperbarui keranjang. Total dari client adalah total keranjang,
+	// jadi dibandingkan dengan total item sebelum voucher, poin dan ongkir
+	if len(result.Discrepancies) > 0 || order.Total != result.ItemsTotal {
+		response["price_changed"] = true
+		response["price_discrepancies"] = result.Discrepancies
+		response["client_total"] = order.Total
 	}
 
 	// Kirim response ke client
 	at.WriteJSON(respw, http.StatusOK, response)
 }
 
-// GetAllOrder - Ambil Semua Data Order
+// writeCheckoutError - Mengirim kegagalan checkout.Submit dengan kode HTTP dan status dari checkout.Error
+func writeCheckoutError(respw http.ResponseWriter, err error) {
+	var failed *checkout.Error
+	if !errors.As(err, &failed) {
+		failed = &checkout.Error{Code: http.StatusInternalServerError, Status: "Error: Gagal membuat pesanan", Err: err}
+	}
+	at.WriteJSON(respw, failed.Code, model.Response{
+		Status:   failed.Status,
+		Response: failed.Err.Error(),
+	})
+}
+
+// formatPickupTime - Waktu ambil pesanan terjadwal dalam format Indonesia, kosong untuk pesanan biasa
+func formatPickupTime(pickupAt time.Time) string {
+	if pickupAt.IsZero() {
+		return ""
+	}
+	formatted, err := FormatToIndonesianTime(pickupAt)
+	if err != nil {
+		return pickupAt.String()
+	}
+	return formatted
+}
+
+// verifiedCustomer - Nomor WhatsApp pelanggan yang terverifikasi lewat login WhatsAuth. Kosong untuk pesanan tanpa login
+// (meja, lapak) dan pesanan yang dibuat staf, karena nomor WhatsApp pada body pesanan hanya ketikan.
+func verifiedCustomer(user model.Userdomyikado) string {
+	if user.ID.IsZero() || IsOrderStaff(user.Role) {
+		return ""
+	}
+	return phone.NormalizePhoneNumber(user.PhoneNumber)
+}
+
+// orderListFilter - Filter daftar order dari query: status, payment_status, type dan rentang tanggal order (from, to)
+func orderListFilter(params url.Values) (bson.M, error) {
+	filter := bson.M{}
+	if status := params.Get("status"); status != "" {
+		filter["status"] = status
+	}
+	if paymentStatus := params.Get("payment_status"); paymentStatus != "" {
+		filter["payment_status"] = paymentStatus
+	}
+	if orderType := params.Get("type"); orderType != "" {
+		filter["type"] = orderType
+	}
+	from, to, err := atdb.ParseDateRange(params)
+	if err != nil {
+		return nil, err
+	}
+	atdb.MatchDateRange(filter, "orderDate", from, to)
+	return filter, nil
+}
+
+// GetAllOrder - Ambil Data Order per halaman. Pesanan terjadwal baru tampil setelah masuk antrean aktif (queue_at),
+// scheduled=upcoming untuk pesanan terjadwal yang belum masuk antrean dan scheduled=all untuk semua pesanan.
+// Query: ?page=&limit=&cursor=&sort=orderDate|total|queueNumber&order=asc|desc&status=&payment_status=&type=&from=&to=&q=&scheduled=
 func GetAllOrder(respw http.ResponseWriter, req *http.Request) {
-	// Dekode token WhatsAuth untuk validasi
-	_, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
+	params := req.URL.Query()
+	q, err := atdb.ParsePageQuery(params, "orderDate", true, "orderDate", "total", "queueNumber")
 	if err != nil {
-		at.WriteJSON(respw, http.StatusForbidden, model.Response{
-			Status:   "Error: Token Tidak Valid",
-			Location: "Decode Token Error",
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Parameter Tidak Valid",
 			Response: err.Error(),
 		})
 		return
 	}
-
-	// Ambil semua data order
-	data, err := atdb.GetAllDoc[[]model.Order](config.Mongoconn, "orders", bson.M{})
+	filter, err := orderListFilter(params)
 	if err != nil {
-		at.WriteJSON(respw, http.StatusNotFound, model.Response{
-			Status:   "Error: Data order tidak ditemukan",
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Parameter Tidak Valid",
 			Response: err.Error(),
 		})
 		return
 	}
+	atdb.MatchText(filter, params.Get("q"), "orderNumber", "user_info.name", "user_info.whatsapp")
+	switch params.Get("scheduled") {
+	case "":
+		filter["queue_at"] = bson.M{"$not": bson.M{"$gt": time.Now()}}
+	case "upcoming":
+		filter["queue_at"] = bson.M{"$gt": time.Now()}
+	case "all":
+	default:
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Parameter Tidak Valid",
+			Response: "scheduled harus upcoming atau all",
+		})
+		return
+	}
 
-	if len(data) == 0 {
-		at.WriteJSON(respw, http.StatusNotFound, model.Response{
-			Status: "Error: Data order kosong",
+	data, page, err := atdb.GetPagedDocs[model.Order](config.Mongoconn, "orders", filter, q)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Data order tidak ditemukan",
+			Response: err.Error(),
 		})
 		return
 	}
 
 	 // Deklarasi variabel orders
-	 var orders []map[string]interface{}
+	 orders := make([]map[string]interface{}, 0, len(data))
 
 	 for _, order := range data {
 		 orderDateInID, err := FormatToIndonesianTime(order.OrderDate)
@@ -270,8 +270,12 @@ func GetAllOrder(respw http.ResponseWriter, req *http.Request) {
 				"note":     order.UserInfo.Note,
 			},
 			"orders":          order.Orders,
-			"total":           formatrupiah(order.Total),
+			"total":           rupiah.Format(order.Total),
 			"payment_method":  order.PaymentMethod,
+			"table_number":    order.TableNumber,
+			"pickup_at":       formatPickupTime(order.PickupAt),
+			"type":            order.Type,
+			"delivery":        order.Delivery,
 			"status":          order.Status,
 			"created_by":      order.CreatedBy,
 			"created_by_role": order.CreatedByRole,
@@ -280,29 +284,20 @@ func GetAllOrder(respw http.ResponseWriter, req *http.Request) {
 
 	// Kirim respons dengan data yang sudah diformat
 	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
-		"status":  "success",
-		"message": "Data order berhasil diambil",
-		"data":    orders,
+		"status":     "success",
+		"message":    "Data order berhasil diambil",
+		"data":       orders,
+		"pagination": page,
 	})
 }
 
 
 // GetOrderByID - Ambil Order Berdasarkan ID
 func GetOrderByID(respw http.ResponseWriter, req *http.Request) {
-	// Dekode token WhatsAuth untuk validasi
-	_, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		at.WriteJSON(respw, http.StatusForbidden, model.Response{
-			Status:   "Error: Token Tidak Valid",
-			Location: "Decode Token Error",
-			Response: err.Error(),
-		})
-		return
-	}
+	user, _ := rbac.CurrentUser(req)
 
 	// Ambil ID dari URL
-	pathParts := strings.Split(req.URL.Path, "/")
-	orderID := pathParts[len(pathParts)-1]
+	orderID := router.Param(req, "id")
 	if orderID == "" {
 		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
 			Status: "Error: ID Order tidak ditemukan di URL",
@@ -329,6 +324,14 @@ func GetOrderByID(respw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Pelanggan hanya boleh melihat pesanannya sendiri
+	if !CanViewOrder(user, order) {
+		at.WriteJSON(respw, http.StatusForbidden, model.Response{
+			Status: "Error: Akses Ditolak",
+		})
+		return
+	}
+
 	// Format tanggal dan waktu menjadi format Indonesia
 	orderDateInID, err := FormatToIndonesianTime(order.OrderDate)
 	if err != nil {
@@ -355,8 +358,12 @@ func GetOrderByID(respw http.ResponseWriter, req *http.Request) {
 				"note":     order.UserInfo.Note,
 			},
 			"orders":          order.Orders,
-			"total":           formatrupiah(order.Total),
+			"total":           rupiah.Format(order.Total),
 			"payment_method":  order.PaymentMethod,
+			"table_number":    order.TableNumber,
+			"pickup_at":       formatPickupTime(order.PickupAt),
+			"type":            order.Type,
+			"delivery":        order.Delivery,
 			"status":          order.Status,
 			"created_by":      order.CreatedBy,
 			"created_by_role": order.CreatedByRole,
@@ -367,33 +374,71 @@ func GetOrderByID(respw http.ResponseWriter, req *http.Request) {
 	at.WriteJSON(respw, http.StatusOK, response)
 }
 
-func GetOrderByUserID(respw http.ResponseWriter, req *http.Request) {
-    // 1. Decode token untuk mendapatkan phonenumber
-    payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-    if err != nil {
-        var respn model.Response
-        respn.Status = "Error : Token Tidak Valid"
-        respn.Info = config.PublicKeyWhatsAuth
-        respn.Location = "Decode Token Error: " + at.GetLoginFromHeader(req)
-        respn.Response = err.Error()
-        at.WriteJSON(respw, http.StatusForbidden, respn)
-        return
-    }
+// GetOrderByNumber - Ambil Order Berdasarkan Nomor Order (LGC...), digit pengecek divalidasi lebih dulu
+func GetOrderByNumber(respw http.ResponseWriter, req *http.Request) {
+	user, _ := rbac.CurrentUser(req)
 
-    // 2. Cari user di database berdasarkan phonenumber dari token
-    docuser, err := atdb.GetOneDoc[model.Userdomyikado](config.Mongoconn, "user", primitive.M{"phonenumber": payload.Id})
+	orderNumber := strings.ToUpper(router.Param(req, "ordernumber"))
+	if !pesanan.IsValidOrderNumber(orderNumber) {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Nomor Order Tidak Valid",
+			Response: "Digit pengecek tidak cocok, kemungkinan nomor order salah ketik",
+		})
+		return
+	}
+
+	order, err := atdb.GetOneDoc[model.Order](config.Mongoconn, "orders", bson.M{"orderNumber": orderNumber})
 	if err != nil {
-		docuser.PhoneNumber = payload.Id
-		docuser.Name = payload.Alias
-		at.WriteJSON(respw, http.StatusNotFound, docuser)
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Order tidak ditemukan",
+			Response: err.Error(),
+		})
 		return
 	}
 
-    // 3. Ambil _id user dari dokumen user yang ditemukan
-    userID := docuser.ID
+	// Pelanggan hanya boleh melihat pesanannya sendiri
+	if !CanViewOrder(user, order) {
+		at.WriteJSON(respw, http.StatusForbidden, model.Response{
+			Status: "Error: Akses Ditolak",
+		})
+		return
+	}
 
-    // 4. Cari orders yang terkait dengan user_id di collection orders
-    orders, err := atdb.GetManyDocs[model.Order](config.Mongoconn, "orders", primitive.M{"user_id": userID})
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Order ditemukan",
+		"data":    order,
+	})
+}
+
+// GetOrderByUserID - Ambil pesanan milik user yang login per halaman, terbaru lebih dulu.
+// Query sama seperti GetAllOrder kecuali pencarian teks.
+func GetOrderByUserID(respw http.ResponseWriter, req *http.Request) {
+    // 1. Ambil user yang login dari context
+    docuser, _ := rbac.CurrentUser(req)
+
+    // 2. Baca parameter halaman dan filter
+    params := req.URL.Query()
+    q, err := atdb.ParsePageQuery(params, "orderDate", true, "orderDate", "total")
+    if err != nil {
+        at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+            Status:   "Error: Parameter Tidak Valid",
+            Response: err.Error(),
+        })
+        return
+    }
+    filter, err := orderListFilter(params)
+    if err != nil {
+        at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+            Status:   "Error: Parameter Tidak Valid",
+            Response: err.Error(),
+        })
+        return
+    }
+    filter["user_id"] = docuser.ID
+
+    // 3. Cari orders yang terkait dengan user_id di collection orders
+    orders, page, err := atdb.GetPagedDocs[model.Order](config.Mongoconn, "orders", filter, q)
     if err != nil {
         var respn model.Response
         respn.Status = "Error: Tidak dapat mengambil data pesanan"
@@ -402,35 +447,21 @@ func GetOrderByUserID(respw http.ResponseWriter, req *http.Request) {
         return
     }
 
-    // 5. Kembalikan data pesanan ke client
-    at.WriteJSON(respw, http.StatusOK, orders)
+    // 4. Kembalikan data pesanan ke client
+    at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+        "status":     "success",
+        "message":    "Data pesanan berhasil diambil",
+        "data":       orders,
+        "pagination": page,
+    })
 }
 
-
-
-
 func UpdateOrder(respw http.ResponseWriter, req *http.Request) {
-	// Decode token untuk mendapatkan payload pengguna
-	payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-	if err != nil {
-		at.WriteJSON(respw, http.StatusForbidden, map[string]string{"error": "Token tidak valid"})
-		return
-	}
-
-	// Ambil user dari database menggunakan phonenumber dari payload
-	var user model.Userdomyikado
-	filter := bson.M{"phonenumber": payload.Id}
-	user, err = atdb.GetOneDoc[model.Userdomyikado](config.Mongoconn, "user", filter)
-	if err != nil {
-		at.WriteJSON(respw, http.StatusNotFound, map[string]string{
-			"error": "Data pengguna tidak ditemukan",
-		})
-		return
-	}
+	// User yang login dipakai untuk validasi alur status dan dicatat sebagai UpdatedBy
+	user, _ := rbac.CurrentUser(req)
 
 	// Ambil ID order dari URL
-	pathParts := strings.Split(req.URL.Path, "/")
-	orderID := pathParts[len(pathParts)-1]
+	orderID := router.Param(req, "id")
 	if orderID == "" {
 		at.WriteJSON(respw, http.StatusBadRequest, map[string]string{"error": "ID Order tidak ditemukan"})
 		return
@@ -457,48 +488,27 @@ func UpdateOrder(respw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Validasi dan update status
-	if status, exists := requestBody["status"]; exists {
-		if statusStr, ok := status.(string); ok {
-			switch statusStr {
-			case "diproses", "selesai":
-				currentOrder.Status = statusStr
-			case "dibatalkan":
-				if currentOrder.Status != "terkirim" {
-					at.WriteJSON(respw, http.StatusBadRequest, map[string]string{
-						"error": "Pesanan tidak dapat dibatalkan karena status saat ini adalah '" + currentOrder.Status + "'",
-					})
-					return
-				}
-				currentOrder.Status = "dibatalkan"
-			default:
-				at.WriteJSON(respw, http.StatusBadRequest, map[string]string{"error": "Status tidak valid"})
-				return
-			}
-		}
-	} else {
+	// Validasi status tujuan sesuai alur pesanan dan role pengguna
+	statusStr, _ := requestBody["status"].(string)
+	if statusStr == "" {
 		at.WriteJSON(respw, http.StatusBadRequest, map[string]string{"error": "Status harus diisi"})
 		return
 	}
-
-	// Tambahkan informasi siapa yang mengupdate
-	currentOrder.UpdatedBy = user.Name       // Menggunakan nama dari struct Userdomyikado
-	currentOrder.UpdatedByRole = user.Role   // Menggunakan role dari struct Userdomyikado
-
-	// Gunakan waktu Indonesia untuk UpdatedAt
-	location, err := time.LoadLocation("Asia/Jakarta")
-	if err != nil {
-		at.WriteJSON(respw, http.StatusInternalServerError, map[string]string{"error": "Gagal memuat zona waktu Indonesia"})
+	reason, _ := requestBody["reason"].(string)
+	if statusStr == model.OrderStatusDitolak && strings.TrimSpace(reason) == "" {
+		at.WriteJSON(respw, http.StatusBadRequest, map[string]string{"error": "Alasan penolakan harus diisi"})
 		return
 	}
-	currentOrder.UpdatedAt = time.Now().In(location)
 
-	// Update data order di database
-	_, err = atdb.ReplaceOneDoc(config.Mongoconn, "orders", bson.M{"_id": objectID}, currentOrder)
+	oldOrder := currentOrder
+	currentOrder, err = TransitionOrder(currentOrder, statusStr, user, reason)
 	if err != nil {
-		at.WriteJSON(respw, http.StatusInternalServerError, map[string]string{"error": "Gagal mengupdate order"})
+		at.WriteJSON(respw, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
+	audit.Record(config.Mongoconn, req, model.AuditUpdate, "orders", objectID, oldOrder, currentOrder)
+
+	notif.SendOrderStatusAsync(currentOrder, config.Mongoconn)
 
 	// Respons sukses
 	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
@@ -511,26 +521,17 @@ func UpdateOrder(respw http.ResponseWriter, req *http.Request) {
 			"updated_by":      currentOrder.UpdatedBy,
 			"updated_by_role": currentOrder.UpdatedByRole,
 			"orders":          currentOrder.Orders,
+			"status_history":  currentOrder.StatusHistory,
 		},
 	})
 }
 
 func DeleteOrder(respw http.ResponseWriter, req *http.Request) {
-    // Ambil token dari header dan decode menggunakan public key
-    payload, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
-    if err != nil {
-        var respn model.Response
-        respn.Status = "Error: Token Tidak Valid"
-        respn.Info = at.GetSecretFromHeader(req)
-        respn.Location = "Decode Token Error"
-        respn.Response = err.Error()
-        at.WriteJSON(respw, http.StatusForbidden, respn)
-        return
-    }
+    // User sudah divalidasi dan disimpan ke context oleh PermissionMiddleware
+    user, _ := rbac.CurrentUser(req)
 
     // Ambil ID order dari URL
-    pathParts := strings.Split(req.URL.Path, "/")
-    orderID := pathParts[len(pathParts)-1] // Ambil bagian terakhir dari URL
+    orderID := router.Param(req, "id")
     if orderID == "" {
         var respn model.Response
         respn.Status = "Error: ID Order tidak ditemukan di URL"
@@ -547,8 +548,17 @@ func DeleteOrder(respw http.ResponseWriter, req *http.Request) {
         return
     }
 
-    // Hapus data order berdasarkan ID
+    // Simpan data order sebelum dihapus untuk audit log
     filter := bson.M{"_id": objectID}
+    oldOrder, err := atdb.GetOneDoc[model.Order](config.Mongoconn, "orders", filter)
+    if err != nil {
+        var respn model.Response
+        respn.Status = "Error: Order tidak ditemukan"
+        at.WriteJSON(respw, http.StatusNotFound, respn)
+        return
+    }
+
+    // Hapus data order berdasarkan ID
     deleteResult, err := atdb.DeleteOneDoc(config.Mongoconn, "orders", filter)
     if err != nil {
         var respn model.Response
@@ -565,11 +575,13 @@ func DeleteOrder(respw http.ResponseWriter, req *http.Request) {
         return
     }
 
+    audit.Record(config.Mongoconn, req, model.AuditDelete, "orders", objectID, oldOrder, nil)
+
     // Berhasil menghapus order
     response := map[string]interface{}{
         "status":  "success",
         "message": "Order berhasil dihapus",
-        "user":    payload.Alias,
+        "user":    user.Name,
         "data":    deleteResult,
     }
     at.WriteJSON(respw, http.StatusOK, response)
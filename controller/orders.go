@@ -2,66 +2,56 @@ package controller
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
-	"strconv"
-	"strings"
 
 	"github.com/gocroot/config"
 	"github.com/gocroot/helper/at"
-	"github.com/gocroot/helper/atapi"
-	"github.com/gocroot/helper/atdb"
 	"github.com/gocroot/helper/jualin"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/router"
 	"github.com/gocroot/model"
 )
 
-// Fungsi untuk menangani request order
-func HandleOrder(w http.ResponseWriter, r *http.Request) {
-	namalapak := at.GetParam(r)
+// HandleOrder - Order dari halaman lapak dengan format lama (jualin), tanpa login: /data/order/:namalapak
+// Item dicocokkan ke koleksi menu berdasarkan nama, lalu diproses sama seperti CreateOrder
+// sehingga tersimpan di koleksi orders dengan nomor antrean, status dan notifikasi yang sama.
+func HandleOrder(respw http.ResponseWriter, req *http.Request) {
+	namalapak := router.Param(req, "namalapak")
 	var orderRequest jualin.PaymentRequest
-
-	// Decode JSON request ke struct
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&orderRequest); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
-	}
-	_, err := atdb.InsertOneDoc(config.Mongoconn, "order", orderRequest)
-	if err != nil {
-		http.Error(w, "Insert Database Gagal", http.StatusBadRequest)
+	if err := json.NewDecoder(req.Body).Decode(&orderRequest); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
 		return
 	}
 
-	//kirim pesan ke tenant
-	message := "*Pesanan Masuk " + namalapak + "*\n" + orderRequest.User.Name + "\n" + orderRequest.User.Whatsapp + "\n" + orderRequest.User.Address + "\n" + createOrderMessage(orderRequest.Orders) + "\nTotal: " + strconv.Itoa(orderRequest.Total) + "\nPembayaran: " + orderRequest.PaymentMethod
-	newmsg := model.SendText{
-		To:       "6285183104981",
-		IsGroup:  false,
-		Messages: message,
-	}
-	_, _, err = atapi.PostStructWithToken[model.Response]("token", config.WAAPIToken, newmsg, config.WAAPIMessage)
-	if err != nil {
-		http.Error(w, "Gagal Mengirim pesan", http.StatusBadRequest)
+	items, err := jualin.ToOrderItems(config.Mongoconn, orderRequest.Orders)
+	if errors.Is(err, pesanan.ErrOrderItemInvalid) {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Item Pesanan Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	} else if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil data menu",
+			Response: err.Error(),
+		})
 		return
 	}
-	// Cetak data order ke terminal (bisa diganti dengan logic lain, misal menyimpan ke database)
-	fmt.Printf("Received Order: %+v\n", orderRequest)
 
-	// Kirim response kembali ke client
-	w.Header().Set("Content-Type", "application/json")
-	response := map[string]string{"status": "success", "message": "Order received"}
-	json.NewEncoder(w).Encode(response)
-}
-
-// Fungsi untuk membuat pesan dari orders
-func createOrderMessage(orders []jualin.Orders) string {
-	var orderStrings []string
-
-	for _, order := range orders {
-		orderString := fmt.Sprintf("%s x%d - Rp %d", order.Name, order.Quantity, order.Price)
-		orderStrings = append(orderStrings, orderString)
+	order := model.Order{
+		UserInfo: model.UserInfo{
+			Name:     orderRequest.User.Name,
+			Whatsapp: orderRequest.User.Whatsapp,
+			Note:     jualin.Note(namalapak, orderRequest.User),
+		},
+		Orders:        items,
+		Total:         float64(orderRequest.Total),
+		PaymentMethod: jualin.PaymentMethod(orderRequest.PaymentMethod),
 	}
-
-	// Gabungkan semua orders menjadi satu string dengan new line sebagai separator
-	return strings.Join(orderStrings, "\n")
+	// Pemesan lapak tidak login, pembuat order dicatat dari nama pelanggan
+	submitOrder(respw, req, order, model.Userdomyikado{Name: orderRequest.User.Name, Role: "jualin"})
 }
@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/inventory"
+	"github.com/gocroot/helper/loyalty"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/pickup"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/voucher"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IsOrderStaff - Role dengan permission order:advance (bawaan: kasir dan admin) dianggap staf toko
+func IsOrderStaff(role string) bool {
+	return rbac.Allowed(config.Mongoconn, role, rbac.OrderAdvance)
+}
+
+// CanViewOrder - Staf dengan permission order:read boleh melihat semua pesanan, pelanggan hanya pesanannya sendiri
+func CanViewOrder(user model.Userdomyikado, order model.Order) bool {
+	return order.UserID == user.ID || rbac.Allowed(config.Mongoconn, user.Role, rbac.OrderRead)
+}
+
+// CheckOrderTransition - Mengecek apakah perpindahan status boleh dilakukan oleh user tersebut
+func CheckOrderTransition(order model.Order, to string, user model.Userdomyikado) error {
+	var actors []string
+	if IsOrderStaff(user.Role) {
+		actors = append(actors, pesanan.ActorStaff)
+	}
+	if order.UserID == user.ID {
+		actors = append(actors, pesanan.ActorOwner)
+	}
+	if rbac.Allowed(config.Mongoconn, user.Role, rbac.KitchenBump) {
+		actors = append(actors, pesanan.ActorKitchen)
+	}
+	// Pesanan yang dibayar lewat payment provider harus lunas sebelum diproses
+	return pesanan.CheckTransition(order, to, paymentMethods[order.PaymentMethod], actors...)
+}
+
+// TransitionOrder - Memindahkan status pesanan dan menambahkan riwayatnya.
+// Update hanya terjadi jika status di database masih sama, sehingga dua kasir tidak saling menimpa.
+func TransitionOrder(order model.Order, to string, user model.Userdomyikado, reason string) (updated model.Order, err error) {
+	if err = CheckOrderTransition(order, to, user); err != nil {
+		return
+	}
+	location, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return
+	}
+	now := time.Now().In(location)
+	entry := model.OrderStatusHistory{
+		From:   order.Status,
+		To:     to,
+		By:     user.Name,
+		Role:   user.Role,
+		At:     now,
+		Reason: reason,
+	}
+	// Stok bahan dikurangi saat pesanan mulai diproses, jika stok kurang pesanan tidak bisa diproses
+	stockDeducted := order.StockDeducted
+	if to == model.OrderStatusDiproses {
+		if err = inventory.Consume(config.Mongoconn, order, user.Name); err != nil {
+			return
+		}
+		stockDeducted = true
+	}
+	filter := bson.M{"_id": order.ID, "status": order.Status}
+	update := bson.M{
+		"$set": bson.M{
+			"status":          to,
+			"updated_by":      user.Name,
+			"updated_by_role": user.Role,
+			"updated_at":      now,
+			"stock_deducted":  stockDeducted,
+		},
+		"$push": bson.M{"status_history": entry},
+	}
+	result, err := atdb.UpdateDoc(config.Mongoconn, "orders", filter, update)
+	if err == nil && result.MatchedCount == 0 {
+		err = errors.New("status pesanan sudah diubah oleh pengguna lain, silakan muat ulang")
+	}
+	if err != nil {
+		// Kembalikan stok yang terlanjur dikurangi karena status gagal diubah
+		if to == model.OrderStatusDiproses {
+			if errRestore := inventory.Restore(config.Mongoconn, order, user.Name); errRestore != nil {
+				log.Println("Gagal mengembalikan stok pesanan " + order.OrderNumber + ": " + errRestore.Error())
+			}
+		}
+		return
+	}
+
+	// Kuota voucher, poin yang ditukar dan kuota slot waktu ambil dikembalikan jika pesanan dibatalkan atau ditolak
+	if to == model.OrderStatusDibatalkan || to == model.OrderStatusDitolak {
+		if errSlot := pickup.Release(config.Mongoconn, order); errSlot != nil {
+			log.Println("Gagal mengembalikan kuota slot pesanan " + order.OrderNumber + ": " + errSlot.Error())
+		}
+		if order.Discount != nil {
+			if errVoucher := voucher.Cancel(config.Mongoconn, order); errVoucher != nil {
+				log.Println("Gagal mengembalikan kuota voucher pesanan " + order.OrderNumber + ": " + errVoucher.Error())
+			}
+		}
+		if order.Points != nil {
+			if errPoints := loyalty.Reverse(config.Mongoconn, order); errPoints != nil {
+				log.Println("Gagal mengembalikan poin pesanan " + order.OrderNumber + ": " + errPoints.Error())
+			}
+		}
+	}
+
+	// Pelanggan mendapat poin loyalitas saat pesanan selesai
+	if to == model.OrderStatusSelesai {
+		if errPoints := loyalty.Earn(config.Mongoconn, order); errPoints != nil {
+			log.Println("Gagal menambahkan poin pesanan " + order.OrderNumber + ": " + errPoints.Error())
+		}
+	}
+
+	// Pesanan yang dibatalkan setelah diproses mengembalikan stok bahannya
+	if to == model.OrderStatusDibatalkan && order.StockDeducted {
+		if err = inventory.Restore(config.Mongoconn, order, user.Name); err != nil {
+			log.Println("Gagal mengembalikan stok pesanan " + order.OrderNumber + ": " + err.Error())
+		} else {
+			atdb.UpdateDoc(config.Mongoconn, "orders", bson.M{"_id": order.ID}, bson.M{"$set": bson.M{"stock_deducted": false}})
+			stockDeducted = false
+		}
+		err = nil
+	}
+
+	updated = order
+	updated.Status = to
+	updated.UpdatedBy = user.Name
+	updated.UpdatedByRole = user.Role
+	updated.UpdatedAt = now
+	updated.StatusHistory = append(updated.StatusHistory, entry)
+	updated.StockDeducted = stockDeducted
+	return
+}
+
+// GetOrderHistory - Ambil riwayat status sebuah pesanan
+func GetOrderHistory(respw http.ResponseWriter, req *http.Request) {
+	user, _ := rbac.CurrentUser(req)
+
+	// Ambil ID order dari URL: /data/order/:id/history
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Order tidak valid",
+		})
+		return
+	}
+
+	order, err := atdb.GetOneDoc[model.Order](config.Mongoconn, "orders", bson.M{"_id": objectID})
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Order tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	// Pelanggan hanya boleh melihat riwayat pesanannya sendiri
+	if !CanViewOrder(user, order) {
+		at.WriteJSON(respw, http.StatusForbidden, model.Response{
+			Status: "Error: Akses Ditolak",
+		})
+		return
+	}
+
+	var history []map[string]interface{}
+	for _, entry := range order.StatusHistory {
+		changedAt, err := FormatToIndonesianTime(entry.At)
+		if err != nil {
+			changedAt = entry.At.String()
+		}
+		history = append(history, map[string]interface{}{
+			"from":   entry.From,
+			"to":     entry.To,
+			"by":     entry.By,
+			"role":   entry.Role,
+			"at":     changedAt,
+			"reason": entry.Reason,
+		})
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Riwayat status order berhasil diambil",
+		"data": map[string]interface{}{
+			"id":             order.ID.Hex(),
+			"orderNumber":    order.OrderNumber,
+			"current_status": order.Status,
+			"history":        history,
+		},
+	})
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/orderstream"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// interval polling jika change stream tidak tersedia, interval komentar keep-alive SSE,
+// dan interval pengecekan pesanan terjadwal yang mulai masuk antrean aktif
+var (
+	orderStreamPollInterval = 3 * time.Second
+	orderStreamKeepAlive    = 15 * time.Second
+	orderStreamDueInterval  = 30 * time.Second
+)
+
+type orderChangeEvent struct {
+	OperationType string              `bson:"operationType"`
+	ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+	FullDocument  model.Order         `bson:"fullDocument"`
+}
+
+// StreamOrders - Server-Sent Events untuk dashboard kasir, mengirim order baru dan perubahan status.
+// ID event berformat <unix milidetik>:<resume token hex>, dikirim ulang browser lewat header Last-Event-ID
+// sehingga stream dapat dilanjutkan setelah reconnect. Pesanan terjadwal yang belum masuk antrean aktif dikirim
+// sebagai order_scheduled, lalu sebagai order_due saat queue_at tercapai.
+// Login lewat header Login, atau cookie login / query ?login= untuk EventSource (StreamLoginMiddleware).
+func StreamOrders(respw http.ResponseWriter, req *http.Request) {
+	flusher, ok := respw.(http.Flusher)
+	if !ok {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status: "Error: Streaming tidak didukung",
+		})
+		return
+	}
+
+	lastEventID := req.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = req.URL.Query().Get("lastEventId")
+	}
+	since, resumeToken := orderstream.ParseEventID(lastEventID)
+
+	respw.Header().Set("Content-Type", "text/event-stream")
+	respw.Header().Set("Cache-Control", "no-cache")
+	respw.Header().Set("Connection", "keep-alive")
+	respw.WriteHeader(http.StatusOK)
+	fmt.Fprint(respw, "retry: 3000\n\n")
+	flusher.Flush()
+
+	ctx := req.Context()
+	err := streamOrdersFromChangeStream(ctx, respw, flusher, resumeToken, &since)
+	if err != nil && ctx.Err() == nil {
+		log.Println("Change stream orders tidak tersedia, beralih ke polling: " + err.Error())
+		if since.IsZero() {
+			since = time.Now()
+		}
+		streamOrdersFromPolling(ctx, respw, flusher, since)
+	}
+}
+
+// since diperbarui setiap ada event, supaya polling bisa melanjutkan jika change stream terputus
+func streamOrdersFromChangeStream(ctx context.Context, respw http.ResponseWriter, flusher http.Flusher, resumeToken bson.Raw, since *time.Time) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": []string{"insert", "update", "replace"}}}}},
+	}
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != nil {
+		opts.SetResumeAfter(resumeToken)
+	} else if !since.IsZero() {
+		opts.SetStartAtOperationTime(&primitive.Timestamp{T: uint32(since.Unix()) + 1})
+	}
+	cs, err := config.Mongoconn.Collection("orders").Watch(ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer cs.Close(context.Background())
+
+	keepAlive := time.NewTicker(orderStreamKeepAlive)
+	defer keepAlive.Stop()
+	due := newDueOrders(*since)
+	defer due.ticker.Stop()
+	for {
+		if cs.TryNext(ctx) {
+			var event orderChangeEvent
+			if err := cs.Decode(&event); err != nil {
+				return err
+			}
+			eventType := "order_updated"
+			if event.OperationType == "insert" {
+				eventType = "order_created"
+			}
+			*since = time.Unix(int64(event.ClusterTime.T), 0)
+			id := orderstream.EventID(*since, cs.ResumeToken())
+			writeOrderEvent(respw, flusher, id, scheduledEventType(eventType, event.FullDocument), event.FullDocument)
+			continue
+		}
+		if err := cs.Err(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-keepAlive.C:
+			fmt.Fprint(respw, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-due.ticker.C:
+			due.write(ctx, respw, flusher)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func streamOrdersFromPolling(ctx context.Context, respw http.ResponseWriter, flusher http.Flusher, since time.Time) {
+	poll := time.NewTicker(orderStreamPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(orderStreamKeepAlive)
+	defer keepAlive.Stop()
+	due := newDueOrders(since)
+	defer due.ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(respw, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-due.ticker.C:
+			due.write(ctx, respw, flusher)
+		case <-poll.C:
+			filter := bson.M{"$or": []bson.M{
+				{"orderDate": bson.M{"$gt": since}},
+				{"updated_at": bson.M{"$gt": since}},
+			}}
+			opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}, {Key: "orderDate", Value: 1}})
+			cur, err := config.Mongoconn.Collection("orders").Find(ctx, filter, opts)
+			if err != nil {
+				log.Println("Polling orders gagal: " + err.Error())
+				continue
+			}
+			var orders []model.Order
+			err = cur.All(ctx, &orders)
+			if err != nil {
+				log.Println("Polling orders gagal: " + err.Error())
+				continue
+			}
+			prev := since
+			for _, order := range orders {
+				eventType, changedAt := "order_updated", order.UpdatedAt
+				if order.UpdatedAt.IsZero() || !order.UpdatedAt.After(prev) {
+					eventType, changedAt = "order_created", order.OrderDate
+				}
+				if changedAt.After(since) {
+					since = changedAt
+				}
+				writeOrderEvent(respw, flusher, orderstream.EventID(changedAt, nil), scheduledEventType(eventType, order), order)
+			}
+		}
+	}
+}
+
+// writeOrderEvent mengirim satu event SSE, id kosong berarti event tanpa id sehingga Last-Event-ID browser tidak berubah
+func writeOrderEvent(respw http.ResponseWriter, flusher http.Flusher, id, eventType string, order model.Order) {
+	if id != "" {
+		fmt.Fprintf(respw, "id: %s\n", id)
+	}
+	fmt.Fprintf(respw, "event: %s\ndata: %s\n\n", eventType, at.Jsonstr(order))
+	flusher.Flush()
+}
+
+// scheduledEventType - Pesanan terjadwal yang belum masuk antrean aktif dikirim sebagai order_scheduled
+func scheduledEventType(eventType string, order model.Order) string {
+	if eventType == "order_created" && order.QueueAt.After(time.Now()) {
+		return "order_scheduled"
+	}
+	return eventType
+}
+
+// dueOrders mengirim event order_due untuk pesanan terjadwal yang queue_at-nya terlewati sejak pengecekan terakhir
+type dueOrders struct {
+	ticker *time.Ticker
+	since  time.Time
+}
+
+func newDueOrders(since time.Time) *dueOrders {
+	if since.IsZero() {
+		since = time.Now()
+	}
+	return &dueOrders{ticker: time.NewTicker(orderStreamDueInterval), since: since}
+}
+
+// write dipanggil dari loop stream yang sama supaya tidak ada penulisan bersamaan ke ResponseWriter.
+// Event order_due tidak membawa id supaya posisi resume change stream tidak bergeser.
+func (d *dueOrders) write(ctx context.Context, respw http.ResponseWriter, flusher http.Flusher) {
+	now := time.Now()
+	filter := bson.M{
+		"queue_at": bson.M{"$gt": d.since, "$lte": now},
+		"status":   bson.M{"$nin": []string{model.OrderStatusDibatalkan, model.OrderStatusDitolak}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "queue_at", Value: 1}})
+	cur, err := config.Mongoconn.Collection("orders").Find(ctx, filter, opts)
+	if err != nil {
+		log.Println("Cek pesanan terjadwal gagal: " + err.Error())
+		return
+	}
+	var orders []model.Order
+	if err = cur.All(ctx, &orders); err != nil {
+		log.Println("Cek pesanan terjadwal gagal: " + err.Error())
+		return
+	}
+	d.since = now
+	for _, order := range orders {
+		writeOrderEvent(respw, flusher, "", "order_due", order)
+	}
+}
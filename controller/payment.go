@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/payment"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/rupiah"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// metode pembayaran yang diterima, true jika metode tersebut memakai payment provider
+var paymentMethods = map[string]bool{"Cash": false, "QRIS": true}
+
+// ActivePaymentProvider - Mengambil payment provider sesuai config.PaymentProvider.
+// Mock hanya aktif jika diset secara eksplisit, supaya tidak terbuka di production.
+func ActivePaymentProvider() (payment.Provider, error) {
+	switch config.PaymentProvider {
+	case "qris":
+		if config.QRISStaticPayload == "" {
+			return nil, errors.New("payload QRIS merchant belum diatur")
+		}
+		return payment.QRISProvider{
+			MerchantPayload: config.QRISStaticPayload,
+			Secret:          config.PaymentCallbackSecret,
+		}, nil
+	case "mock":
+		return payment.MockProvider{Secret: config.PaymentCallbackSecret}, nil
+	}
+	return nil, errors.New("payment provider belum dikonfigurasi")
+}
+
+// PaymentCallback - Menerima callback bertanda tangan dari payment provider: /webhook/payment/:provider
+func PaymentCallback(respw http.ResponseWriter, req *http.Request) {
+	provider, err := ActivePaymentProvider()
+	if err != nil || provider.Name() != router.Param(req, "provider") {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Payment Provider Tidak Dikenal",
+			Response: router.Param(req, "provider"),
+		})
+		return
+	}
+
+	cb, err := provider.ParseCallback(req)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusUnauthorized, model.Response{
+			Status:   "Error: Callback Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	order, err := atdb.GetOneDoc[model.Order](config.Mongoconn, "orders", bson.M{"payment_info.reference": cb.Reference})
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Order tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	// Hanya tagihan yang masih menunggu yang diubah, callback ganda tidak berpengaruh
+	var after model.Order
+	switch cb.Status {
+	case payment.CallbackPaid:
+		// Nominal dibandingkan per rupiah utuh, float dari JSON callback bisa berbeda di belakang koma
+		if math.Round(cb.Amount) != math.Round(order.PaymentInfo.Amount) {
+			at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+				Status:   "Error: Nominal Pembayaran Tidak Sesuai",
+				Response: rupiah.Format(cb.Amount) + " != " + rupiah.Format(order.PaymentInfo.Amount),
+			})
+			return
+		}
+		after, err = settlePaidOrder(order.ID, isClosedOrder(order.Status))
+	case payment.CallbackExpired:
+		filter := bson.M{"_id": order.ID, "payment_status": model.PaymentStatusMenunggu}
+		update := bson.M{"$set": bson.M{"payment_status": model.PaymentStatusKedaluwarsa}}
+		after, err = atdb.FindOneAndUpdateDoc[model.Order](config.Mongoconn, "orders", filter, update)
+	default:
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Status Callback Tidak Dikenal",
+			Response: cb.Status,
+		})
+		return
+	}
+	updated := err == nil
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengupdate pembayaran",
+			Response: err.Error(),
+		})
+		return
+	}
+	if updated {
+		// callback tidak membawa user, pelaku dicatat sebagai payment provider
+		actor := audit.ActorOf(req)
+		actor.Name, actor.Role = provider.Name(), "payment"
+		audit.RecordBy(config.Mongoconn, actor, model.AuditUpdate, "orders", order.ID, order, after)
+	}
+	refundNeeded := updated && after.PaymentStatus == model.PaymentStatusPerluRefund
+	if refundNeeded {
+		log.Println("Pembayaran order " + order.OrderNumber + " masuk setelah pesanan " + after.Status + ", perlu refund " + rupiah.Format(cb.Amount))
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":        "success",
+		"orderNumber":   order.OrderNumber,
+		"updated":       updated,
+		"refund_needed": refundNeeded,
+	})
+}
+
+// isClosedOrder - Pesanan yang sudah dibatalkan atau ditolak dan tidak akan dibuat
+func isClosedOrder(status string) bool {
+	return status == model.OrderStatusDibatalkan || status == model.OrderStatusDitolak
+}
+
+// settlePaidOrder - Mencatat pembayaran yang masuk: lunas untuk pesanan aktif, perlu refund untuk pesanan yang sudah
+// dibatalkan/ditolak supaya tidak tercatat lunas. Pembayaran yang masuk sesaat setelah tagihan ditandai kedaluwarsa
+// tetap dicatat karena uang pelanggan sudah terpotong. Status pesanan ikut dicek di filter; jika berubah setelah
+// dibaca, dicoba sekali lagi dengan kondisi sebaliknya. mongo.ErrNoDocuments jika tagihan sudah tidak menunggu.
+func settlePaidOrder(orderID primitive.ObjectID, closed bool) (after model.Order, err error) {
+	closedStatuses := bson.A{model.OrderStatusDibatalkan, model.OrderStatusDitolak}
+	for attempt := 0; attempt < 2; attempt++ {
+		status, paymentStatus := bson.M{"$nin": closedStatuses}, model.PaymentStatusLunas
+		if closed {
+			status, paymentStatus = bson.M{"$in": closedStatuses}, model.PaymentStatusPerluRefund
+		}
+		filter := bson.M{
+			"_id":            orderID,
+			"status":         status,
+			"payment_status": bson.M{"$in": bson.A{model.PaymentStatusMenunggu, model.PaymentStatusKedaluwarsa}},
+		}
+		update := bson.M{"$set": bson.M{"payment_status": paymentStatus, "payment_info.paid_at": time.Now()}}
+		after, err = atdb.FindOneAndUpdateDoc[model.Order](config.Mongoconn, "orders", filter, update)
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		closed = !closed
+	}
+	return
+}
+
+// GetOrderPayment - Cek status pembayaran sebuah order: /data/order/:id/payment
+func GetOrderPayment(respw http.ResponseWriter, req *http.Request) {
+	user, _ := rbac.CurrentUser(req)
+
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Order tidak valid",
+		})
+		return
+	}
+
+	order, err := atdb.GetOneDoc[model.Order](config.Mongoconn, "orders", bson.M{"_id": objectID})
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Order tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	// Pelanggan hanya boleh melihat pesanannya sendiri
+	if !CanViewOrder(user, order) {
+		at.WriteJSON(respw, http.StatusForbidden, model.Response{
+			Status: "Error: Akses Ditolak",
+		})
+		return
+	}
+
+	// Tandai kedaluwarsa jika tagihan sudah lewat waktu dan belum ada callback
+	if order.PaymentStatus == model.PaymentStatusMenunggu && order.PaymentInfo != nil && time.Now().After(order.PaymentInfo.ExpiresAt) {
+		filter := bson.M{"_id": order.ID, "payment_status": model.PaymentStatusMenunggu}
+		if _, err := atdb.UpdateDoc(config.Mongoconn, "orders", filter, bson.M{"$set": bson.M{"payment_status": model.PaymentStatusKedaluwarsa}}); err == nil {
+			order.PaymentStatus = model.PaymentStatusKedaluwarsa
+		}
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Status pembayaran berhasil diambil",
+		"data": map[string]interface{}{
+			"id":             order.ID.Hex(),
+			"orderNumber":    order.OrderNumber,
+			"payment_method": order.PaymentMethod,
+			"payment_status": order.PaymentStatus,
+			"payment_info":   order.PaymentInfo,
+		},
+	})
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/pickup"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/model"
+)
+
+// GetPickupSlots - Slot waktu ambil pesanan terjadwal pada satu tanggal beserta sisa kuotanya: /data/pickup/slots?date=2024-10-01.
+// Tanpa date berarti hari ini (Asia/Jakarta).
+func GetPickupSlots(respw http.ResponseWriter, req *http.Request) {
+	location, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal memuat zona waktu Indonesia",
+			Response: err.Error(),
+		})
+		return
+	}
+	now := time.Now().In(location)
+	date := now
+	if value := req.URL.Query().Get("date"); value != "" {
+		if date, err = time.ParseInLocation("2006-01-02", value, location); err != nil {
+			at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+				Status:   "Error: Format tanggal harus YYYY-MM-DD",
+				Response: err.Error(),
+			})
+			return
+		}
+	}
+
+	schedule, err := pickup.GetSchedule(config.Mongoconn)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil jadwal slot",
+			Response: err.Error(),
+		})
+		return
+	}
+	used, err := pickup.Used(config.Mongoconn, date, location)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil kuota slot",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Slot waktu ambil berhasil diambil",
+		"date":    date.Format("2006-01-02"),
+		"data":    pickup.DaySlots(schedule, date, location, now, used),
+	})
+}
+
+// GetPickupSchedule - Pengaturan jam buka, slot dan lead time pesanan terjadwal (admin)
+func GetPickupSchedule(respw http.ResponseWriter, req *http.Request) {
+	schedule, err := pickup.GetSchedule(config.Mongoconn)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil jadwal slot",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Jadwal slot berhasil diambil",
+		"data":    schedule,
+	})
+}
+
+// UpdatePickupSchedule - Simpan pengaturan pesanan terjadwal (admin). Pesanan yang sudah dibuat tetap memakai
+// slot dan waktu masuk antrean yang dihitung saat pesanan dibuat.
+func UpdatePickupSchedule(respw http.ResponseWriter, req *http.Request) {
+	user, _ := rbac.CurrentUser(req)
+
+	var input model.PickupSchedule
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	if err := pickup.Validate(input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Jadwal Slot Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+	before, err := pickup.GetSchedule(config.Mongoconn)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil jadwal slot",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.UpdatedBy = user.Name
+	input.UpdatedAt = time.Now()
+	schedule, err := pickup.SaveSchedule(config.Mongoconn, input)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menyimpan jadwal slot",
+			Response: err.Error(),
+		})
+		return
+	}
+	audit.RecordKey(config.Mongoconn, req, model.AuditUpdate, pickup.Collection, schedule.ID, before, schedule)
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Jadwal slot berhasil disimpan",
+		"data":    schedule,
+	})
+}
@@ -424,6 +424,7 @@ func PutDataProject(respw http.ResponseWriter, req *http.Request) {
 	prj.Owner = existingprj.Owner
 	prj.Members = existingprj.Members
 	prj.CoverBuku = existingprj.CoverBuku
+	prj.CoverBukuKey = existingprj.CoverBukuKey
 	prj.DraftBuku = existingprj.DraftBuku
 	prj.DraftPDFBuku = existingprj.DraftPDFBuku
 	prj.SampulPDFBuku = existingprj.SampulPDFBuku
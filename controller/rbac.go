@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GetRolePermissions - Daftar permission efektif setiap role beserta semua permission yang tersedia: /data/roles
+func GetRolePermissions(respw http.ResponseWriter, req *http.Request) {
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Data permission role berhasil diambil",
+		"data": map[string]interface{}{
+			"roles":       rbac.Roles(config.Mongoconn),
+			"permissions": rbac.All,
+		},
+	})
+}
+
+// UpdateRolePermissions - Mengganti seluruh permission sebuah role: PUT /data/role/:role {"permissions": [...]}
+func UpdateRolePermissions(respw http.ResponseWriter, req *http.Request) {
+	user, _ := rbac.CurrentUser(req)
+	role := strings.TrimSpace(router.Param(req, "role"))
+
+	var input struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	for _, permission := range input.Permissions {
+		if !rbac.Valid(permission) {
+			at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+				Status:   "Error: Permission Tidak Dikenal",
+				Response: permission,
+			})
+			return
+		}
+	}
+	// Cegah admin mengunci dirinya sendiri dari pengaturan permission
+	if role == user.Role && !rbac.Match(input.Permissions, rbac.UserRole) {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Permission Tidak Valid",
+			Response: "Permission " + rbac.UserRole + " tidak boleh dihapus dari role sendiri",
+		})
+		return
+	}
+
+	// role bawaan yang belum pernah diubah belum punya dokumen, audit memakai permission default-nya
+	before, err := atdb.GetOneDoc[model.RolePermission](config.Mongoconn, "role_permission", bson.M{"_id": role})
+	if err != nil {
+		before = model.RolePermission{Role: role, Permissions: rbac.Permissions(config.Mongoconn, role)}
+	}
+
+	doc := model.RolePermission{
+		Role:        role,
+		Permissions: input.Permissions,
+		UpdatedBy:   user.Name,
+		UpdatedAt:   time.Now(),
+	}
+	if doc.Permissions == nil {
+		doc.Permissions = []string{}
+	}
+	_, err = atdb.UpdateOneDoc(config.Mongoconn, "role_permission", bson.M{"_id": role}, bson.M{
+		"permissions": doc.Permissions,
+		"updated_by":  doc.UpdatedBy,
+		"updated_at":  doc.UpdatedAt,
+	})
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menyimpan permission role",
+			Response: err.Error(),
+		})
+		return
+	}
+	rbac.Invalidate()
+	audit.RecordKey(config.Mongoconn, req, model.AuditUpdate, "role_permission", role, before, doc)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Permission role berhasil diupdate",
+		"data":    doc,
+	})
+}
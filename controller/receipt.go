@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/receipt"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/rupiah"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetOrderReceipt - Unduh struk pesanan dalam PDF: /data/order/:id/receipt?layout=a4|80mm|58mm
+func GetOrderReceipt(respw http.ResponseWriter, req *http.Request) {
+	user, _ := rbac.CurrentUser(req)
+
+	// Ambil ID order dari URL: /data/order/:id/receipt
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Order tidak valid",
+		})
+		return
+	}
+
+	order, err := atdb.GetOneDoc[model.Order](config.Mongoconn, "orders", bson.M{"_id": objectID})
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Order tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	// Pelanggan hanya boleh mencetak struk pesanannya sendiri
+	if !CanViewOrder(user, order) {
+		at.WriteJSON(respw, http.StatusForbidden, model.Response{
+			Status: "Error: Akses Ditolak",
+		})
+		return
+	}
+
+	layout := req.URL.Query().Get("layout")
+	if layout == "" {
+		layout = "80mm"
+	}
+	r, err := orderReceipt(order)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menyusun struk",
+			Response: err.Error(),
+		})
+		return
+	}
+	content, err := receipt.Generate(r, layout)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Gagal membuat struk",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	respw.Header().Set("Content-Disposition", "inline; filename=\"struk-"+order.OrderNumber+".pdf\"")
+	respw.Header().Set("Content-Type", "application/pdf")
+	respw.Header().Set("Content-Length", fmt.Sprint(len(content)))
+	respw.WriteHeader(http.StatusOK)
+	respw.Write(content)
+}
+
+// orderReceipt - Mengisi struk dari data order dengan format rupiah dan waktu Asia/Jakarta
+func orderReceipt(order model.Order) (r receipt.Receipt, err error) {
+	date, err := FormatToIndonesianTime(order.OrderDate)
+	if err != nil {
+		return
+	}
+	r = receipt.Receipt{
+		ShopName:      config.ShopName,
+		ShopAddress:   config.ShopAddress,
+		ShopPhone:     config.ShopPhone,
+		OrderNumber:   order.OrderNumber,
+		QueueNumber:   order.QueueNumber,
+		Date:          date,
+		Cashier:       order.CreatedBy,
+		Customer:      order.UserInfo.Name,
+		Total:         rupiah.Format(order.Total),
+		PaymentMethod: order.PaymentMethod,
+		PaymentStatus: order.PaymentStatus,
+		StatusURL:     config.OrderStatusURL + url.QueryEscape(order.OrderNumber),
+	}
+	if order.Discount != nil || order.Points != nil || order.Delivery != nil {
+		r.Subtotal = rupiah.Format(order.Subtotal)
+	}
+	if order.Delivery != nil {
+		r.DeliveryFee = rupiah.Format(order.Delivery.Fee)
+		r.Address = order.Delivery.Address
+	}
+	if order.Discount != nil {
+		r.Discount = rupiah.Format(order.Discount.Amount)
+		r.VoucherCode = order.Discount.Code
+	}
+	if order.Points != nil {
+		r.PointsUsed = order.Points.Redeemed
+		r.PointsValue = rupiah.Format(order.Points.Discount)
+	}
+	for _, item := range order.Orders {
+		r.Items = append(r.Items, receipt.Item{
+			Name:      item.MenuName,
+			Options:   pesanan.ModifierLabel(item.Modifiers),
+			Quantity:  item.Quantity,
+			UnitPrice: rupiah.Format(item.Price),
+			Subtotal:  rupiah.Format(item.Price * float64(item.Quantity)),
+		})
+	}
+	return
+}
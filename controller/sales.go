@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/sales"
+	"github.com/gocroot/helper/xlsx"
+	"github.com/gocroot/model"
+)
+
+// GetSalesReport - Ringkasan penjualan untuk admin: pendapatan, jumlah order, rata-rata transaksi,
+// menu terlaris, rasio pembatalan dan jam ramai
+func GetSalesReport(respw http.ResponseWriter, req *http.Request) {
+	q, err := sales.ParseQuery(req.URL.Query(), time.Now())
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Parameter Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+	summary, err := sales.GetSalesSummary(config.Mongoconn, q)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Gagal menghitung laporan penjualan",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Laporan penjualan berhasil diambil",
+		"data":    summary,
+	})
+}
+
+// ExportSalesReport - Unduh laporan penjualan dalam format CSV atau XLSX: ?format=csv|xlsx
+func ExportSalesReport(respw http.ResponseWriter, req *http.Request) {
+	q, err := sales.ParseQuery(req.URL.Query(), time.Now())
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Parameter Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+	summary, err := sales.GetSalesSummary(config.Mongoconn, q)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Gagal menghitung laporan penjualan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	sheets := salesSheets(summary)
+	filename := "laporan-penjualan-" + q.From.Format("20060102") + "-" + q.To.AddDate(0, 0, -1).Format("20060102")
+	var content []byte
+	var contentType string
+	switch req.URL.Query().Get("format") {
+	case "xlsx":
+		content, err = xlsx.Write(sheets)
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		filename += ".xlsx"
+	case "", "csv":
+		content, err = salesCSV(sheets)
+		contentType = "text/csv; charset=utf-8"
+		filename += ".csv"
+	default:
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Format Tidak Valid",
+			Response: "Format hanya 'csv' atau 'xlsx'",
+		})
+		return
+	}
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal membuat file laporan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	respw.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	respw.Header().Set("Content-Type", contentType)
+	respw.Header().Set("Content-Length", fmt.Sprint(len(content)))
+	respw.WriteHeader(http.StatusOK)
+	respw.Write(content)
+}
+
+// salesSheets - Menyusun ringkasan penjualan menjadi tabel-tabel untuk diekspor
+func salesSheets(summary sales.Summary) []xlsx.Sheet {
+	ringkasan := xlsx.Sheet{Name: "Ringkasan", Rows: [][]interface{}{
+		{"Dari", summary.From},
+		{"Sampai", summary.To},
+		{"Pendapatan", summary.Revenue},
+		{"Jumlah Order Selesai", summary.OrderCount},
+		{"Rata-rata Transaksi", summary.AverageTicket},
+		{"Order Dibatalkan/Ditolak", summary.CancelledCount},
+		{"Rasio Pembatalan", summary.CancellationRate},
+	}}
+	periode := xlsx.Sheet{Name: "Periode", Rows: [][]interface{}{{"Periode (" + summary.Bucket + ")", "Jumlah Order", "Pendapatan"}}}
+	for _, s := range summary.Series {
+		periode.Rows = append(periode.Rows, []interface{}{s.Period, s.OrderCount, s.Revenue})
+	}
+	menuQty := xlsx.Sheet{Name: "Menu Terlaris (Qty)", Rows: [][]interface{}{{"Menu", "Jumlah", "Pendapatan"}}}
+	for _, m := range summary.TopByQuantity {
+		menuQty.Rows = append(menuQty.Rows, []interface{}{m.Name, m.Quantity, m.Revenue})
+	}
+	menuRevenue := xlsx.Sheet{Name: "Menu Terlaris (Pendapatan)", Rows: [][]interface{}{{"Menu", "Jumlah", "Pendapatan"}}}
+	for _, m := range summary.TopByRevenue {
+		menuRevenue.Rows = append(menuRevenue.Rows, []interface{}{m.Name, m.Quantity, m.Revenue})
+	}
+	jam := xlsx.Sheet{Name: "Jam Ramai", Rows: [][]interface{}{{"Jam", "Jumlah Order", "Pendapatan"}}}
+	for _, h := range summary.PeakHours {
+		jam.Rows = append(jam.Rows, []interface{}{fmt.Sprintf("%02d:00", h.Hour), h.OrderCount, h.Revenue})
+	}
+	pembayaran := xlsx.Sheet{Name: "Metode Pembayaran", Rows: [][]interface{}{{"Metode", "Jumlah Order", "Pendapatan"}}}
+	for _, p := range summary.ByPaymentMethod {
+		pembayaran.Rows = append(pembayaran.Rows, []interface{}{p.PaymentMethod, p.OrderCount, p.Revenue})
+	}
+	return []xlsx.Sheet{ringkasan, periode, menuQty, menuRevenue, jam, pembayaran}
+}
+
+// salesCSV - Semua tabel digabung dalam satu CSV, dipisah baris kosong dan judul tabel
+func salesCSV(sheets []xlsx.Sheet) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for i, sheet := range sheets {
+		if i > 0 {
+			w.Write([]string{})
+		}
+		w.Write([]string{sheet.Name})
+		for _, row := range sheet.Rows {
+			record := make([]string, len(row))
+			for j, value := range row {
+				switch v := value.(type) {
+				case float64:
+					record[j] = strconv.FormatFloat(v, 'f', -1, 64)
+				default:
+					record[j] = fmt.Sprint(v)
+				}
+			}
+			w.Write(record)
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
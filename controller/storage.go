@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/storage"
+)
+
+// ActiveStorage - Mengambil backend penyimpanan gambar menu, banner dan kategori sesuai config.StorageBackend, default GitHub
+func ActiveStorage() (storage.Storage, error) {
+	return publicStorage(config.StorageGitHubOrg, config.StorageGitHubRepo)
+}
+
+// ProfileStorage - Backend penyimpanan foto profil. Di GitHub tetap memakai repo profile terpisah,
+// di local dan s3 memakai folder/bucket yang sama dengan gambar menu karena sama-sama publik.
+func ProfileStorage() (storage.Storage, error) {
+	return publicStorage(config.StorageGitHubProjectOrg, config.StorageGitHubProfileRepo)
+}
+
+// KatalogStorage - Backend penyimpanan cover buku, di GitHub memakai repo katalog seperti ProfileStorage
+func KatalogStorage() (storage.Storage, error) {
+	return publicStorage(config.StorageGitHubProjectOrg, config.StorageGitHubKatalogRepo)
+}
+
+func publicStorage(githubOrg, githubRepo string) (storage.Storage, error) {
+	switch config.StorageBackend {
+	case "", "github":
+		return githubStorage(githubOrg, githubRepo)
+	case "local":
+		baseURL := config.StoragePublicURL
+		if baseURL == "" {
+			baseURL = "/files"
+		}
+		return storage.LocalStorage{Dir: config.StorageLocalDir, BaseURL: baseURL}, nil
+	case "s3":
+		return s3Storage(config.StorageS3Bucket, config.StoragePublicURL)
+	}
+	return nil, errors.New("backend penyimpanan tidak dikenal: " + config.StorageBackend)
+}
+
+// DocumentStorage - Backend penyimpanan dokumen privat (draft buku, SPK, SPI) sesuai config.StorageDocumentBackend.
+// Folder dan bucket dokumen harus terpisah dari gambar supaya tidak bisa dibuka lewat /files atau URL publik bucket.
+func DocumentStorage() (storage.Storage, error) {
+	switch config.StorageDocumentBackend {
+	case "", "github":
+		return githubStorage(config.StorageGitHubProjectOrg, config.StorageDocumentGitHubRepo)
+	case "local":
+		dir, errDir := filepath.Abs(config.StorageDocumentLocalDir)
+		public, errPublic := filepath.Abs(config.StorageLocalDir)
+		if errDir != nil || errPublic != nil || dir == public || strings.HasPrefix(dir, public+string(filepath.Separator)) {
+			return nil, errors.New("folder dokumen harus di luar folder gambar " + config.StorageLocalDir)
+		}
+		return storage.LocalStorage{Dir: dir}, nil
+	case "s3":
+		if config.StorageDocumentS3Bucket == "" || config.StorageDocumentS3Bucket == config.StorageS3Bucket {
+			return nil, errors.New("bucket dokumen S3 harus diisi dan berbeda dari bucket gambar")
+		}
+		return s3Storage(config.StorageDocumentS3Bucket, "")
+	}
+	return nil, errors.New("backend penyimpanan dokumen tidak dikenal: " + config.StorageDocumentBackend)
+}
+
+// legacyDocumentStorage - Repo GitHub privat tempat dokumen disimpan sebelum backend dokumen bisa diatur,
+// key lama pada project tetap dibaca dari sini jika tidak ada di backend dokumen yang aktif
+func legacyDocumentStorage() (storage.Storage, error) {
+	return githubStorage("penerbitbukupedia", "draft")
+}
+
+func githubStorage(org, repo string) (storage.Storage, error) {
+	if config.GHAccessToken == "" {
+		return nil, errors.New("token GitHub untuk penyimpanan belum diatur")
+	}
+	return storage.GitHubStorage{
+		Token:       config.GHAccessToken,
+		Org:         org,
+		Repo:        repo,
+		AuthorName:  config.StorageGitHubAuthorName,
+		AuthorEmail: config.StorageGitHubAuthorEmail,
+	}, nil
+}
+
+func s3Storage(bucket, publicURL string) (storage.Storage, error) {
+	if config.StorageS3Endpoint == "" || bucket == "" {
+		return nil, errors.New("endpoint dan bucket S3 belum diatur")
+	}
+	return storage.S3Storage{
+		Endpoint:  config.StorageS3Endpoint,
+		Region:    config.StorageS3Region,
+		Bucket:    bucket,
+		AccessKey: config.StorageS3AccessKey,
+		SecretKey: config.StorageS3SecretKey,
+		PublicURL: publicURL,
+	}, nil
+}
+
+// getDocument - Membaca dokumen privat dari backend dokumen, lalu dari repo lama untuk key yang disimpan sebelumnya
+func getDocument(key string) (content []byte, source string, err error) {
+	store, err := DocumentStorage()
+	if err != nil {
+		return
+	}
+	source = store.Name()
+	if content, err = store.Get(key); err == nil {
+		return
+	}
+	legacy, errLegacy := legacyDocumentStorage()
+	if errLegacy != nil || legacy == store {
+		return
+	}
+	if content, errLegacy = legacy.Get(key); errLegacy == nil {
+		return content, "github penerbitbukupedia/draft", nil
+	}
+	return
+}
+
+// deleteStoredFiles - Menghapus file lama setelah diganti, kegagalan hanya dicatat karena data utama sudah tersimpan
+func deleteStoredFiles(store storage.Storage, keys ...string) {
+	for _, key := range keys {
+		if err := store.Delete(key); err != nil {
+			log.Println("Gagal menghapus file " + key + " di " + store.Name() + ": " + err.Error())
+		}
+	}
+}
+
+// ServeStoredFile - Melayani file untuk backend local: /files/*key
+func ServeStoredFile(respw http.ResponseWriter, req *http.Request) {
+	store, err := ActiveStorage()
+	local, ok := store.(storage.LocalStorage)
+	if err != nil || !ok {
+		NotFound(respw, req)
+		return
+	}
+	local.Serve(respw, req, router.Param(req, "key"))
+}
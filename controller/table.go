@@ -0,0 +1,357 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/table"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetAllTable - Ambil semua meja yang belum dihapus (admin)
+func GetAllTable(respw http.ResponseWriter, req *http.Request) {
+	data, err := atdb.GetManyDocs[model.Table](config.Mongoconn, table.Collection, atdb.ExcludeDeleted(bson.M{}))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil data meja",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Data meja berhasil diambil",
+		"data":    data,
+	})
+}
+
+// GetTableByID - Ambil satu meja: /data/table/:id
+func GetTableByID(respw http.ResponseWriter, req *http.Request) {
+	t, ok := tableFromParam(respw, req)
+	if !ok {
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Meja ditemukan",
+		"data":    t,
+	})
+}
+
+// CreateTable - Tambah meja baru (admin), meja baru langsung aktif jika active tidak diisi false
+func CreateTable(respw http.ResponseWriter, req *http.Request) {
+	input := model.Table{Active: true}
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.Number = strings.TrimSpace(input.Number)
+	if err := table.Validate(input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Data Meja Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+	if !tableNumberAvailable(respw, input.Number, primitive.NilObjectID) {
+		return
+	}
+
+	input.ID = primitive.NilObjectID
+	input.TokenVersion = 1
+	input.CreatedAt = time.Now()
+	input.UpdatedAt = time.Time{}
+	input.DeletedAt, input.DeletedBy = time.Time{}, ""
+	insertedID, err := atdb.InsertOneDoc(config.Mongoconn, table.Collection, input)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menyimpan meja",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.ID = insertedID
+	audit.Record(config.Mongoconn, req, model.AuditCreate, table.Collection, insertedID, nil, input)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Meja berhasil ditambahkan",
+		"data":    input,
+	})
+}
+
+// UpdateTable - Ubah nomor, area, kursi dan status aktif meja (admin): /data/table/:id.
+// QR lama tetap berlaku, gunakan POST /data/table/:id/qr untuk menggantinya.
+func UpdateTable(respw http.ResponseWriter, req *http.Request) {
+	before, ok := tableFromParam(respw, req)
+	if !ok {
+		return
+	}
+	var input model.Table
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.Number = strings.TrimSpace(input.Number)
+	if err := table.Validate(input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Data Meja Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+	if !tableNumberAvailable(respw, input.Number, before.ID) {
+		return
+	}
+
+	update := bson.M{"$set": bson.M{
+		"number":     input.Number,
+		"area":       input.Area,
+		"seats":      input.Seats,
+		"active":     input.Active,
+		"updated_at": time.Now(),
+	}}
+	after, err := atdb.FindOneAndUpdateDoc[model.Table](config.Mongoconn, table.Collection, atdb.ExcludeDeleted(bson.M{"_id": before.ID}), update)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Meja tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	audit.Record(config.Mongoconn, req, model.AuditUpdate, table.Collection, after.ID, before, after)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Meja berhasil diupdate",
+		"data":    after,
+	})
+}
+
+// DeleteTable - Soft delete meja (admin), QR meja langsung tidak bisa dipakai memesan
+func DeleteTable(respw http.ResponseWriter, req *http.Request) {
+	softDeleteDoc[model.Table](respw, req, table.Collection, "Meja")
+}
+
+// RestoreTable - Mengembalikan meja yang dihapus beserta QR lamanya
+func RestoreTable(respw http.ResponseWriter, req *http.Request) {
+	restoreDoc[model.Table](respw, req, table.Collection, "Meja")
+}
+
+// GetTableQR - Unduh QR meja untuk dicetak: /data/table/:id/qr?format=pdf|png.
+// PDF berupa kartu A6 berisi nomor meja, PNG hanya gambar QR-nya.
+func GetTableQR(respw http.ResponseWriter, req *http.Request) {
+	t, ok := tableFromParam(respw, req)
+	if !ok {
+		return
+	}
+	writeTableQR(respw, req, t)
+}
+
+// RegenerateTableQR - Ganti QR meja (admin), misalnya QR tercetak hilang atau difoto orang lain.
+// Versi token dinaikkan sehingga QR lama ditolak, lalu QR baru langsung dikirim seperti GetTableQR.
+func RegenerateTableQR(respw http.ResponseWriter, req *http.Request) {
+	before, ok := tableFromParam(respw, req)
+	if !ok {
+		return
+	}
+	update := bson.M{"$inc": bson.M{"token_version": 1}, "$set": bson.M{"updated_at": time.Now()}}
+	after, err := atdb.FindOneAndUpdateDoc[model.Table](config.Mongoconn, table.Collection, atdb.ExcludeDeleted(bson.M{"_id": before.ID}), update)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Meja tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	audit.Record(config.Mongoconn, req, model.AuditUpdate, table.Collection, after.ID, before, after)
+	writeTableQR(respw, req, after)
+}
+
+// writeTableQR - Menandatangani token meja lalu mengirim QR-nya sebagai PDF atau PNG
+func writeTableQR(respw http.ResponseWriter, req *http.Request, t model.Table) {
+	token, err := table.EncodeToken(t, config.PrivateKey)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal membuat token meja",
+			Response: err.Error(),
+		})
+		return
+	}
+	orderURL := table.OrderURL(config.TableOrderURL, token)
+
+	var content []byte
+	var contentType, ext string
+	switch req.URL.Query().Get("format") {
+	case "", "pdf":
+		content, err = table.Card(config.ShopName, t, orderURL)
+		contentType, ext = "application/pdf", ".pdf"
+	case "png":
+		content, err = table.QRCode(orderURL, 512)
+		contentType, ext = "image/png", ".png"
+	default:
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Format Tidak Dikenal",
+			Response: "format harus pdf atau png",
+		})
+		return
+	}
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal membuat QR meja",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	respw.Header().Set("Content-Disposition", "inline; filename=\"meja-"+t.Number+ext+"\"")
+	respw.Header().Set("Content-Type", contentType)
+	respw.Header().Set("Content-Length", fmt.Sprint(len(content)))
+	respw.WriteHeader(http.StatusOK)
+	respw.Write(content)
+}
+
+// GetOpenTables - Tampilan kasir: pesanan dine-in yang belum ditutup dikelompokkan per meja
+func GetOpenTables(respw http.ResponseWriter, req *http.Request) {
+	orders, err := atdb.GetManyDocs[model.Order](config.Mongoconn, "orders", table.OpenFilter(primitive.NilObjectID))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil pesanan meja",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Pesanan meja berhasil diambil",
+		"data":    table.Group(orders),
+	})
+}
+
+// CloseTable - Tutup meja setelah pelanggan selesai: semua pesanan meja yang masih terbuka ditandai settled
+// dan pesanan Cash dibayar di kasir. Ditolak jika masih ada pesanan yang belum selesai atau QRIS yang belum lunas.
+func CloseTable(respw http.ResponseWriter, req *http.Request) {
+	user, _ := rbac.CurrentUser(req)
+
+	tableID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Meja tidak valid",
+		})
+		return
+	}
+	// Meja yang sudah dihapus tetap bisa ditutup supaya tagihannya tidak menggantung
+	orders, err := atdb.GetManyDocs[model.Order](config.Mongoconn, "orders", table.OpenFilter(tableID))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil pesanan meja",
+			Response: err.Error(),
+		})
+		return
+	}
+	if len(orders) == 0 {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status: "Error: Tidak ada pesanan terbuka di meja ini",
+		})
+		return
+	}
+	if err := table.CheckSettle(orders); err != nil {
+		at.WriteJSON(respw, http.StatusConflict, model.Response{
+			Status:   "Error: Meja Belum Bisa Ditutup",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+	if err := table.Settle(config.Mongoconn, ids, user.Name, time.Now()); err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menutup meja",
+			Response: err.Error(),
+		})
+		return
+	}
+	settled, err := atdb.GetManyDocs[model.Order](config.Mongoconn, "orders", bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal mengambil pesanan meja",
+			Response: err.Error(),
+		})
+		return
+	}
+	before := make(map[primitive.ObjectID]model.Order, len(orders))
+	for _, order := range orders {
+		before[order.ID] = order
+	}
+	for _, order := range settled {
+		audit.Record(config.Mongoconn, req, model.AuditUpdate, "orders", order.ID, before[order.ID], order)
+	}
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Meja " + orders[0].TableNumber + " berhasil ditutup",
+		"user":    user.Name,
+		"data":    table.Group(settled),
+	})
+}
+
+// tableFromParam - Mengambil meja :id yang belum dihapus, respons error sudah dikirim jika ok false
+func tableFromParam(respw http.ResponseWriter, req *http.Request) (t model.Table, ok bool) {
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Meja tidak valid",
+		})
+		return
+	}
+	t, err = atdb.GetOneDoc[model.Table](config.Mongoconn, table.Collection, atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Meja tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	return t, true
+}
+
+// tableNumberAvailable - Nomor meja harus unik di antara meja yang belum dihapus, respons 409 dikirim jika sudah dipakai
+func tableNumberAvailable(respw http.ResponseWriter, number string, exceptID primitive.ObjectID) bool {
+	filter := atdb.ExcludeDeleted(bson.M{"number": number, "_id": bson.M{"$ne": exceptID}})
+	count, err := atdb.GetCountDoc(config.Mongoconn, table.Collection, filter)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal memeriksa nomor meja",
+			Response: err.Error(),
+		})
+		return false
+	}
+	if count > 0 {
+		at.WriteJSON(respw, http.StatusConflict, model.Response{
+			Status:   "Error: Nomor Meja Sudah Dipakai",
+			Response: number,
+		})
+		return false
+	}
+	return true
+}
@@ -18,8 +18,10 @@ import (
 	"github.com/gocroot/helper/at"
 	"github.com/gocroot/helper/atapi"
 	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
 	"github.com/gocroot/helper/gcallapi"
 	"github.com/gocroot/helper/lms"
+	"github.com/gocroot/helper/rbac"
 	"github.com/gocroot/helper/report"
 	"github.com/gocroot/helper/watoken"
 	"github.com/gocroot/helper/whatsauth"
@@ -66,38 +68,35 @@ func GetDataUser(respw http.ResponseWriter, req *http.Request) {
 	at.WriteJSON(respw, http.StatusOK, docuser)
 }
 
-// GetAllDataUsers - Ambil Semua Data Pengguna
+// GetAllDataUsers - Ambil Data Pengguna per halaman.
+// Query: ?page=&limit=&cursor=&sort=name|email&order=asc|desc&role=&q=
 func GetAllDataUsers(respw http.ResponseWriter, req *http.Request) {
-	// Dekode token WhatsAuth untuk validasi
-	_, err := watoken.Decode(config.PublicKeyWhatsAuth, at.GetLoginFromHeader(req))
+	params := req.URL.Query()
+	q, err := atdb.ParsePageQuery(params, "name", false, "name", "email")
 	if err != nil {
-		at.WriteJSON(respw, http.StatusForbidden, model.Response{
-			Status:   "Error: Token Tidak Valid",
-			Location: "Decode Token Error",
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Parameter Tidak Valid",
 			Response: err.Error(),
 		})
 		return
 	}
+	filter := bson.M{}
+	if role := params.Get("role"); role != "" {
+		filter["role"] = role
+	}
+	atdb.MatchText(filter, params.Get("q"), "name", "email", "phonenumber")
 
-	// Ambil semua data pengguna
-	data, err := atdb.GetAllDoc[[]model.Userdomyikado](config.Mongoconn, "user", bson.M{})
+	data, page, err := atdb.GetPagedDocs[model.Userdomyikado](config.Mongoconn, "user", filter, q)
 	if err != nil {
-		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
 			Status:   "Error: Data pengguna tidak ditemukan",
 			Response: err.Error(),
 		})
 		return
 	}
 
-	if len(data) == 0 {
-		at.WriteJSON(respw, http.StatusNotFound, model.Response{
-			Status: "Error: Data pengguna kosong",
-		})
-		return
-	}
-
 	// Deklarasi variabel untuk hasil format
-	var users []map[string]interface{}
+	users := make([]map[string]interface{}, 0, len(data))
 
 	// Format data pengguna sebelum dikirim
 	for _, user := range data {
@@ -112,9 +111,10 @@ func GetAllDataUsers(respw http.ResponseWriter, req *http.Request) {
 
 	// Kirim respons dengan data yang sudah diformat
 	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
-		"status":  "success",
-		"message": "Data pengguna berhasil diambil",
-		"data":    users,
+		"status":     "success",
+		"message":    "Data pengguna berhasil diambil",
+		"data":       users,
+		"pagination": page,
 	})
 }
 
@@ -132,7 +132,8 @@ func UpdateUserRole(w http.ResponseWriter, r *http.Request) {
         return
     }
     validRoles := map[string]bool{"user": true, "admin": true, "cashier": true, "dosen": true}
-    if !validRoles[request.Role] {
+    // role baru yang dibuat lewat /data/role/:role juga boleh dipakai
+    if !validRoles[request.Role] && len(rbac.Permissions(config.Mongoconn, request.Role)) == 0 {
         w.Header().Set("Content-Type", "application/json")
         w.WriteHeader(http.StatusBadRequest)
         json.NewEncoder(w).Encode(map[string]string{"message": "Invalid role"})
@@ -142,14 +143,25 @@ func UpdateUserRole(w http.ResponseWriter, r *http.Request) {
     defer cancel()
     collection := config.Mongoconn.Collection("user")
     filter := bson.M{"email": request.Email}
+    // role lama dibutuhkan untuk audit log
+    before, err := atdb.GetOneDoc[model.Userdomyikado](config.Mongoconn, "user", filter)
+    if err != nil {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusNotFound)
+        json.NewEncoder(w).Encode(map[string]string{"message": "User not found"})
+        return
+    }
     update := bson.M{"$set": bson.M{"role": request.Role}}
-    _, err := collection.UpdateOne(ctx, filter, update)
+    _, err = collection.UpdateOne(ctx, filter, update)
     if err != nil {
         w.Header().Set("Content-Type", "application/json")
         w.WriteHeader(http.StatusInternalServerError)
         json.NewEncoder(w).Encode(map[string]string{"message": "Failed to update user role"})
         return
     }
+    after := before
+    after.Role = request.Role
+    audit.Record(config.Mongoconn, r, model.AuditUpdate, "user", before.ID, before, after)
     response := map[string]string{"message": "User role updated successfully"}
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusOK)
@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/router"
+	"github.com/gocroot/helper/voucher"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetAllVoucher - Ambil semua voucher (admin)
+func GetAllVoucher(respw http.ResponseWriter, req *http.Request) {
+	data, err := atdb.GetAllDoc[[]model.Voucher](config.Mongoconn, "voucher", atdb.ExcludeDeleted(bson.M{}))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Data voucher tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Data voucher berhasil diambil",
+		"data":    data,
+	})
+}
+
+// GetVoucherByID - Ambil satu voucher beserta statistik pemakaiannya: /data/voucher/:id
+func GetVoucherByID(respw http.ResponseWriter, req *http.Request) {
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Voucher tidak valid",
+		})
+		return
+	}
+
+	data, err := atdb.GetOneDoc[model.Voucher](config.Mongoconn, "voucher", atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Voucher tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Voucher ditemukan",
+		"data":    data,
+	})
+}
+
+// GetVoucherStats - Statistik pemakaian voucher: /data/voucher/:id/stats
+func GetVoucherStats(respw http.ResponseWriter, req *http.Request) {
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Voucher tidak valid",
+		})
+		return
+	}
+
+	data, err := atdb.GetOneDoc[model.Voucher](config.Mongoconn, "voucher", atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Voucher tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	stats, err := voucher.GetStats(config.Mongoconn, objectID)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menghitung statistik voucher",
+			Response: err.Error(),
+		})
+		return
+	}
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Statistik voucher berhasil diambil",
+		"data": map[string]interface{}{
+			"voucher":     data,
+			"used_count":  data.UsedCount,
+			"usage_limit": data.UsageLimit,
+			"stats":       stats,
+		},
+	})
+}
+
+// CreateVoucher - Tambah voucher baru (admin)
+func CreateVoucher(respw http.ResponseWriter, req *http.Request) {
+	var input model.Voucher
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.Code = voucher.NormalizeCode(input.Code)
+	if err := voucher.Validate(input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Data Voucher Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+	// Kode voucher yang sudah dihapus tetap dipesan, supaya restore tidak membuat kode ganda
+	if count, err := atdb.GetCountDoc(config.Mongoconn, "voucher", bson.M{"code": input.Code}); err != nil || count > 0 {
+		at.WriteJSON(respw, http.StatusConflict, model.Response{
+			Status:   "Error: Kode Voucher Sudah Dipakai",
+			Response: input.Code,
+		})
+		return
+	}
+
+	input.ID = primitive.NilObjectID
+	input.UsedCount = 0
+	input.CreatedAt = time.Now()
+	insertedID, err := atdb.InsertOneDoc(config.Mongoconn, "voucher", input)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusInternalServerError, model.Response{
+			Status:   "Error: Gagal menyimpan voucher",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.ID = insertedID
+	audit.Record(config.Mongoconn, req, model.AuditCreate, "voucher", insertedID, nil, input)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Voucher berhasil ditambahkan",
+		"data":    input,
+	})
+}
+
+// UpdateVoucher - Ubah voucher (admin), jumlah pemakaian tidak ikut diubah: /data/voucher/:id
+func UpdateVoucher(respw http.ResponseWriter, req *http.Request) {
+	objectID, err := primitive.ObjectIDFromHex(router.Param(req, "id"))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status: "Error: ID Voucher tidak valid",
+		})
+		return
+	}
+	before, err := atdb.GetOneDoc[model.Voucher](config.Mongoconn, "voucher", atdb.ExcludeDeleted(bson.M{"_id": objectID}))
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Voucher tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+
+	var input model.Voucher
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Bad Request",
+			Response: err.Error(),
+		})
+		return
+	}
+	input.Code = voucher.NormalizeCode(input.Code)
+	if err := voucher.Validate(input); err != nil {
+		at.WriteJSON(respw, http.StatusBadRequest, model.Response{
+			Status:   "Error: Data Voucher Tidak Valid",
+			Response: err.Error(),
+		})
+		return
+	}
+	// Kode voucher yang sudah dihapus tetap dipesan, supaya restore tidak membuat kode ganda
+	if count, err := atdb.GetCountDoc(config.Mongoconn, "voucher", bson.M{"code": input.Code, "_id": bson.M{"$ne": objectID}}); err != nil || count > 0 {
+		at.WriteJSON(respw, http.StatusConflict, model.Response{
+			Status:   "Error: Kode Voucher Sudah Dipakai",
+			Response: input.Code,
+		})
+		return
+	}
+
+	update := bson.M{"$set": bson.M{
+		"code":           input.Code,
+		"name":           input.Name,
+		"description":    input.Description,
+		"type":           input.Type,
+		"value":          input.Value,
+		"max_discount":   input.MaxDiscount,
+		"min_spend":      input.MinSpend,
+		"buy_quantity":   input.BuyQuantity,
+		"get_quantity":   input.GetQuantity,
+		"menu_ids":       input.MenuIDs,
+		"category_ids":   input.CategoryIDs,
+		"start_at":       input.StartAt,
+		"end_at":         input.EndAt,
+		"usage_limit":    input.UsageLimit,
+		"per_user_limit": input.PerUserLimit,
+		"active":         input.Active,
+	}}
+	data, err := atdb.FindOneAndUpdateDoc[model.Voucher](config.Mongoconn, "voucher", atdb.ExcludeDeleted(bson.M{"_id": objectID}), update)
+	if err != nil {
+		at.WriteJSON(respw, http.StatusNotFound, model.Response{
+			Status:   "Error: Voucher tidak ditemukan",
+			Response: err.Error(),
+		})
+		return
+	}
+	audit.Record(config.Mongoconn, req, model.AuditUpdate, "voucher", objectID, before, data)
+
+	at.WriteJSON(respw, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Voucher berhasil diupdate",
+		"data":    data,
+	})
+}
+
+// DeleteVoucher - Soft delete voucher (admin), riwayat pemakaian tetap tersimpan: /data/voucher/:id
+func DeleteVoucher(respw http.ResponseWriter, req *http.Request) {
+	softDeleteDoc[model.Voucher](respw, req, "voucher", "Voucher")
+}
+
+// RestoreVoucher - Mengembalikan voucher yang sudah di-soft delete: /data/voucher/:id/restore
+func RestoreVoucher(respw http.ResponseWriter, req *http.Request) {
+	restoreDoc[model.Voucher](respw, req, "voucher", "Voucher")
+}
@@ -130,3 +130,11 @@ func NotFound(respw http.ResponseWriter, req *http.Request) {
 	resp.Response = "Not Found"
 	at.WriteJSON(respw, http.StatusNotFound, resp)
 }
+
+// MethodNotAllowed - Path ada tetapi method salah, header Allow sudah diisi router
+func MethodNotAllowed(respw http.ResponseWriter, req *http.Request) {
+	var resp model.Response
+	resp.Response = "Method Not Allowed"
+	resp.Info = "Allow: " + respw.Header().Get("Allow")
+	at.WriteJSON(respw, http.StatusMethodNotAllowed, resp)
+}
@@ -2,6 +2,7 @@ package atapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -9,9 +10,14 @@ import (
 )
 
 func PostStructWithToken[T any](tokenkey string, tokenvalue string, structname interface{}, urltarget string) (statusCode int, result T, err error) {
+	return PostStructWithTokenContext[T](context.Background(), tokenkey, tokenvalue, structname, urltarget)
+}
+
+// PostStructWithTokenContext sama dengan PostStructWithToken, request dibatalkan saat ctx habis waktu
+func PostStructWithTokenContext[T any](ctx context.Context, tokenkey string, tokenvalue string, structname interface{}, urltarget string) (statusCode int, result T, err error) {
 	client := http.Client{}
 	mJson, _ := json.Marshal(structname)
-	req, err := http.NewRequest("POST", urltarget, bytes.NewBuffer(mJson))
+	req, err := http.NewRequestWithContext(ctx, "POST", urltarget, bytes.NewBuffer(mJson))
 	if err != nil {
 		return
 	}
@@ -232,3 +232,104 @@ func ReplaceOneDoc(db *mongo.Database, collection string, filter bson.M, doc int
 	}
 	return
 }
+
+// IncrementCounter menaikkan counter dengan key tertentu secara atomik (find-and-modify) dan mengembalikan nilai barunya.
+// Dokumen counter dibuat otomatis jika belum ada, sehingga aman dipakai bersamaan oleh banyak instance.
+func IncrementCounter(db *mongo.Database, collection string, key string) (seq int, err error) {
+	filter := bson.M{"_id": key}
+	update := bson.M{"$inc": bson.M{"seq": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	err = db.Collection(collection).FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&counter)
+	if err != nil {
+		return
+	}
+	return counter.Seq, nil
+}
+
+// IncrementCounterLimit seperti IncrementCounter, tetapi hanya menaikkan counter selama nilainya masih di bawah limit.
+// ok bernilai false jika counter sudah mencapai limit.
+func IncrementCounterLimit(db *mongo.Database, collection string, key string, limit int) (seq int, ok bool, err error) {
+	filter := bson.M{"_id": key, "seq": bson.M{"$lt": limit}}
+	update := bson.M{"$inc": bson.M{"seq": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	err = db.Collection(collection).FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&counter)
+	// dokumen sudah ada tetapi tidak cocok dengan filter, upsert gagal karena _id yang sama
+	if mongo.IsDuplicateKeyError(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return
+	}
+	return counter.Seq, true, nil
+}
+
+// AddCounterLimit seperti IncrementCounterLimit, tetapi menaikkan counter sebanyak n sekaligus
+// selama hasilnya tidak melebihi limit. ok bernilai false jika sisa kuota kurang dari n.
+func AddCounterLimit(db *mongo.Database, collection string, key string, n, limit int) (seq int, ok bool, err error) {
+	if n > limit {
+		return 0, false, nil
+	}
+	filter := bson.M{"_id": key, "seq": bson.M{"$lte": limit - n}}
+	update := bson.M{"$inc": bson.M{"seq": n}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	err = db.Collection(collection).FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&counter)
+	if mongo.IsDuplicateKeyError(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return
+	}
+	return counter.Seq, true, nil
+}
+
+// UpdateDoc menjalankan update dengan operator bebas ($set, $push, $inc, ...) tanpa upsert.
+// Cocok untuk update bersyarat, misalnya hanya jika status dokumen masih sama.
+func UpdateDoc(db *mongo.Database, collection string, filter bson.M, update bson.M) (updateresult *mongo.UpdateResult, err error) {
+	updateresult, err = db.Collection(collection).UpdateOne(context.TODO(), filter, update)
+	return
+}
+
+// UpdateManyDocs seperti UpdateDoc tetapi untuk semua dokumen yang cocok dengan filter
+func UpdateManyDocs(db *mongo.Database, collection string, filter bson.M, update bson.M) (updateresult *mongo.UpdateResult, err error) {
+	updateresult, err = db.Collection(collection).UpdateMany(context.TODO(), filter, update)
+	return
+}
+
+// FindOneAndUpdateDoc menjalankan update atomik pada satu dokumen dan mengembalikan dokumen setelah diupdate.
+// Mengembalikan mongo.ErrNoDocuments jika tidak ada dokumen yang cocok dengan filter.
+func FindOneAndUpdateDoc[T any](db *mongo.Database, collection string, filter bson.M, update bson.M) (doc T, err error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = db.Collection(collection).FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&doc)
+	return
+}
+
+// FindOneAndUpsertDoc seperti FindOneAndUpdateDoc, tetapi membuat dokumen baru jika belum ada yang cocok dengan filter
+func FindOneAndUpsertDoc[T any](db *mongo.Database, collection string, filter bson.M, update bson.M) (doc T, err error) {
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = db.Collection(collection).FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&doc)
+	return
+}
+
+// AggregateDocs menjalankan aggregation pipeline dan mendecode seluruh hasilnya ke []T
+func AggregateDocs[T any](db *mongo.Database, collection string, pipeline mongo.Pipeline) (result []T, err error) {
+	ctx := context.TODO()
+	cursor, err := db.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx)
+	err = cursor.All(ctx, &result)
+	return
+}
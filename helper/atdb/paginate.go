@@ -0,0 +1,259 @@
+package atdb
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Batas jumlah dokumen per halaman
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// ErrInvalidCursor dikembalikan jika cursor dari client tidak bisa dibaca
+var ErrInvalidCursor = errors.New("cursor tidak valid")
+
+// PageQuery parameter pagination untuk GetPagedDocs.
+// Jika Cursor diisi, Page diabaikan dan halaman berikutnya diambil setelah dokumen terakhir pada cursor (keyset),
+// sehingga tetap cepat untuk koleksi besar dan tidak melompati dokumen saat ada data baru.
+type PageQuery struct {
+	Page   int64  // dimulai dari 1
+	Limit  int64  // jumlah dokumen per halaman
+	Sort   string // nama field bson untuk pengurutan, _id dipakai sebagai pengurut kedua
+	Desc   bool   // urutan menurun
+	Cursor string // next_cursor dari respons sebelumnya
+}
+
+// Pagination metadata halaman yang dikirim ke client bersama data
+type Pagination struct {
+	Total      int64  `json:"total"`          // jumlah dokumen yang cocok dengan filter
+	Page       int64  `json:"page,omitempty"` // kosong jika memakai cursor
+	Limit      int64  `json:"limit"`
+	Sort       string `json:"sort"`
+	Order      string `json:"order"` // asc atau desc
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ParsePageQuery membaca ?page=2&limit=20&sort=name&order=asc&cursor=... dari query string.
+// sortable berisi field yang boleh dipakai untuk sort, defaultSort dipakai jika sort tidak diisi.
+func ParsePageQuery(params url.Values, defaultSort string, defaultDesc bool, sortable ...string) (q PageQuery, err error) {
+	q = PageQuery{Page: 1, Limit: DefaultPageLimit, Sort: defaultSort, Desc: defaultDesc, Cursor: params.Get("cursor")}
+	if page := params.Get("page"); page != "" {
+		if q.Page, err = strconv.ParseInt(page, 10, 64); err != nil || q.Page < 1 {
+			return q, errors.New("page harus angka mulai dari 1")
+		}
+	}
+	if limit := params.Get("limit"); limit != "" {
+		if q.Limit, err = strconv.ParseInt(limit, 10, 64); err != nil || q.Limit < 1 {
+			return q, errors.New("limit harus angka mulai dari 1")
+		}
+		if q.Limit > MaxPageLimit {
+			q.Limit = MaxPageLimit
+		}
+	}
+	if sort := params.Get("sort"); sort != "" {
+		allowed := false
+		for _, field := range sortable {
+			allowed = allowed || field == sort
+		}
+		if !allowed {
+			return q, errors.New("sort hanya boleh: " + strings.Join(sortable, ", "))
+		}
+		q.Sort = sort
+	}
+	if q.Cursor != "" {
+		if _, err = decodeCursor(q.Cursor); err != nil {
+			return q, err
+		}
+	}
+	switch params.Get("order") {
+	case "":
+	case "asc":
+		q.Desc = false
+	case "desc":
+		q.Desc = true
+	default:
+		return q, errors.New("order hanya boleh asc atau desc")
+	}
+	return q, nil
+}
+
+// ParseDateRange membaca ?from=2024-10-01&to=2024-10-31 dalam zona waktu Asia/Jakarta.
+// Tanggal akhir ikut dihitung (to dikembalikan sebagai awal hari berikutnya), nilai kosong berarti tidak dibatasi.
+func ParseDateRange(params url.Values) (from, to time.Time, err error) {
+	location, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return
+	}
+	if s := params.Get("from"); s != "" {
+		if from, err = time.ParseInLocation("2006-01-02", s, location); err != nil {
+			return
+		}
+	}
+	if s := params.Get("to"); s != "" {
+		if to, err = time.ParseInLocation("2006-01-02", s, location); err != nil {
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	return
+}
+
+// MatchDateRange menambahkan filter rentang waktu [from, to) pada field, waktu nol diabaikan
+func MatchDateRange(filter bson.M, field string, from, to time.Time) {
+	cond := bson.M{}
+	if !from.IsZero() {
+		cond["$gte"] = from
+	}
+	if !to.IsZero() {
+		cond["$lt"] = to
+	}
+	if len(cond) > 0 {
+		filter[field] = cond
+	}
+}
+
+// MatchText menambahkan pencarian teks bebas (tanpa membedakan huruf besar kecil) pada salah satu field
+func MatchText(filter bson.M, text string, fields ...string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(text), Options: "i"}
+	var or []bson.M
+	for _, field := range fields {
+		or = append(or, bson.M{field: pattern})
+	}
+	filter["$or"] = or
+}
+
+// GetPagedDocs mengambil satu halaman dokumen sesuai filter, beserta jumlah total dan cursor halaman berikutnya
+func GetPagedDocs[T any](db *mongo.Database, collection string, filter bson.M, q PageQuery) (docs []T, page Pagination, err error) {
+	if q.Limit < 1 {
+		q.Limit = DefaultPageLimit
+	}
+	if q.Sort == "" {
+		q.Sort = "_id"
+	}
+	dir := 1
+	page = Pagination{Limit: q.Limit, Sort: q.Sort, Order: "asc"}
+	if q.Desc {
+		dir = -1
+		page.Order = "desc"
+	}
+
+	coll := db.Collection(collection)
+	if page.Total, err = coll.CountDocuments(context.TODO(), filter); err != nil {
+		return
+	}
+
+	sort := bson.D{{Key: q.Sort, Value: dir}}
+	if q.Sort != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: dir})
+	}
+	opts := options.Find().SetSort(sort).SetLimit(q.Limit + 1)
+	find := filter
+	if q.Cursor != "" {
+		var after bson.M
+		if after, err = cursorFilter(q, dir); err != nil {
+			return
+		}
+		if len(filter) > 0 {
+			find = bson.M{"$and": []bson.M{filter, after}}
+		} else {
+			find = after
+		}
+	} else {
+		page.Page = q.Page
+		opts.SetSkip((q.Page - 1) * q.Limit)
+	}
+
+	cursor, err := coll.Find(context.TODO(), find, opts)
+	if err != nil {
+		return
+	}
+	var raws []bson.Raw
+	if err = cursor.All(context.TODO(), &raws); err != nil {
+		return
+	}
+	if int64(len(raws)) > q.Limit {
+		raws = raws[:q.Limit]
+		page.HasMore = true
+	}
+	docs = make([]T, 0, len(raws))
+	for _, raw := range raws {
+		var doc T
+		if err = bson.Unmarshal(raw, &doc); err != nil {
+			return
+		}
+		docs = append(docs, doc)
+	}
+	if page.HasMore {
+		page.NextCursor, err = encodeCursor(raws[len(raws)-1], q.Sort)
+	}
+	return
+}
+
+// pageCursor isi cursor: nilai field sort dan _id dokumen terakhir pada halaman
+type pageCursor struct {
+	Value bson.RawValue      `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+func encodeCursor(last bson.Raw, sort string) (string, error) {
+	c := pageCursor{Value: last.Lookup(strings.Split(sort, ".")...)}
+	if err := last.Lookup("_id").Unmarshal(&c.ID); err != nil {
+		return "", err
+	}
+	if c.Value.Type == 0 {
+		c.Value = bson.RawValue{Type: bson.TypeNull}
+	}
+	b, err := bson.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string) (c pageCursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err = bson.Unmarshal(b, &c); err != nil || c.ID.IsZero() {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// cursorFilter membuat filter dokumen yang berada setelah cursor sesuai arah sort
+func cursorFilter(q PageQuery, dir int) (bson.M, error) {
+	c, err := decodeCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	op := "$gt"
+	if dir < 0 {
+		op = "$lt"
+	}
+	if q.Sort == "_id" {
+		return bson.M{"_id": bson.M{op: c.ID}}, nil
+	}
+	return bson.M{"$or": []bson.M{
+		{q.Sort: bson.M{op: c.Value}},
+		{q.Sort: c.Value, "_id": bson.M{op: c.ID}},
+	}}, nil
+}
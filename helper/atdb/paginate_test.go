@@ -0,0 +1,155 @@
+package atdb
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParsePageQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    PageQuery
+		wantErr bool
+	}{
+		{"", PageQuery{Page: 1, Limit: DefaultPageLimit, Sort: "created_at", Desc: true}, false},
+		{"page=3&limit=10&sort=name&order=asc", PageQuery{Page: 3, Limit: 10, Sort: "name"}, false},
+		{"limit=500", PageQuery{Page: 1, Limit: MaxPageLimit, Sort: "created_at", Desc: true}, false},
+		{"page=0", PageQuery{}, true},
+		{"page=dua", PageQuery{}, true},
+		{"limit=0", PageQuery{}, true},
+		{"sort=password", PageQuery{}, true},
+		{"order=naik", PageQuery{}, true},
+		{"cursor=bukan-cursor", PageQuery{}, true},
+	}
+	for _, tt := range tests {
+		params, _ := url.ParseQuery(tt.query)
+		got, err := ParsePageQuery(params, "created_at", true, "name", "price", "created_at")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParsePageQuery(%q) tidak error", tt.query)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePageQuery(%q) = %+v, %v, want %+v", tt.query, got, err, tt.want)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	id := primitive.NewObjectID()
+	last, _ := bson.Marshal(bson.M{"_id": id, "name": "Kopi Susu", "price": 20000})
+
+	encoded, err := encodeCursor(last, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ID != id || c.Value.StringValue() != "Kopi Susu" {
+		t.Errorf("decodeCursor = %s, %v, want %s, Kopi Susu", c.ID.Hex(), c.Value, id.Hex())
+	}
+
+	// cursor bisa dipakai lewat query string
+	params := url.Values{"cursor": {encoded}}
+	if _, err := ParsePageQuery(params, "name", false, "name"); err != nil {
+		t.Errorf("ParsePageQuery dengan cursor: %v", err)
+	}
+
+	// field sort yang tidak ada di dokumen disimpan sebagai null
+	encoded, err = encodeCursor(last, "category")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err = decodeCursor(encoded); err != nil || c.Value.Type != bson.TypeNull {
+		t.Errorf("cursor field kosong = %v, %v, want null", c.Value, err)
+	}
+
+	// bukan base64, bukan dokumen BSON, dan dokumen tanpa _id
+	noID, _ := bson.Marshal(bson.M{"v": "Kopi Susu"})
+	for _, invalid := range []string{"!!!", base64.RawURLEncoding.EncodeToString([]byte("{}")), base64.RawURLEncoding.EncodeToString(noID)} {
+		if _, err := decodeCursor(invalid); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q) = %v, want ErrInvalidCursor", invalid, err)
+		}
+	}
+}
+
+func TestCursorFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+	last, _ := bson.Marshal(bson.M{"_id": id, "name": "Kopi Susu"})
+	byName, _ := encodeCursor(last, "name")
+	byID, _ := encodeCursor(last, "_id")
+
+	got, err := cursorFilter(PageQuery{Sort: "_id", Cursor: byID}, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (bson.M{"_id": bson.M{"$lt": id}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("cursorFilter(_id desc) = %v, want %v", got, want)
+	}
+
+	got, err = cursorFilter(PageQuery{Sort: "name", Cursor: byName}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	or, ok := got["$or"].([]bson.M)
+	if !ok || len(or) != 2 {
+		t.Fatalf("cursorFilter(name asc) = %v", got)
+	}
+	if v := or[0]["name"].(bson.M)["$gt"].(bson.RawValue); v.StringValue() != "Kopi Susu" {
+		t.Errorf("nama setelah cursor = %v", v)
+	}
+	if v := or[1]["_id"].(bson.M)["$gt"]; v != id {
+		t.Errorf("pengurut kedua _id = %v, want %s", v, id.Hex())
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	location, _ := time.LoadLocation("Asia/Jakarta")
+	from, to, err := ParseDateRange(url.Values{"from": {"2024-10-01"}, "to": {"2024-10-31"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !from.Equal(time.Date(2024, 10, 1, 0, 0, 0, 0, location)) || !to.Equal(time.Date(2024, 11, 1, 0, 0, 0, 0, location)) {
+		t.Errorf("ParseDateRange = %v - %v", from, to)
+	}
+	if from, to, err = ParseDateRange(url.Values{}); err != nil || !from.IsZero() || !to.IsZero() {
+		t.Errorf("ParseDateRange kosong = %v - %v, %v", from, to, err)
+	}
+	if _, _, err = ParseDateRange(url.Values{"from": {"01/10/2024"}}); err == nil {
+		t.Error("format tanggal salah tidak error")
+	}
+}
+
+func TestMatchFilters(t *testing.T) {
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	filter := bson.M{}
+	MatchDateRange(filter, "orderDate", from, time.Time{})
+	MatchText(filter, " kopi.susu ", "name", "description")
+	want := bson.M{
+		"orderDate": bson.M{"$gte": from},
+		"$or": []bson.M{
+			{"name": primitive.Regex{Pattern: `kopi\.susu`, Options: "i"}},
+			{"description": primitive.Regex{Pattern: `kopi\.susu`, Options: "i"}},
+		},
+	}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("filter = %v, want %v", filter, want)
+	}
+
+	empty := bson.M{}
+	MatchDateRange(empty, "orderDate", time.Time{}, time.Time{})
+	MatchText(empty, "  ", "name")
+	if len(empty) != 0 {
+		t.Errorf("filter tanpa rentang dan teks = %v", empty)
+	}
+}
@@ -0,0 +1,36 @@
+package atdb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ExcludeDeleted menambahkan syarat dokumen belum dihapus (soft delete) pada filter, filter yang sama dikembalikan
+func ExcludeDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = bson.M{"$exists": false}
+	return filter
+}
+
+// SoftDeleteOneDoc menandai dokumen sebagai terhapus dengan deleted_at dan deleted_by tanpa menghapusnya,
+// sehingga bisa dikembalikan dengan RestoreOneDoc. MatchedCount 0 jika dokumen tidak ada atau sudah terhapus.
+func SoftDeleteOneDoc(db *mongo.Database, collection string, filter bson.M, deletedBy string) (*mongo.UpdateResult, error) {
+	match := bson.M{}
+	for k, v := range filter {
+		match[k] = v
+	}
+	update := bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy}}
+	return db.Collection(collection).UpdateOne(context.TODO(), ExcludeDeleted(match), update)
+}
+
+// RestoreOneDoc mengembalikan dokumen yang dihapus dengan SoftDeleteOneDoc. MatchedCount 0 jika dokumen tidak sedang terhapus.
+func RestoreOneDoc(db *mongo.Database, collection string, filter bson.M) (*mongo.UpdateResult, error) {
+	match := bson.M{"deleted_at": bson.M{"$exists": true}}
+	for k, v := range filter {
+		match[k] = v
+	}
+	update := bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}}
+	return db.Collection(collection).UpdateOne(context.TODO(), match, update)
+}
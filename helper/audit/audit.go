@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collection nama koleksi audit log
+const Collection = "audit_log"
+
+// Actor pelaku perubahan yang dicatat di audit log
+type Actor struct {
+	ID   primitive.ObjectID
+	Name string
+	Role string
+	IP   string
+}
+
+// ActorOf mengambil pelaku dari user di context request (middleware auth) dan IP dari at.GetClientIP
+func ActorOf(req *http.Request) (actor Actor) {
+	if user, ok := rbac.CurrentUser(req); ok {
+		actor.ID, actor.Name, actor.Role = user.ID, user.Name, user.Role
+	}
+	if ip, err := at.GetClientIP(req); err == nil {
+		actor.IP = ip
+	}
+	return
+}
+
+// Record mencatat perubahan dokumen ke koleksi audit_log. Pelaku diambil dari user di context request
+// (middleware auth) dan IP dari at.GetClientIP. before nil untuk create, after nil untuk delete.
+// Kegagalan hanya dicatat ke log supaya perubahan data yang sudah tersimpan tidak ikut gagal.
+func Record(db *mongo.Database, req *http.Request, action, entity string, entityID primitive.ObjectID, before, after interface{}) {
+	RecordBy(db, ActorOf(req), action, entity, entityID, before, after)
+}
+
+// RecordBy sama dengan Record dengan pelaku yang ditentukan pemanggil, untuk perubahan tanpa user di context
+// seperti pesanan dari meja, lapak dan bot WhatsApp atau callback payment provider.
+func RecordBy(db *mongo.Database, actor Actor, action, entity string, entityID primitive.ObjectID, before, after interface{}) {
+	insert(db, actor, model.AuditLog{Action: action, Entity: entity, EntityID: entityID, Changes: Diff(before, after)})
+}
+
+// RecordKey sama dengan Record untuk dokumen yang _id-nya string, misalnya role_permission dan pengaturan toko
+func RecordKey(db *mongo.Database, req *http.Request, action, entity, key string, before, after interface{}) {
+	insert(db, ActorOf(req), model.AuditLog{Action: action, Entity: entity, EntityKey: key, Changes: Diff(before, after)})
+}
+
+func insert(db *mongo.Database, actor Actor, entry model.AuditLog) {
+	entry.ActorID, entry.Actor, entry.Role, entry.IP = actor.ID, actor.Name, actor.Role, actor.IP
+	entry.CreatedAt = time.Now()
+	if _, err := atdb.InsertOneDoc(db, Collection, entry); err != nil {
+		id := entry.EntityKey
+		if id == "" {
+			id = entry.EntityID.Hex()
+		}
+		log.Println("Gagal mencatat audit " + entry.Action + " " + entry.Entity + " " + id + ": " + err.Error())
+	}
+}
+
+// Diff membandingkan field level teratas dua dokumen (struct atau bson.M) dalam bentuk bson,
+// hasilnya hanya field yang berubah dan diurutkan berdasarkan nama field. _id tidak ikut dibandingkan.
+func Diff(before, after interface{}) []model.AuditChange {
+	prev, next := toMap(before), toMap(after)
+	fields := make(map[string]bool, len(prev)+len(next))
+	for field := range prev {
+		fields[field] = true
+	}
+	for field := range next {
+		fields[field] = true
+	}
+	delete(fields, "_id")
+
+	var changes []model.AuditChange
+	for field := range fields {
+		if reflect.DeepEqual(prev[field], next[field]) {
+			continue
+		}
+		changes = append(changes, model.AuditChange{Field: field, Before: prev[field], After: next[field]})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// toMap mengubah dokumen menjadi bson.M lewat marshal bson, supaya tag bson dan omitempty ikut berlaku
+func toMap(doc interface{}) bson.M {
+	if doc == nil || (reflect.ValueOf(doc).Kind() == reflect.Ptr && reflect.ValueOf(doc).IsNil()) {
+		return bson.M{}
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return bson.M{}
+	}
+	m := bson.M{}
+	if err := bson.Unmarshal(raw, &m); err != nil {
+		return bson.M{}
+	}
+	return m
+}
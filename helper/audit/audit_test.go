@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDiff(t *testing.T) {
+	id := primitive.NewObjectID()
+	before := model.Menu{ID: id, Name: "Kopi Susu", Price: 18000, Status: "tersedia",
+		Modifiers: []model.ModifierGroup{{Name: "Ukuran", Type: model.ModifierSingle}}}
+	after := before
+	after.Price = 20000
+	after.Description = "Kopi susu gula aren"
+
+	changes := Diff(before, after)
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v, want 2", changes)
+	}
+	if c := changes[0]; c.Field != "description" || c.Before != nil || c.After != "Kopi susu gula aren" {
+		t.Errorf("changes[0] = %+v", c)
+	}
+	if c := changes[1]; c.Field != "price" || c.Before != 18000.0 || c.After != 20000.0 {
+		t.Errorf("changes[1] = %+v", c)
+	}
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	category := model.Category{ID: primitive.NewObjectID(), Name: "Kopi"}
+	if changes := Diff(nil, category); len(changes) != 1 || changes[0].Field != "name" || changes[0].After != "Kopi" {
+		t.Errorf("create changes = %+v", changes)
+	}
+	if changes := Diff(category, nil); len(changes) != 1 || changes[0].Before != "Kopi" || changes[0].After != nil {
+		t.Errorf("delete changes = %+v", changes)
+	}
+}
@@ -0,0 +1,327 @@
+package checkout
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/delivery"
+	"github.com/gocroot/helper/kitchen"
+	"github.com/gocroot/helper/loyalty"
+	"github.com/gocroot/helper/notif"
+	"github.com/gocroot/helper/payment"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/phone"
+	"github.com/gocroot/helper/pickup"
+	"github.com/gocroot/helper/voucher"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Input pesanan dari client beserta pembuatnya
+type Input struct {
+	Order    model.Order         // body pesanan dari client, harga dan total dari client tidak dipakai
+	User     model.Userdomyikado // pembuat pesanan, dicatat sebagai CreatedBy dan riwayat status
+	Customer string              // nomor WhatsApp terverifikasi pemilik pesanan, kosong jika tidak ada (meja, lapak, kasir)
+	Staff    bool                // pembuat pesanan staf di toko yang boleh menukar poin atas nama pelanggan
+	Provider payment.Provider    // payment provider untuk metode non-tunai, nil untuk Cash
+	Actor    audit.Actor         // pelaku yang dicatat di audit log beserta IP client
+}
+
+// Result pesanan yang sudah tersimpan beserta selisih harga dengan harga dari client
+type Result struct {
+	Order         model.Order
+	ItemsTotal    float64 // total harga item dari koleksi menu, sebelum voucher, poin dan ongkir
+	Discrepancies []model.PriceDiscrepancy
+}
+
+// Error kegagalan membuat pesanan beserta kode HTTP dan status untuk client
+type Error struct {
+	Code   int
+	Status string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return e.Status + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func fail(code int, status string, err error) *Error {
+	return &Error{Code: code, Status: status, Err: err}
+}
+
+// Submit memproses pesanan: validasi, hitung harga dari menu, stasiun dapur, antar, slot waktu ambil, voucher, poin,
+// tagihan pembayaran, nomor antrean, simpan ke orders dan audit log, lalu kirim notifikasi. Dipakai order web, order lapak dan bot WhatsApp.
+// Kuota voucher, poin dan slot yang sudah diklaim dikembalikan jika pesanan gagal dibuat. geo boleh nil.
+func Submit(db, geo *mongo.Database, in Input) (result Result, err error) {
+	order, user := in.Order, in.User
+	if order.UserInfo.Name == "" || order.UserInfo.Whatsapp == "" {
+		err = fail(http.StatusBadRequest, "Error: Bad Request", errors.New("UserInfo harus berisi Name dan Whatsapp"))
+		return
+	}
+
+	// Harga dan total selalu dihitung ulang dari koleksi menu
+	pricedItems, total, discrepancies, err := pesanan.PriceOrderItems(db, order.Orders)
+	if errors.Is(err, pesanan.ErrOrderItemInvalid) {
+		err = fail(http.StatusBadRequest, "Error: Item Pesanan Tidak Valid", err)
+		return
+	} else if err != nil {
+		err = fail(http.StatusInternalServerError, "Error: Gagal mengambil data menu", err)
+		return
+	}
+
+	// Stasiun persiapan dicatat saat order dibuat, tiket tidak berpindah stasiun jika kategori diubah kemudian
+	pricedItems, err = kitchen.AssignStations(db, pricedItems)
+	if err != nil {
+		err = fail(http.StatusInternalServerError, "Error: Gagal mengambil stasiun menu", err)
+		return
+	}
+
+	location, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		err = fail(http.StatusInternalServerError, "Error: Gagal memuat zona waktu Indonesia", err)
+		return
+	}
+	currentTimeInID := time.Now().In(location)
+
+	// ID dibuat di sini karena ledger poin dan tagihan pembayaran membutuhkannya,
+	// nomor antrean baru diambil setelah semua pengecekan lolos
+	newOrder := model.Order{
+		ID:        primitive.NewObjectID(),
+		OrderDate: currentTimeInID,
+		UserID:    user.ID,
+		Customer:  in.Customer,
+		UserInfo: model.UserInfo{
+			Name:     order.UserInfo.Name,
+			Whatsapp: order.UserInfo.Whatsapp,
+			Note:     order.UserInfo.Note,
+		},
+		Orders:        pricedItems,
+		Total:         total,
+		PaymentMethod: order.PaymentMethod,
+		Status:        model.OrderStatusTerkirim,
+		TableID:       order.TableID,
+		TableNumber:   order.TableNumber,
+		CreatedBy:     user.Name,
+		CreatedByRole: user.Role,
+		StatusHistory: []model.OrderStatusHistory{{
+			To:   model.OrderStatusTerkirim,
+			By:   user.Name,
+			Role: user.Role,
+			At:   currentTimeInID,
+		}},
+	}
+
+	// Pesanan antar: titik pelanggan harus di dalam zona aktif, ongkir dihitung dari jarak ke toko
+	switch order.Type {
+	case "":
+	case model.OrderTypeDelivery:
+		if !order.TableID.IsZero() || !order.PickupAt.IsZero() {
+			err = fail(http.StatusBadRequest, "Error: Pesanan Antar Tidak Valid", errors.New("pesanan antar tidak bisa dari meja atau memakai waktu ambil"))
+			return
+		}
+		if order.Delivery == nil || strings.TrimSpace(order.Delivery.Address) == "" {
+			err = fail(http.StatusBadRequest, "Error: Pesanan Antar Tidak Valid", errors.New("delivery harus berisi address, long dan lat"))
+			return
+		}
+		order.Delivery.Address = strings.TrimSpace(order.Delivery.Address)
+		quote, errQuote := delivery.Quote(db, geo, *order.Delivery)
+		if err = DeliveryError(errQuote); err != nil {
+			return
+		}
+		newOrder.Type = model.OrderTypeDelivery
+		newOrder.Delivery = &quote
+	default:
+		err = fail(http.StatusBadRequest, "Error: Jenis Pesanan Tidak Valid", errors.New("type hanya diperbolehkan kosong atau '"+model.OrderTypeDelivery+"'"))
+		return
+	}
+
+	// Pesanan terjadwal memakai kuota minuman slot waktu ambil, antrean aktif baru menampilkannya menjelang waktu ambil
+	if !order.PickupAt.IsZero() {
+		if !order.TableID.IsZero() {
+			err = fail(http.StatusBadRequest, "Error: Waktu Ambil Tidak Valid", errors.New("pesanan dari meja tidak bisa dijadwalkan"))
+			return
+		}
+		schedule, errSchedule := pickup.GetSchedule(db)
+		if errSchedule != nil {
+			err = fail(http.StatusInternalServerError, "Error: Gagal mengambil jadwal slot", errSchedule)
+			return
+		}
+		slot, errSlot := pickup.SlotFor(schedule, order.PickupAt, location, currentTimeInID)
+		if errSlot == nil {
+			newOrder.PickupDrinks = pickup.Drinks(pricedItems)
+			errSlot = pickup.Claim(db, slot, newOrder.PickupDrinks)
+		}
+		if errors.Is(errSlot, pickup.ErrSlotInvalid) || errors.Is(errSlot, pickup.ErrSlotFull) {
+			err = fail(http.StatusConflict, "Error: Waktu Ambil Tidak Tersedia", errSlot)
+			return
+		} else if errSlot != nil {
+			err = fail(http.StatusInternalServerError, "Error: Gagal memesan slot waktu ambil", errSlot)
+			return
+		}
+		if slot.Capacity == 0 {
+			newOrder.PickupDrinks = 0 // slot tidak dibatasi, tidak ada kuota yang perlu dikembalikan
+		}
+		newOrder.PickupAt = slot.Start
+		newOrder.QueueAt = pickup.QueueAt(schedule, slot.Start)
+	}
+
+	// mulai dari sini kuota slot, voucher dan poin yang sudah diklaim dikembalikan jika pesanan gagal dibuat
+	defer func() {
+		if err != nil {
+			Release(db, newOrder)
+		}
+	}()
+
+	// Potongan voucher dihitung dari harga server, kuota voucher diklaim sebelum order disimpan
+	if order.VoucherCode != "" {
+		discount, errVoucher := voucher.Apply(db, order.VoucherCode, pricedItems, in.Customer, currentTimeInID)
+		if errors.Is(errVoucher, voucher.ErrVoucherInvalid) {
+			err = fail(http.StatusBadRequest, "Error: Voucher Tidak Valid", errVoucher)
+			return
+		} else if errVoucher != nil {
+			err = fail(http.StatusInternalServerError, "Error: Gagal memproses voucher", errVoucher)
+			return
+		}
+		newOrder.VoucherCode = discount.Code
+		newOrder.Subtotal = total
+		newOrder.Discount = &discount
+		newOrder.Total = total - discount.Amount
+	}
+
+	// Penukaran poin loyalitas dipotong setelah voucher, hanya oleh pemilik nomor WhatsApp atau kasir di toko
+	if order.RedeemPoints != 0 || !order.RedeemMenuID.IsZero() {
+		customer := phone.NormalizePhoneNumber(newOrder.UserInfo.Whatsapp)
+		if !in.Staff && (in.Customer == "" || in.Customer != customer) {
+			err = fail(http.StatusForbidden, "Error: Poin Tidak Dapat Ditukar", errors.New("poin hanya bisa ditukar oleh pemilik nomor WhatsApp pada pesanan"))
+			return
+		}
+		points, pointsDiscount, errPoints := loyalty.Quote(db, pricedItems, newOrder.Total, order.RedeemPoints, order.RedeemMenuID)
+		if errPoints == nil {
+			errPoints = loyalty.Burn(db, customer, points, newOrder)
+		}
+		if errors.Is(errPoints, loyalty.ErrPointsInvalid) {
+			err = fail(http.StatusBadRequest, "Error: Poin Tidak Dapat Ditukar", errPoints)
+			return
+		} else if errPoints != nil {
+			err = fail(http.StatusInternalServerError, "Error: Poin Tidak Dapat Ditukar", errPoints)
+			return
+		}
+		newOrder.Points = &model.OrderPoints{Redeemed: points, Discount: pointsDiscount, RewardMenuID: order.RedeemMenuID}
+		newOrder.Subtotal = total
+		newOrder.Total -= pointsDiscount
+	}
+
+	// Ongkir tidak ikut dipotong voucher atau poin
+	if newOrder.Delivery != nil {
+		newOrder.Subtotal = total
+		newOrder.Total += newOrder.Delivery.Fee
+	}
+
+	// Buat tagihan ke payment provider, dapur baru memproses setelah lunas
+	newOrder.PaymentStatus = model.PaymentStatusBelumDibayar
+	if in.Provider != nil {
+		intent, errIntent := in.Provider.CreateIntent(newOrder.ID.Hex(), newOrder.Total)
+		if errIntent != nil {
+			err = fail(http.StatusBadGateway, "Error: Gagal membuat tagihan pembayaran", errIntent)
+			return
+		}
+		newOrder.PaymentStatus = model.PaymentStatusMenunggu
+		newOrder.PaymentInfo = &model.PaymentInfo{
+			Provider:  intent.Provider,
+			Reference: intent.Reference,
+			Amount:    intent.Amount,
+			QRString:  intent.QRString,
+			ExpiresAt: intent.ExpiresAt,
+		}
+	}
+
+	// Nomor antrean diambil paling akhir, supaya pesanan yang ditolak tidak membuat nomor antrean bolong
+	newOrder.OrderNumber, newOrder.QueueNumber, err = pesanan.GenerateOrderNumber(db)
+	if err != nil {
+		err = fail(http.StatusInternalServerError, "Error: Gagal membuat nomor antrean", err)
+		return
+	}
+	if newOrder.Points != nil {
+		if errLedger := loyalty.SetOrderNumber(db, newOrder); errLedger != nil {
+			log.Println("Gagal mencatat nomor order " + newOrder.OrderNumber + " pada ledger poin: " + errLedger.Error())
+		}
+	}
+
+	// Simpan order baru ke database, nomor antrean yang sudah diambil dipakai ulang saat insert diulang
+	if err = insertOrder(db, newOrder); err != nil {
+		err = fail(http.StatusInternalServerError, "Error: Gagal Insert Database", err)
+		return
+	}
+	audit.RecordBy(db, in.Actor, model.AuditCreate, "orders", newOrder.ID, nil, newOrder)
+
+	if newOrder.Discount != nil {
+		if errVoucher := voucher.Redeem(db, newOrder); errVoucher != nil {
+			log.Println("Gagal mencatat pemakaian voucher order " + newOrder.OrderNumber + ": " + errVoucher.Error())
+		}
+	}
+
+	// Kirim notifikasi WhatsApp ke pelanggan, kegagalan kirim tidak menggagalkan order
+	notif.SendOrderStatusAsync(newOrder, db)
+
+	return Result{Order: newOrder, ItemsTotal: total, Discrepancies: discrepancies}, nil
+}
+
+// insertOrderAttempts - Jumlah percobaan menyimpan order dengan nomor antrean yang sama
+const insertOrderAttempts = 3
+
+// insertOrder menyimpan order dan mengulang insert dengan _id dan nomor antrean yang sama jika gagal,
+// supaya gangguan sesaat ke MongoDB tidak membuat nomor antrean bolong. Duplicate key pada _id berarti
+// percobaan sebelumnya sudah tersimpan walaupun balasannya hilang. Nomor antrean hanya terlewat jika
+// semua percobaan gagal.
+func insertOrder(db *mongo.Database, order model.Order) (err error) {
+	for attempt := 0; attempt < insertOrderAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 200 * time.Millisecond)
+		}
+		_, err = atdb.InsertOneDoc(db, "orders", order)
+		if err == nil || (attempt > 0 && mongo.IsDuplicateKeyError(err)) {
+			return nil
+		}
+	}
+	return err
+}
+
+// DeliveryError memetakan error delivery.Quote ke Error dengan kode HTTP, nil jika quote berhasil
+func DeliveryError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, delivery.ErrOutOfArea):
+		return fail(http.StatusBadRequest, "Error: Di Luar Area Antar", err)
+	case errors.Is(err, delivery.ErrNotConfigured):
+		return fail(http.StatusServiceUnavailable, "Error: Layanan Antar Belum Tersedia", err)
+	}
+	return fail(http.StatusInternalServerError, "Error: Gagal menghitung ongkir", err)
+}
+
+// Release mengembalikan kuota slot waktu ambil, kuota voucher dan poin yang sudah ditukar jika order gagal dibuat
+func Release(db *mongo.Database, order model.Order) {
+	if err := pickup.Release(db, order); err != nil {
+		log.Println("Gagal mengembalikan kuota slot " + pickup.Key(order.PickupAt) + ": " + err.Error())
+	}
+	if order.Discount != nil {
+		if err := voucher.Release(db, order.Discount.VoucherID, order.Discount.Customer); err != nil {
+			log.Println("Gagal mengembalikan kuota voucher " + order.Discount.Code + ": " + err.Error())
+		}
+	}
+	if order.Points != nil {
+		if err := loyalty.Reverse(db, order); err != nil {
+			log.Println("Gagal mengembalikan poin order " + order.ID.Hex() + ": " + err.Error())
+		}
+	}
+}
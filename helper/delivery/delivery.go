@@ -0,0 +1,197 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collection nama koleksi zona layanan antar
+const Collection = "delivery_zone"
+
+// SettingsCollection koleksi titik toko dan tarif ongkir, hanya berisi satu dokumen dengan _id settingsID
+const SettingsCollection = "delivery_settings"
+
+const settingsID = "default"
+
+// earthRadiusKm jari-jari rata-rata bumi untuk rumus haversine
+const earthRadiusKm = 6371.0
+
+// ErrOutOfArea - Titik pelanggan di luar semua zona aktif atau lebih jauh dari band ongkir terjauh
+var ErrOutOfArea = errors.New("alamat di luar area layanan antar")
+
+// ErrNotConfigured - Admin belum mengisi titik toko atau tarif ongkir
+var ErrNotConfigured = errors.New("layanan antar belum diatur")
+
+// ValidPoint - Mengecek koordinat GeoJSON [long, lat]
+func ValidPoint(long, lat float64) bool {
+	return long >= -180 && long <= 180 && lat >= -90 && lat <= 90 && !(long == 0 && lat == 0)
+}
+
+// ValidateZone - Mengecek isian zona sebelum disimpan oleh admin. Area harus Polygon GeoJSON
+// dengan ring tertutup (titik pertama sama dengan titik terakhir) minimal empat titik.
+func ValidateZone(z model.DeliveryZone) error {
+	if z.Name == "" {
+		return errors.New("nama zona wajib diisi")
+	}
+	if z.Area.Type != "Polygon" {
+		return errors.New("area harus GeoJSON bertipe Polygon")
+	}
+	if len(z.Area.Coordinates) == 0 {
+		return errors.New("koordinat area wajib diisi")
+	}
+	for i, ring := range z.Area.Coordinates {
+		if len(ring) < 4 {
+			return fmt.Errorf("ring %d minimal 4 titik", i)
+		}
+		for _, position := range ring {
+			if len(position) != 2 || !ValidPoint(position[0], position[1]) {
+				return fmt.Errorf("ring %d berisi koordinat tidak valid, gunakan [long, lat]", i)
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Errorf("ring %d harus tertutup, titik terakhir sama dengan titik pertama", i)
+		}
+	}
+	return nil
+}
+
+// ValidateSettings - Mengecek titik toko dan tarif sebelum disimpan. Band diurutkan dari jarak terdekat,
+// tanpa band berarti layanan antar dimatikan.
+func ValidateSettings(s model.DeliverySettings) error {
+	if len(s.Bands) == 0 {
+		return nil
+	}
+	if !ValidPoint(s.Origin.Longitude, s.Origin.Latitude) {
+		return errors.New("titik toko (origin) wajib diisi dengan long dan lat yang valid")
+	}
+	previous := 0.0
+	for i, band := range s.Bands {
+		if band.MaxKm <= previous {
+			return fmt.Errorf("max_km band %d harus lebih besar dari band sebelumnya", i)
+		}
+		if band.Fee < 0 {
+			return fmt.Errorf("ongkir band %d tidak boleh minus", i)
+		}
+		previous = band.MaxKm
+	}
+	return nil
+}
+
+// GetSettings mengambil pengaturan dari database, pengaturan kosong (layanan antar mati) jika belum ada
+func GetSettings(db *mongo.Database) (model.DeliverySettings, error) {
+	s, err := atdb.GetOneDoc[model.DeliverySettings](db, SettingsCollection, bson.M{"_id": settingsID})
+	if err == mongo.ErrNoDocuments {
+		return model.DeliverySettings{ID: settingsID, Bands: []model.DeliveryBand{}}, nil
+	}
+	return s, err
+}
+
+// SaveSettings menyimpan pengaturan setelah divalidasi
+func SaveSettings(db *mongo.Database, s model.DeliverySettings) (model.DeliverySettings, error) {
+	s.ID = settingsID
+	s.Origin.MaxDistance = 0
+	_, err := atdb.ReplaceOneDoc(db, SettingsCollection, bson.M{"_id": settingsID}, s)
+	return s, err
+}
+
+// Distance jarak garis lurus dalam kilometer antara dua titik (haversine)
+func Distance(from, to model.LongLat) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(to.Latitude - from.Latitude)
+	dLong := rad(to.Longitude - from.Longitude)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(from.Latitude))*math.Cos(rad(to.Latitude))*math.Sin(dLong/2)*math.Sin(dLong/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// Fee ongkir untuk jarak km dari band pertama yang mencakupnya, ok false jika lebih jauh dari band terjauh
+func Fee(bands []model.DeliveryBand, km float64) (fee float64, ok bool) {
+	for _, band := range bands {
+		if km <= band.MaxKm {
+			return band.Fee, true
+		}
+	}
+	return 0, false
+}
+
+// pointFilter filter $geoIntersects untuk satu titik pada field GeoJSON, sama dengan pencarian region di GIS
+func pointFilter(field string, long, lat float64) bson.M {
+	return bson.M{
+		field: bson.M{
+			"$geoIntersects": bson.M{
+				"$geometry": bson.M{
+					"type":        "Point",
+					"coordinates": []float64{long, lat},
+				},
+			},
+		},
+	}
+}
+
+// ZoneAt mencari zona aktif yang memuat titik pelanggan, ErrOutOfArea jika tidak ada
+func ZoneAt(db *mongo.Database, long, lat float64) (model.DeliveryZone, error) {
+	filter := pointFilter("area", long, lat)
+	filter["active"] = true
+	zone, err := atdb.GetOneDoc[model.DeliveryZone](db, Collection, atdb.ExcludeDeleted(filter))
+	if err == mongo.ErrNoDocuments {
+		return zone, fmt.Errorf("%w: tidak ada zona antar di titik %.6f, %.6f", ErrOutOfArea, lat, long)
+	}
+	return zone, err
+}
+
+// RegionAt mencari provinsi sampai desa dari koleksi region GIS
+func RegionAt(geo *mongo.Database, long, lat float64) (model.Region, error) {
+	return atdb.GetOneDoc[model.Region](geo, "region", pointFilter("border", long, lat))
+}
+
+// Quote mengecek titik pelanggan terhadap zona dan band ongkir lalu mengisi zona, jarak, ongkir dan wilayah.
+// Wilayah hanya pelengkap alamat, sehingga database GIS yang tidak tersedia (geo nil) atau titik tanpa region tidak menggagalkan quote.
+func Quote(db, geo *mongo.Database, input model.OrderDelivery) (model.OrderDelivery, error) {
+	if !ValidPoint(input.Longitude, input.Latitude) {
+		return input, fmt.Errorf("%w: koordinat long dan lat tidak valid", ErrOutOfArea)
+	}
+	settings, err := GetSettings(db)
+	if err != nil {
+		return input, err
+	}
+	if len(settings.Bands) == 0 {
+		return input, ErrNotConfigured
+	}
+	zone, err := ZoneAt(db, input.Longitude, input.Latitude)
+	if err != nil {
+		return input, err
+	}
+	point := model.LongLat{Longitude: input.Longitude, Latitude: input.Latitude}
+	km := math.Round(Distance(settings.Origin, point)*100) / 100
+	fee, ok := Fee(settings.Bands, km)
+	if !ok {
+		return input, fmt.Errorf("%w: jarak %.2f km melebihi batas antar %.2f km", ErrOutOfArea, km, settings.Bands[len(settings.Bands)-1].MaxKm)
+	}
+
+	quote := model.OrderDelivery{
+		Address:    input.Address,
+		Note:       input.Note,
+		Longitude:  input.Longitude,
+		Latitude:   input.Latitude,
+		ZoneID:     zone.ID,
+		ZoneName:   zone.Name,
+		DistanceKm: km,
+		Fee:        fee,
+	}
+	if geo != nil {
+		if region, err := RegionAt(geo, input.Longitude, input.Latitude); err == nil {
+			quote.Province = region.Province
+			quote.District = region.District
+			quote.SubDistrict = region.SubDistrict
+			quote.Village = region.Village
+		}
+	}
+	return quote, nil
+}
@@ -0,0 +1,70 @@
+package delivery
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gocroot/model"
+)
+
+func TestDistanceAndFee(t *testing.T) {
+	// Gedung Sate ke Alun-alun Bandung sekitar 2,5 km
+	from := model.LongLat{Longitude: 107.6186, Latitude: -6.9025}
+	to := model.LongLat{Longitude: 107.6071, Latitude: -6.9218}
+	if km := Distance(from, to); math.Abs(km-2.48) > 0.1 {
+		t.Errorf("Distance = %.2f km, want sekitar 2.48", km)
+	}
+	if km := Distance(from, from); km != 0 {
+		t.Errorf("Distance titik yang sama = %f", km)
+	}
+
+	bands := []model.DeliveryBand{{MaxKm: 2, Fee: 5000}, {MaxKm: 5, Fee: 10000}}
+	for km, want := range map[float64]float64{0.5: 5000, 2: 5000, 2.01: 10000, 5: 10000} {
+		if fee, ok := Fee(bands, km); !ok || fee != want {
+			t.Errorf("Fee(%.2f) = %.0f %v, want %.0f", km, fee, ok, want)
+		}
+	}
+	if _, ok := Fee(bands, 5.5); ok {
+		t.Error("jarak di luar band terjauh seharusnya ditolak")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	zone := model.DeliveryZone{
+		Name: "Bandung Tengah",
+		Area: model.Location{Type: "Polygon", Coordinates: [][][]float64{{
+			{107.60, -6.95}, {107.65, -6.95}, {107.65, -6.88}, {107.60, -6.88}, {107.60, -6.95},
+		}}},
+	}
+	if err := ValidateZone(zone); err != nil {
+		t.Errorf("zona valid ditolak: %v", err)
+	}
+	open := zone
+	open.Area.Coordinates = [][][]float64{zone.Area.Coordinates[0][:4]}
+	if ValidateZone(open) == nil {
+		t.Error("ring yang tidak tertutup seharusnya ditolak")
+	}
+	swapped := zone
+	swapped.Area.Coordinates = [][][]float64{{{-6.95, 107.60}, {-6.95, 107.65}, {-6.88, 107.65}, {-6.95, 107.60}}}
+	if ValidateZone(swapped) == nil {
+		t.Error("koordinat [lat, long] seharusnya ditolak")
+	}
+
+	settings := model.DeliverySettings{
+		Origin: model.LongLat{Longitude: 107.6186, Latitude: -6.9025},
+		Bands:  []model.DeliveryBand{{MaxKm: 2, Fee: 5000}, {MaxKm: 5, Fee: 10000}},
+	}
+	if err := ValidateSettings(settings); err != nil {
+		t.Errorf("pengaturan valid ditolak: %v", err)
+	}
+	settings.Bands = []model.DeliveryBand{{MaxKm: 5, Fee: 10000}, {MaxKm: 2, Fee: 5000}}
+	if ValidateSettings(settings) == nil {
+		t.Error("band yang tidak urut seharusnya ditolak")
+	}
+	if ValidateSettings(model.DeliverySettings{Bands: []model.DeliveryBand{{MaxKm: 2}}}) == nil {
+		t.Error("band tanpa titik toko seharusnya ditolak")
+	}
+	if err := ValidateSettings(model.DeliverySettings{}); err != nil {
+		t.Errorf("pengaturan kosong (antar mati) ditolak: %v", err)
+	}
+}
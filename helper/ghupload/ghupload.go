@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/google/go-github/v59/github"
 
@@ -32,10 +33,13 @@ func GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail string,
 		Message: github.String("Upload file"),
 		Content: fileContent,
 		Branch:  github.String("main"),
-		Author: &github.CommitAuthor{
+	}
+	// tanpa author, commit tercatat atas nama pemilik token
+	if GitHubAuthorName != "" {
+		opts.Author = &github.CommitAuthor{
 			Name:  github.String(GitHubAuthorName),
 			Email: github.String(GitHubAuthorEmail),
-		},
+		}
 	}
 
 	content, response, err = client.Repositories.CreateFile(ctx, githubOrg, githubRepo, pathFile, opts)
@@ -80,3 +84,28 @@ func GithubGetFile(GitHubAccessToken, githubOrg, githubRepo, pathFile string) (f
 
 	return
 }
+
+// Function to delete file from GitHub repository, file yang sudah tidak ada dianggap berhasil dihapus
+func GithubDelete(GitHubAccessToken, githubOrg, githubRepo, pathFile string) (err error) {
+	ctx := context.Background()
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: GitHubAccessToken},
+	)
+	tc := oauth2.NewClient(ctx, ts)
+	client := github.NewClient(tc)
+
+	currentContent, _, response, err := client.Repositories.GetContents(ctx, githubOrg, githubRepo, pathFile, nil)
+	if response != nil && response.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	opts := &github.RepositoryContentFileOptions{
+		Message: github.String("Delete file"),
+		SHA:     github.String(currentContent.GetSHA()),
+		Branch:  github.String("main"),
+	}
+	_, _, err = client.Repositories.DeleteFile(ctx, githubOrg, githubRepo, pathFile, opts)
+	return
+}
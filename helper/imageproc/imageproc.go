@@ -0,0 +1,156 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // daftarkan decoder WebP untuk image.Decode
+)
+
+// Batas gambar yang diterima dan ukuran gambar utama setelah diproses
+const (
+	MaxUploadSize = 10 << 20   // 10 MB, foto kamera HP biasanya 3-8 MB
+	MaxPixels     = 50_000_000 // mencegah decompression bomb, foto 48 MP masih diterima
+	MaxSide       = 1600       // sisi terpanjang gambar utama
+	JPEGQuality   = 85
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("format gambar harus JPEG, PNG atau WebP")
+	ErrTooLarge          = errors.New("ukuran gambar terlalu besar")
+)
+
+// Variant ukuran gambar turunan, dipotong di tengah sesuai rasio Width:Height lalu disimpan sebagai JPEG (atau PNG),
+// ditambah WebP jika ukurannya lebih kecil
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// Variant standar
+var (
+	Thumb  = Variant{Name: "thumb", Width: 160, Height: 160}   // ikon daftar dan keranjang, 1:1
+	Card   = Variant{Name: "card", Width: 480, Height: 360}    // kartu menu, 4:3
+	Banner = Variant{Name: "banner", Width: 1200, Height: 400} // banner promo, 3:1
+)
+
+// Variant yang dibuat per jenis gambar
+var (
+	MenuVariants     = []Variant{Thumb, Card}
+	CategoryVariants = []Variant{Thumb, Card}
+	BannerVariants   = []Variant{Thumb, Banner}
+)
+
+// Image gambar yang sudah didecode, diputar sesuai EXIF orientation dan diperkecil ke MaxSide
+type Image struct {
+	img *image.RGBA
+}
+
+// Decode memvalidasi format dari isi file (bukan ekstensi), lalu mendecode gambar.
+// Metadata (EXIF, lokasi GPS, profil kamera) tidak ikut karena gambar selalu diencode ulang.
+func Decode(content []byte) (Image, error) {
+	if len(content) > MaxUploadSize {
+		return Image{}, ErrTooLarge
+	}
+	switch http.DetectContentType(content) {
+	case "image/jpeg", "image/png", "image/webp":
+	default:
+		return Image{}, ErrUnsupportedFormat
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return Image{}, err
+	}
+	if config.Width < 1 || config.Height < 1 || config.Width*config.Height > MaxPixels {
+		return Image{}, ErrTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return Image{}, err
+	}
+
+	// perkecil dulu sebelum diputar supaya rotasi tidak dikerjakan pada jutaan piksel
+	orientation := Orientation(content)
+	w, h := fit(src.Bounds().Dx(), src.Bounds().Dy(), MaxSide)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w == src.Bounds().Dx() && h == src.Bounds().Dy() {
+		draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Src)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+	}
+	return Image{img: orient(dst, orientation)}, nil
+}
+
+// Bounds ukuran gambar utama
+func (i Image) Bounds() image.Rectangle {
+	return i.img.Bounds()
+}
+
+// Encode gambar utama: JPEG untuk foto, PNG jika ada bagian transparan. ext diawali titik.
+func (i Image) Encode() (content []byte, contentType, ext string, err error) {
+	return Encode(i.img)
+}
+
+// Encode gambar apa saja (gambar utama atau hasil Resize) ke JPEG lossy, atau PNG jika ada bagian transparan
+func Encode(img image.Image) (content []byte, contentType, ext string, err error) {
+	var buf bytes.Buffer
+	if opaque, ok := img.(interface{ Opaque() bool }); !ok || opaque.Opaque() {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: JPEGQuality})
+		return buf.Bytes(), "image/jpeg", ".jpg", err
+	}
+	err = png.Encode(&buf, img)
+	return buf.Bytes(), "image/png", ".png", err
+}
+
+// Resize memotong gambar di tengah sesuai rasio variant lalu mengubah ukurannya menjadi Width*scale x Height*scale.
+// ok false jika gambar terlalu kecil untuk scale tersebut (scale 1 selalu dibuat walaupun harus diperbesar).
+func (i Image) Resize(v Variant, scale int) (img image.Image, ok bool) {
+	crop := cover(i.img.Bounds(), v.Width, v.Height)
+	w, h := v.Width*scale, v.Height*scale
+	if scale > 1 && (crop.Dx() < w || crop.Dy() < h) {
+		return nil, false
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), i.img, crop, draw.Src, nil)
+	return dst, true
+}
+
+// EncodeWebP mengencode gambar ke WebP lossless, hanya ada encoder lossless tanpa cgo.
+// Untuk foto hasilnya sering lebih besar dari JPEG, jadi WebP hanya dipakai sebagai alternatif yang lebih kecil.
+func EncodeWebP(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit ukuran baru dengan sisi terpanjang maksimal limit, rasio dipertahankan dan tidak diperbesar
+func fit(w, h, limit int) (int, int) {
+	if w <= limit && h <= limit {
+		return w, h
+	}
+	if w >= h {
+		return limit, max(1, h*limit/w)
+	}
+	return max(1, w*limit/h), limit
+}
+
+// cover area terbesar di tengah bounds dengan rasio w:h
+func cover(bounds image.Rectangle, w, h int) image.Rectangle {
+	bw, bh := bounds.Dx(), bounds.Dy()
+	cw, ch := bw, bw*h/w
+	if ch > bh {
+		cw, ch = bh*w/h, bh
+	}
+	x := bounds.Min.X + (bw-cw)/2
+	y := bounds.Min.Y + (bh-ch)/2
+	return image.Rect(x, y, x+cw, y+ch)
+}
@@ -0,0 +1,115 @@
+package imageproc
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// jpegWithOrientation membuat JPEG w x h dengan segmen EXIF berisi tag Orientation
+func jpegWithOrientation(t *testing.T, w, h, orientation int) []byte {
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			src.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, nil); err != nil {
+		t.Fatal(err)
+	}
+	// TIFF big endian, IFD0 di offset 8 dengan satu entry Orientation (SHORT)
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, byte(orientation), 0, 0, 0, 0, 0, 0}
+	exif := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(exif) + 2) >> 8), byte(len(exif) + 2)}, exif...)
+	jpg := buf.Bytes()
+	return append(append(append([]byte{}, jpg[:2]...), segment...), jpg[2:]...)
+}
+
+func TestDecodeAppliesOrientation(t *testing.T) {
+	content := jpegWithOrientation(t, 40, 20, 6)
+	if o := Orientation(content); o != 6 {
+		t.Fatalf("Orientation = %d, want 6", o)
+	}
+	img, err := Decode(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Errorf("bounds = %v, want 20x40 setelah diputar", b)
+	}
+	encoded, contentType, ext, err := img.Encode()
+	if err != nil || contentType != "image/jpeg" || ext != ".jpg" {
+		t.Fatalf("Encode = %q %q %v", contentType, ext, err)
+	}
+	if Orientation(encoded) != 1 {
+		t.Error("EXIF masih ada setelah diencode ulang")
+	}
+}
+
+func TestDecodeRejectsUnsupported(t *testing.T) {
+	if _, err := Decode([]byte("GIF89a........")); err != ErrUnsupportedFormat {
+		t.Errorf("err = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestDecodeLimitsSize(t *testing.T) {
+	img, err := Decode(jpegWithOrientation(t, 3200, 1000, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != MaxSide || b.Dy() != 500 {
+		t.Errorf("bounds = %v, want %dx500", b, MaxSide)
+	}
+}
+
+func TestResizeVariant(t *testing.T) {
+	img, err := Decode(jpegWithOrientation(t, 2000, 1500, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	card, ok := img.Resize(Card, 2)
+	if !ok {
+		t.Fatal("card@2x tidak dibuat")
+	}
+	if b := card.Bounds(); b.Dx() != 960 || b.Dy() != 720 {
+		t.Errorf("card@2x bounds = %v, want 960x720", b)
+	}
+	if _, ok := img.Resize(Banner, 2); ok {
+		t.Error("banner@2x tidak boleh dibuat dari gambar 1600px")
+	}
+	thumb, _ := img.Resize(Thumb, 1)
+	encoded, err := EncodeWebP(thumb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := webp.DecodeConfig(bytes.NewReader(encoded))
+	if err != nil || config.Width != 160 || config.Height != 160 {
+		t.Errorf("webp = %+v %v", config, err)
+	}
+}
+
+func TestEncodeVariant(t *testing.T) {
+	img, err := Decode(jpegWithOrientation(t, 2000, 1500, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	card, _ := img.Resize(Card, 1)
+	encoded, contentType, ext, err := Encode(card)
+	if err != nil || contentType != "image/jpeg" || ext != ".jpg" {
+		t.Fatalf("Encode foto = %q %q %v", contentType, ext, err)
+	}
+	config, err := jpeg.DecodeConfig(bytes.NewReader(encoded))
+	if err != nil || config.Width != 480 || config.Height != 360 {
+		t.Errorf("jpeg = %+v %v", config, err)
+	}
+
+	transparent := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	if _, contentType, ext, err = Encode(transparent); err != nil || contentType != "image/png" || ext != ".png" {
+		t.Errorf("Encode transparan = %q %q %v, want PNG", contentType, ext, err)
+	}
+}
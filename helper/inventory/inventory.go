@@ -0,0 +1,201 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrInsufficientStock = errors.New("stok bahan tidak mencukupi")
+
+// Requirements menghitung total bahan yang dibutuhkan untuk item pesanan berdasarkan resep tiap menu.
+// Menu tanpa resep tidak memakai stok.
+func Requirements(db *mongo.Database, items []model.OrderItem) (need map[primitive.ObjectID]float64, err error) {
+	var menuIDs []primitive.ObjectID
+	for _, item := range items {
+		menuIDs = append(menuIDs, item.MenuID)
+	}
+	menus, err := atdb.GetAllDoc[[]model.Menu](db, "menu", bson.M{"_id": bson.M{"$in": menuIDs}})
+	if err != nil {
+		return
+	}
+	recipes := make(map[primitive.ObjectID][]model.RecipeItem)
+	for _, menu := range menus {
+		recipes[menu.ID] = menu.Recipe
+	}
+	need = make(map[primitive.ObjectID]float64)
+	for _, item := range items {
+		for _, r := range recipes[item.MenuID] {
+			need[r.IngredientID] += r.Quantity * float64(item.Quantity)
+		}
+	}
+	return
+}
+
+// Consume mengurangi stok bahan untuk sebuah pesanan. Setiap bahan hanya dikurangi jika stoknya cukup,
+// jika ada satu bahan yang kurang maka pengurangan yang sudah terjadi dikembalikan lagi.
+func Consume(db *mongo.Database, order model.Order, by string) (err error) {
+	need, err := Requirements(db, order.Orders)
+	if err != nil {
+		return
+	}
+	var done []primitive.ObjectID
+	for ingredientID, quantity := range need {
+		_, err = applyChange(db, ingredientID, -quantity, "pesanan "+order.OrderNumber+" diproses", by, order.ID)
+		if err != nil {
+			for _, id := range done {
+				applyChange(db, id, need[id], "batal mengurangi stok pesanan "+order.OrderNumber, by, order.ID)
+			}
+			return
+		}
+		done = append(done, ingredientID)
+	}
+	return RefreshMenuAvailability(db, done)
+}
+
+// Restore mengembalikan stok bahan dari pesanan yang dibatalkan setelah diproses. Jumlah yang dikembalikan dihitung
+// dari pergerakan stok pesanan itu sendiri, bukan dari resep menu saat ini yang mungkin sudah diubah.
+// Bahan yang sudah pernah dikembalikan tidak dikembalikan lagi.
+func Restore(db *mongo.Database, order model.Order, by string) (err error) {
+	movements, err := atdb.GetAllDoc[[]model.StockMovement](db, "stock_movement", bson.M{"order_id": order.ID})
+	if err != nil {
+		return
+	}
+	net := make(map[primitive.ObjectID]float64)
+	var ingredientIDs []primitive.ObjectID
+	for _, m := range movements {
+		if _, ok := net[m.IngredientID]; !ok {
+			ingredientIDs = append(ingredientIDs, m.IngredientID)
+		}
+		net[m.IngredientID] += m.Change
+	}
+	var restored []primitive.ObjectID
+	for _, ingredientID := range ingredientIDs {
+		// selisih pembulatan float dari beberapa pergerakan tidak dianggap sisa stok yang dipakai
+		if net[ingredientID] > -1e-9 {
+			continue
+		}
+		if _, err = applyChange(db, ingredientID, -net[ingredientID], "pesanan "+order.OrderNumber+" dibatalkan", by, order.ID); err != nil {
+			return
+		}
+		restored = append(restored, ingredientID)
+	}
+	return RefreshMenuAvailability(db, restored)
+}
+
+// Adjust penyesuaian stok manual (restock, bahan rusak, stock opname), alasan wajib diisi
+func Adjust(db *mongo.Database, ingredientID primitive.ObjectID, change float64, reason, by string) (ingredient model.Ingredient, err error) {
+	if reason == "" {
+		err = errors.New("alasan penyesuaian stok wajib diisi")
+		return
+	}
+	if change == 0 {
+		err = errors.New("perubahan stok tidak boleh 0")
+		return
+	}
+	if ingredient, err = applyChange(db, ingredientID, change, reason, by, primitive.NilObjectID); err != nil {
+		return
+	}
+	err = RefreshMenuAvailability(db, []primitive.ObjectID{ingredientID})
+	return
+}
+
+// applyChange mengubah stok satu bahan secara atomik lalu mencatat pergerakan stoknya.
+// Pengurangan hanya terjadi jika stok masih cukup, sehingga stok tidak pernah minus.
+func applyChange(db *mongo.Database, ingredientID primitive.ObjectID, change float64, reason, by string, orderID primitive.ObjectID) (ingredient model.Ingredient, err error) {
+	now := time.Now()
+	filter := bson.M{"_id": ingredientID}
+	if change < 0 {
+		filter["stock"] = bson.M{"$gte": -change}
+	}
+	update := bson.M{"$inc": bson.M{"stock": change}, "$set": bson.M{"updated_at": now}}
+	ingredient, err = atdb.FindOneAndUpdateDoc[model.Ingredient](db, "ingredient", filter, update)
+	if err == mongo.ErrNoDocuments {
+		current, errFind := atdb.GetOneDoc[model.Ingredient](db, "ingredient", bson.M{"_id": ingredientID})
+		if errFind != nil {
+			err = errors.New("bahan " + ingredientID.Hex() + " tidak ditemukan")
+			return
+		}
+		err = fmt.Errorf("%w: %s tersisa %v %s, dibutuhkan %v", ErrInsufficientStock, current.Name, current.Stock, current.Unit, -change)
+		return
+	}
+	if err != nil {
+		return
+	}
+	_, err = atdb.InsertOneDoc(db, "stock_movement", model.StockMovement{
+		IngredientID:   ingredient.ID,
+		IngredientName: ingredient.Name,
+		Change:         change,
+		StockAfter:     ingredient.Stock,
+		Reason:         reason,
+		OrderID:        orderID,
+		By:             by,
+		At:             now,
+	})
+	return
+}
+
+// RefreshMenuAvailability mengatur out_of_stock menu yang resepnya memakai bahan-bahan tersebut:
+// true jika ada bahan yang tidak cukup untuk satu porsi lagi. Status yang diatur admin tidak diubah,
+// menu hanya bisa dipesan jika status tersedia dan tidak out_of_stock (pesanan.IsMenuOrderable).
+func RefreshMenuAvailability(db *mongo.Database, ingredientIDs []primitive.ObjectID) (err error) {
+	if len(ingredientIDs) == 0 {
+		return
+	}
+	menus, err := atdb.GetAllDoc[[]model.Menu](db, "menu", bson.M{"recipe.ingredient_id": bson.M{"$in": ingredientIDs}})
+	if err != nil {
+		return
+	}
+	return refreshMenus(db, menus)
+}
+
+// RefreshMenu mengatur out_of_stock satu menu dari resepnya saat ini, dipakai setelah resep diubah.
+// Menu tanpa resep tidak bergantung pada stok sehingga tidak pernah out_of_stock.
+func RefreshMenu(db *mongo.Database, menu model.Menu) error {
+	return refreshMenus(db, []model.Menu{menu})
+}
+
+func refreshMenus(db *mongo.Database, menus []model.Menu) (err error) {
+	var usedIDs []primitive.ObjectID
+	for _, menu := range menus {
+		for _, r := range menu.Recipe {
+			usedIDs = append(usedIDs, r.IngredientID)
+		}
+	}
+	stock := make(map[primitive.ObjectID]float64)
+	if len(usedIDs) > 0 {
+		ingredients, errIngredient := atdb.GetAllDoc[[]model.Ingredient](db, "ingredient", bson.M{"_id": bson.M{"$in": usedIDs}})
+		if errIngredient != nil {
+			return errIngredient
+		}
+		for _, ingredient := range ingredients {
+			stock[ingredient.ID] = ingredient.Stock
+		}
+	}
+	for _, menu := range menus {
+		outOfStock := OutOfStock(menu.Recipe, stock)
+		if menu.OutOfStock == outOfStock {
+			continue
+		}
+		if _, err = atdb.UpdateDoc(db, "menu", bson.M{"_id": menu.ID}, bson.M{"$set": bson.M{"out_of_stock": outOfStock}}); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// OutOfStock true jika stok salah satu bahan resep tidak cukup untuk satu porsi
+func OutOfStock(recipe []model.RecipeItem, stock map[primitive.ObjectID]float64) bool {
+	for _, r := range recipe {
+		if stock[r.IngredientID] < r.Quantity {
+			return true
+		}
+	}
+	return false
+}
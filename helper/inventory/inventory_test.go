@@ -0,0 +1,29 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestOutOfStock(t *testing.T) {
+	kopi, susu := primitive.NewObjectID(), primitive.NewObjectID()
+	recipe := []model.RecipeItem{{IngredientID: kopi, Quantity: 18}, {IngredientID: susu, Quantity: 150}}
+	tests := []struct {
+		name   string
+		recipe []model.RecipeItem
+		stock  map[primitive.ObjectID]float64
+		want   bool
+	}{
+		{"tanpa resep", nil, nil, false},
+		{"cukup untuk satu porsi", recipe, map[primitive.ObjectID]float64{kopi: 18, susu: 150}, false},
+		{"salah satu bahan kurang", recipe, map[primitive.ObjectID]float64{kopi: 500, susu: 100}, true},
+		{"bahan tidak ada di koleksi", recipe, map[primitive.ObjectID]float64{kopi: 500}, true},
+	}
+	for _, tt := range tests {
+		if got := OutOfStock(tt.recipe, tt.stock); got != tt.want {
+			t.Errorf("%s: OutOfStock = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package jualin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PaymentMethod menyamakan metode pembayaran format lama dengan metode di model.Order (Cash/QRIS)
+func PaymentMethod(method string) string {
+	if strings.EqualFold(strings.TrimSpace(method), "qris") {
+		return "QRIS"
+	}
+	return "Cash"
+}
+
+// Note catatan order dari data lapak, format lama tidak punya kolom catatan
+func Note(namalapak string, user User) string {
+	var lines []string
+	if namalapak != "" {
+		lines = append(lines, "Lapak: "+namalapak)
+	}
+	if user.Address != "" {
+		lines = append(lines, "Alamat: "+user.Address)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// menuByName memetakan nama menu (huruf kecil) ke dokumen menu, format lama hanya mengirim nama tanpa ID.
+// Menu yang sudah dihapus (soft delete) tidak ikut, supaya nama yang dipakai ulang tidak tertukar dengan menu lama.
+func menuByName(db *mongo.Database) (menus map[string]model.Menu, err error) {
+	all, err := atdb.GetAllDoc[[]model.Menu](db, "menu", atdb.ExcludeDeleted(bson.M{}))
+	if err != nil {
+		return
+	}
+	menus = make(map[string]model.Menu, len(all))
+	for _, menu := range all {
+		menus[menuKey(menu.Name)] = menu
+	}
+	return
+}
+
+// menuKey nama menu yang dicocokkan tanpa membedakan huruf besar kecil dan spasi di tepi
+func menuKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ToOrderItems mengubah item format lama menjadi item model.Order dengan mencocokkan nama ke koleksi menu.
+// Harga dari client tetap disalin supaya selisih harga bisa dilaporkan setelah dihitung ulang.
+func ToOrderItems(db *mongo.Database, orders []Orders) (items []model.OrderItem, err error) {
+	menus, err := menuByName(db)
+	if err != nil {
+		return
+	}
+	return toOrderItems(orders, menus)
+}
+
+func toOrderItems(orders []Orders, menus map[string]model.Menu) (items []model.OrderItem, err error) {
+	for _, o := range orders {
+		menu, ok := menus[menuKey(o.Name)]
+		if !ok {
+			err = fmt.Errorf("%w: menu %s tidak ditemukan", pesanan.ErrOrderItemInvalid, o.Name)
+			return
+		}
+		items = append(items, model.OrderItem{
+			MenuID:   menu.ID,
+			MenuName: menu.Name,
+			Price:    float64(o.Price),
+			Quantity: o.Quantity,
+		})
+	}
+	return
+}
+
+// ToOrder mengubah dokumen koleksi order (format lama) menjadi model.Order untuk migrasi.
+// Nama dan harga dari dokumen lama dipertahankan apa adanya sebagai catatan sejarah;
+// MenuID diisi jika namanya masih ada di koleksi menu.
+func ToOrder(legacy PaymentRequest, menus map[string]model.Menu, status, paymentStatus string) model.Order {
+	orderDate := legacy.ID.Timestamp()
+	if location, err := time.LoadLocation("Asia/Jakarta"); err == nil {
+		orderDate = orderDate.In(location)
+	}
+	var items []model.OrderItem
+	for _, o := range legacy.Orders {
+		item := model.OrderItem{
+			MenuName: o.Name,
+			Price:    float64(o.Price),
+			Quantity: o.Quantity,
+		}
+		if menu, ok := menus[menuKey(o.Name)]; ok {
+			item.MenuID = menu.ID
+		}
+		items = append(items, item)
+	}
+	return model.Order{
+		OrderDate: orderDate,
+		UserInfo: model.UserInfo{
+			Name:     legacy.User.Name,
+			Whatsapp: legacy.User.Whatsapp,
+			Note:     Note("", legacy.User),
+		},
+		Orders:        items,
+		Total:         float64(legacy.Total),
+		PaymentMethod: PaymentMethod(legacy.PaymentMethod),
+		PaymentStatus: paymentStatus,
+		Status:        status,
+		CreatedBy:     legacy.User.Name,
+		CreatedByRole: "jualin",
+		StatusHistory: []model.OrderStatusHistory{{
+			To:   status,
+			By:   "migrasi",
+			Role: "system",
+			At:   time.Now(),
+		}},
+		LegacyID: legacy.ID,
+	}
+}
+
+// Migrate menyalin semua dokumen koleksi order ke koleksi orders dengan nomor order dan antrean
+// sesuai tanggal order lama. Dokumen lama tidak dihapus, dan dokumen yang sudah pernah disalin
+// (legacy_id sudah ada di orders) dilewati sehingga aman dijalankan ulang.
+func Migrate(db *mongo.Database, status, paymentStatus string, dryRun bool) (migrated, skipped int, err error) {
+	legacies, err := atdb.GetAllDoc[[]PaymentRequest](db, "order", bson.M{})
+	if err != nil {
+		return
+	}
+	menus, err := menuByName(db)
+	if err != nil {
+		return
+	}
+	for _, legacy := range legacies {
+		count, errCount := atdb.GetCountDoc(db, "orders", bson.M{"legacy_id": legacy.ID})
+		if errCount != nil {
+			return migrated, skipped, errCount
+		}
+		if count > 0 {
+			skipped++
+			continue
+		}
+		order := ToOrder(legacy, menus, status, paymentStatus)
+		if dryRun {
+			migrated++
+			continue
+		}
+		order.OrderNumber, order.QueueNumber, err = pesanan.OrderNumberAt(db, order.OrderDate)
+		if err != nil {
+			return
+		}
+		if _, err = atdb.InsertOneDoc(db, "orders", order); err != nil {
+			return
+		}
+		migrated++
+	}
+	return
+}
@@ -0,0 +1,91 @@
+package jualin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	kopi  = model.Menu{ID: primitive.NewObjectID(), Name: "Kopi Susu", Price: 20000}
+	menus = map[string]model.Menu{menuKey(kopi.Name): kopi}
+)
+
+func TestPaymentMethod(t *testing.T) {
+	tests := map[string]string{"qris": "QRIS", " QRIS ": "QRIS", "cash": "Cash", "": "Cash", "transfer": "Cash"}
+	for method, want := range tests {
+		if got := PaymentMethod(method); got != want {
+			t.Errorf("PaymentMethod(%q) = %q, want %q", method, got, want)
+		}
+	}
+}
+
+func TestNote(t *testing.T) {
+	tests := []struct {
+		namalapak string
+		user      User
+		want      string
+	}{
+		{"Lapak Kampus", User{Address: "Gedung A"}, "Lapak: Lapak Kampus\nAlamat: Gedung A"},
+		{"Lapak Kampus", User{}, "Lapak: Lapak Kampus"},
+		{"", User{Address: "Gedung A"}, "Alamat: Gedung A"},
+		{"", User{}, ""},
+	}
+	for _, tt := range tests {
+		if got := Note(tt.namalapak, tt.user); got != tt.want {
+			t.Errorf("Note(%q, %+v) = %q, want %q", tt.namalapak, tt.user, got, tt.want)
+		}
+	}
+}
+
+func TestToOrderItems(t *testing.T) {
+	items, err := toOrderItems([]Orders{{Name: " kopi SUSU ", Quantity: 2, Price: 1000}}, menus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nama diambil dari menu, harga client disalin supaya selisihnya bisa dilaporkan
+	want := []model.OrderItem{{MenuID: kopi.ID, MenuName: "Kopi Susu", Price: 1000, Quantity: 2}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items = %+v, want %+v", items, want)
+	}
+	if _, err := toOrderItems([]Orders{{Name: "Es Teh", Quantity: 1}}, menus); !errors.Is(err, pesanan.ErrOrderItemInvalid) {
+		t.Errorf("menu tidak ada: err = %v, want ErrOrderItemInvalid", err)
+	}
+}
+
+func TestToOrder(t *testing.T) {
+	created := time.Date(2024, 10, 1, 3, 0, 0, 0, time.UTC)
+	legacy := PaymentRequest{
+		ID:            primitive.NewObjectIDFromTimestamp(created),
+		Orders:        []Orders{{Name: "Kopi Susu", Quantity: 2, Price: 18000}, {Name: "Menu Lama", Quantity: 1, Price: 5000}},
+		Total:         41000,
+		User:          User{Name: "Budi", Whatsapp: "08123456789", Address: "Gedung A"},
+		PaymentMethod: "qris",
+	}
+	order := ToOrder(legacy, menus, model.OrderStatusSelesai, model.PaymentStatusLunas)
+
+	if !order.OrderDate.Equal(created) || order.OrderDate.Location().String() != "Asia/Jakarta" {
+		t.Errorf("OrderDate = %v, want %v di Asia/Jakarta", order.OrderDate, created)
+	}
+	wantItems := []model.OrderItem{
+		{MenuID: kopi.ID, MenuName: "Kopi Susu", Price: 18000, Quantity: 2},
+		{MenuName: "Menu Lama", Price: 5000, Quantity: 1}, // menu sudah tidak ada, nama dan harga lama tetap disimpan
+	}
+	if !reflect.DeepEqual(order.Orders, wantItems) {
+		t.Errorf("Orders = %+v, want %+v", order.Orders, wantItems)
+	}
+	if order.Total != 41000 || order.PaymentMethod != "QRIS" || order.PaymentStatus != model.PaymentStatusLunas {
+		t.Errorf("Total %.0f, PaymentMethod %s, PaymentStatus %s", order.Total, order.PaymentMethod, order.PaymentStatus)
+	}
+	if order.Status != model.OrderStatusSelesai || len(order.StatusHistory) != 1 || order.StatusHistory[0].To != model.OrderStatusSelesai {
+		t.Errorf("Status %s, StatusHistory %+v", order.Status, order.StatusHistory)
+	}
+	if order.UserInfo.Note != "Alamat: Gedung A" || order.CreatedByRole != "jualin" || order.LegacyID != legacy.ID {
+		t.Errorf("UserInfo %+v, CreatedByRole %s, LegacyID %s", order.UserInfo, order.CreatedByRole, order.LegacyID.Hex())
+	}
+}
@@ -1,5 +1,7 @@
 package jualin
 
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
 type Orders struct {
 	Name     string `json:"name" bson:"name"`
 	Quantity int    `json:"quantity" bson:"quantity"`
@@ -13,11 +15,12 @@ type User struct {
 }
 
 type PaymentRequest struct {
-	Orders        []Orders`json:"orders" bson:"orders"`
-	Total         int     `json:"total" bson:"total"`
-	User          User    `json:"user" bson:"user"`
-	Payment       string  `json:"payment" bson:"payment"`
-	PaymentMethod string  `json:"paymentMethod" bson:"paymentMethod"`
+	ID            primitive.ObjectID `json:"-" bson:"_id,omitempty"` // diisi saat dibaca dari koleksi order untuk migrasi
+	Orders        []Orders           `json:"orders" bson:"orders"`
+	Total         int                `json:"total" bson:"total"`
+	User          User               `json:"user" bson:"user"`
+	Payment       string             `json:"payment" bson:"payment"`
+	PaymentMethod string             `json:"paymentMethod" bson:"paymentMethod"`
 }
 
 type MenuItem struct {
@@ -61,6 +61,11 @@ func jaro(s1, s2 string) float64 {
 	return (float64(matches)/float64(len1) + float64(matches)/float64(len2) + float64(matches-t)/float64(matches)) / 3.0
 }
 
+// JaroWinkler kemiripan dua kata antara 0 (berbeda) dan 1 (sama), awalan yang sama diberi bobot lebih
+func JaroWinkler(s1, s2 string) float64 {
+	return jaroWinkler(s1, s2)
+}
+
 func jaroWinkler(s1, s2 string) float64 {
 	jaroDist := jaro(s1, s2)
 
@@ -7,6 +7,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/RadhiFadlillah/go-sastrawi"
 	"github.com/kimseokgis/backend-ai/model"
@@ -156,9 +157,13 @@ func QueriesALL(db *mongo.Database, ctx context.Context) (dest []model.Datasets,
 	return dest, err
 }
 
+// defaultStemmer kamus sastrawi cukup dibuat sekali, membangunnya di setiap panggilan cukup berat
+var defaultStemmer = sync.OnceValue(func() sastrawi.Stemmer {
+	return sastrawi.NewStemmer(sastrawi.DefaultDictionary())
+})
+
 func Stemmer(Sentences string) (newString string) {
-	dictionary := sastrawi.DefaultDictionary()
-	stemmer := sastrawi.NewStemmer(dictionary)
+	stemmer := defaultStemmer()
 	for _, word := range sastrawi.Tokenize(Sentences) {
 		//if word != "i" { //menghilangkan i singkatan dari iteung
 		newString = newString + " " + stemmer.Stem(word)
@@ -0,0 +1,206 @@
+package kitchen
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DefaultStation stasiun untuk kategori yang belum dipetakan, kebanyakan menu kedai kopi dibuat di bar
+const DefaultStation = model.StationBar
+
+// Stations semua stasiun yang dikenal, urutan ini dipakai saat menampilkan daftar stasiun
+var Stations = []string{model.StationBar, model.StationKitchen, model.StationPastry}
+
+// LateAfter batas umur tiket per stasiun sebelum ditandai terlambat di layar stasiun
+var LateAfter = map[string]time.Duration{
+	model.StationBar:     5 * time.Minute,
+	model.StationKitchen: 15 * time.Minute,
+	model.StationPastry:  5 * time.Minute,
+}
+
+// ErrLineInvalid - Item tidak bisa ditandai siap (indeks salah, pesanan tidak sedang diproses, sudah/belum siap)
+var ErrLineInvalid = errors.New("item pesanan tidak dapat diubah")
+
+// ValidStation - Mengecek nama stasiun yang diisi admin pada kategori
+func ValidStation(station string) bool {
+	for _, s := range Stations {
+		if s == station {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignStations mengisi stasiun setiap item dari kategori menunya. Item yang sudah punya stasiun tidak diubah,
+// sehingga bisa dipakai juga untuk pesanan lama yang dibuat sebelum kategori dipetakan ke stasiun.
+// Menu yang sudah dihapus tetap dicari supaya pesanan lama tetap masuk ke stasiun yang benar.
+func AssignStations(db *mongo.Database, items []model.OrderItem) ([]model.OrderItem, error) {
+	var menuIDs []primitive.ObjectID
+	for _, item := range items {
+		if item.Station == "" {
+			menuIDs = append(menuIDs, item.MenuID)
+		}
+	}
+	assigned := append([]model.OrderItem(nil), items...)
+	if len(menuIDs) == 0 {
+		return assigned, nil
+	}
+
+	menus, err := atdb.GetAllDoc[[]model.Menu](db, "menu", bson.M{"_id": bson.M{"$in": menuIDs}})
+	if err != nil {
+		return nil, err
+	}
+	categoryByMenu := make(map[primitive.ObjectID]primitive.ObjectID, len(menus))
+	var categoryIDs []primitive.ObjectID
+	for _, menu := range menus {
+		categoryByMenu[menu.ID] = menu.CategoryID
+		categoryIDs = append(categoryIDs, menu.CategoryID)
+	}
+	categories, err := atdb.GetAllDoc[[]model.Category](db, "category", bson.M{"_id": bson.M{"$in": categoryIDs}})
+	if err != nil {
+		return nil, err
+	}
+	stationByCategory := make(map[primitive.ObjectID]string, len(categories))
+	for _, category := range categories {
+		stationByCategory[category.ID] = category.Station
+	}
+
+	for i := range assigned {
+		if assigned[i].Station != "" {
+			continue
+		}
+		assigned[i].Station = stationByCategory[categoryByMenu[assigned[i].MenuID]]
+		if assigned[i].Station == "" {
+			assigned[i].Station = DefaultStation
+		}
+	}
+	return assigned, nil
+}
+
+// StartedAt waktu pesanan mulai dikerjakan (masuk status diproses), umur tiket dihitung dari sini
+func StartedAt(order model.Order) time.Time {
+	for i := len(order.StatusHistory) - 1; i >= 0; i-- {
+		if order.StatusHistory[i].To == model.OrderStatusDiproses {
+			return order.StatusHistory[i].At
+		}
+	}
+	return order.OrderDate
+}
+
+// TicketLine satu baris item pada tiket stasiun, Index menunjuk posisi item di model.Order.Orders
+type TicketLine struct {
+	Index     int                      `json:"index"`
+	MenuName  string                   `json:"menu_name"`
+	Quantity  int                      `json:"quantity"`
+	Modifiers []model.SelectedModifier `json:"modifiers,omitempty"`
+	ReadyAt   time.Time                `json:"ready_at,omitempty"`
+	ReadyBy   string                   `json:"ready_by,omitempty"`
+}
+
+// Ticket bagian satu pesanan yang dikerjakan sebuah stasiun
+type Ticket struct {
+	OrderID     primitive.ObjectID `json:"order_id"`
+	OrderNumber string             `json:"orderNumber"`
+	QueueNumber int                `json:"queueNumber"`
+	TableNumber string             `json:"table_number,omitempty"`
+	Customer    string             `json:"customer"`
+	Note        string             `json:"note,omitempty"`
+	Station     string             `json:"station"`
+	Lines       []TicketLine       `json:"lines"`
+	StartedAt   time.Time          `json:"started_at"`
+	AgeSeconds  int64              `json:"age_seconds"`
+	Late        bool               `json:"late"` // Umur tiket melewati batas stasiun
+	Done        bool               `json:"done"` // Semua baris stasiun ini sudah siap
+}
+
+// Tickets memecah pesanan menjadi tiket untuk satu stasiun, diurutkan dari yang paling lama.
+// Item pesanan harus sudah diisi stasiunnya (AssignStations). Tiket yang semua barisnya sudah siap
+// hanya ikut jika includeDone, lateAfter 0 memakai batas bawaan stasiun (LateAfter).
+func Tickets(orders []model.Order, station string, now time.Time, lateAfter time.Duration, includeDone bool) []Ticket {
+	if lateAfter <= 0 {
+		lateAfter = LateAfter[station]
+	}
+	tickets := []Ticket{}
+	for _, order := range orders {
+		ticket := Ticket{
+			OrderID:     order.ID,
+			OrderNumber: order.OrderNumber,
+			QueueNumber: order.QueueNumber,
+			TableNumber: order.TableNumber,
+			Customer:    order.UserInfo.Name,
+			Note:        order.UserInfo.Note,
+			Station:     station,
+			StartedAt:   StartedAt(order),
+			Done:        true,
+		}
+		for i, item := range order.Orders {
+			if item.Station != station {
+				continue
+			}
+			ticket.Lines = append(ticket.Lines, TicketLine{
+				Index:     i,
+				MenuName:  item.MenuName,
+				Quantity:  item.Quantity,
+				Modifiers: item.Modifiers,
+				ReadyAt:   item.ReadyAt,
+				ReadyBy:   item.ReadyBy,
+			})
+			if item.ReadyAt.IsZero() {
+				ticket.Done = false
+			}
+		}
+		if len(ticket.Lines) == 0 || (ticket.Done && !includeDone) {
+			continue
+		}
+		age := now.Sub(ticket.StartedAt)
+		ticket.AgeSeconds = int64(age / time.Second)
+		ticket.Late = !ticket.Done && lateAfter > 0 && age > lateAfter
+		tickets = append(tickets, ticket)
+	}
+	sort.SliceStable(tickets, func(i, j int) bool { return tickets[i].StartedAt.Before(tickets[j].StartedAt) })
+	return tickets
+}
+
+// AllReady - Semua item pesanan sudah ditandai siap oleh stasiunnya
+func AllReady(items []model.OrderItem) bool {
+	for _, item := range items {
+		if item.ReadyAt.IsZero() {
+			return false
+		}
+	}
+	return len(items) > 0
+}
+
+// SetReady menandai (ready true) atau membatalkan tanda siap satu item pesanan yang sedang diproses.
+// Update bersyarat pada status dan tanda siap item, sehingga dua layar yang menekan bersamaan tidak saling menimpa.
+func SetReady(db *mongo.Database, order model.Order, index int, ready bool, by string, at time.Time) (model.Order, error) {
+	if order.Status != model.OrderStatusDiproses {
+		return order, fmt.Errorf("%w: pesanan %s berstatus '%s', bukan '%s'", ErrLineInvalid, order.OrderNumber, order.Status, model.OrderStatusDiproses)
+	}
+	if index < 0 || index >= len(order.Orders) {
+		return order, fmt.Errorf("%w: item ke-%d tidak ada pada pesanan %s", ErrLineInvalid, index, order.OrderNumber)
+	}
+	field := fmt.Sprintf("orders.%d.", index)
+	filter := bson.M{"_id": order.ID, "status": model.OrderStatusDiproses, field + "ready_at": bson.M{"$exists": !ready}}
+	update := bson.M{"$set": bson.M{field + "ready_at": at, field + "ready_by": by}}
+	if !ready {
+		update = bson.M{"$unset": bson.M{field + "ready_at": "", field + "ready_by": ""}}
+	}
+	updated, err := atdb.FindOneAndUpdateDoc[model.Order](db, "orders", filter, update)
+	if err == mongo.ErrNoDocuments {
+		state := "sudah"
+		if !ready {
+			state = "belum"
+		}
+		return order, fmt.Errorf("%w: item %s %s ditandai siap atau status pesanan sudah berubah", ErrLineInvalid, order.Orders[index].MenuName, state)
+	}
+	return updated, err
+}
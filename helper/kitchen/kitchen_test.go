@@ -0,0 +1,61 @@
+package kitchen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocroot/model"
+)
+
+func TestTickets(t *testing.T) {
+	now := time.Now()
+	slow := model.Order{
+		OrderNumber: "LGC-1",
+		OrderDate:   now.Add(-20 * time.Minute),
+		StatusHistory: []model.OrderStatusHistory{
+			{To: model.OrderStatusTerkirim, At: now.Add(-20 * time.Minute)},
+			{To: model.OrderStatusDiproses, At: now.Add(-8 * time.Minute)},
+		},
+		Orders: []model.OrderItem{
+			{MenuName: "Croissant", Quantity: 1, Station: model.StationPastry},
+			{MenuName: "Kopi Susu", Quantity: 2, Station: model.StationBar},
+		},
+	}
+	fresh := model.Order{
+		OrderNumber: "LGC-2",
+		OrderDate:   now.Add(-time.Minute),
+		Orders: []model.OrderItem{
+			{MenuName: "Americano", Quantity: 1, Station: model.StationBar, ReadyAt: now},
+			{MenuName: "Latte", Quantity: 1, Station: model.StationBar},
+		},
+	}
+	done := model.Order{
+		OrderNumber: "LGC-3",
+		OrderDate:   now.Add(-2 * time.Minute),
+		Orders:      []model.OrderItem{{MenuName: "Espresso", Quantity: 1, Station: model.StationBar, ReadyAt: now}},
+	}
+
+	bar := Tickets([]model.Order{fresh, done, slow}, model.StationBar, now, 0, false)
+	if len(bar) != 2 || bar[0].OrderNumber != "LGC-1" || bar[1].OrderNumber != "LGC-2" {
+		t.Fatalf("bar = %+v", bar)
+	}
+	if line := bar[0].Lines[0]; len(bar[0].Lines) != 1 || line.Index != 1 || line.MenuName != "Kopi Susu" {
+		t.Errorf("lines = %+v", bar[0].Lines)
+	}
+	if !bar[0].Late || bar[0].AgeSeconds != 8*60 {
+		t.Errorf("tiket lama: late=%v age=%d, want true 480", bar[0].Late, bar[0].AgeSeconds)
+	}
+	if bar[1].Late || bar[1].Done {
+		t.Errorf("tiket baru: late=%v done=%v", bar[1].Late, bar[1].Done)
+	}
+	if all := Tickets([]model.Order{fresh, done, slow}, model.StationBar, now, 0, true); len(all) != 3 || !all[1].Done {
+		t.Errorf("include done = %+v", all)
+	}
+	if pastry := Tickets([]model.Order{slow}, model.StationPastry, now, 10*time.Minute, false); len(pastry) != 1 || pastry[0].Late {
+		t.Errorf("pastry = %+v", pastry)
+	}
+
+	if AllReady(fresh.Orders) || !AllReady(done.Orders) || AllReady(nil) {
+		t.Error("AllReady salah")
+	}
+}
@@ -0,0 +1,247 @@
+package loyalty
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	RupiahPerPoint = 1000 // setiap Rp 1.000 dari total pesanan selesai mendapat 1 poin sebelum multiplier tier
+	PointValue     = 100  // nilai 1 poin dalam rupiah saat ditukar sebagai potongan
+)
+
+// Tier tingkatan pelanggan berdasarkan total poin yang pernah didapat
+type Tier struct {
+	Name        string  `json:"name"`
+	MinLifetime int     `json:"min_lifetime"`
+	Multiplier  float64 `json:"multiplier"`
+}
+
+// Tiers diurutkan dari tier terendah
+var Tiers = []Tier{
+	{Name: "Bronze", MinLifetime: 0, Multiplier: 1},
+	{Name: "Silver", MinLifetime: 500, Multiplier: 1.25},
+	{Name: "Gold", MinLifetime: 2000, Multiplier: 1.5},
+}
+
+// ErrPointsInvalid - Penukaran poin tidak bisa dilakukan (saldo kurang, menu tidak bisa ditukar, melebihi total)
+var ErrPointsInvalid = errors.New("poin tidak dapat ditukar")
+
+// TierFor mengembalikan tier saat ini dan tier berikutnya (nil jika sudah tertinggi)
+func TierFor(lifetime int) (tier Tier, next *Tier) {
+	for i, t := range Tiers {
+		if lifetime >= t.MinLifetime {
+			tier = t
+			next = nil
+			if i+1 < len(Tiers) {
+				next = &Tiers[i+1]
+			}
+		}
+	}
+	return
+}
+
+// EarnedPoints menghitung poin dari total pesanan dengan multiplier tier
+func EarnedPoints(total float64, tier Tier) int {
+	return int(math.Floor(total / RupiahPerPoint * tier.Multiplier))
+}
+
+// PointsBase nilai belanja yang dihitung untuk poin: total pesanan tanpa ongkir
+func PointsBase(order model.Order) float64 {
+	total := order.Total
+	if order.Delivery != nil {
+		total -= order.Delivery.Fee
+	}
+	return math.Max(total, 0)
+}
+
+// GetAccount mengambil saldo poin pelanggan, akun yang belum pernah dapat poin dianggap saldo 0
+func GetAccount(db *mongo.Database, phonenumber string) (account model.LoyaltyAccount, err error) {
+	account, err = atdb.GetOneDoc[model.LoyaltyAccount](db, "loyalty_account", bson.M{"_id": phonenumber})
+	if err == mongo.ErrNoDocuments {
+		return model.LoyaltyAccount{Phone: phonenumber}, nil
+	}
+	return
+}
+
+// GetLedger mengambil riwayat poin pelanggan, terbaru di atas
+func GetLedger(db *mongo.Database, phonenumber string) (ledger []model.PointsLedger, err error) {
+	return atdb.AggregateDocs[model.PointsLedger](db, "loyalty_ledger", mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"phone": phonenumber}}},
+		{{Key: "$sort", Value: bson.D{{Key: "at", Value: -1}, {Key: "_id", Value: -1}}}},
+	})
+}
+
+// Earn menambahkan poin untuk pesanan yang selesai ke pelanggan yang login saat memesan (order.Customer).
+// Nomor WhatsApp yang hanya diketik di pesanan tidak mendapat poin, begitu juga pesanan meja, lapak dan kasir.
+func Earn(db *mongo.Database, order model.Order) (err error) {
+	customer := order.Customer
+	if customer == "" {
+		return
+	}
+	account, err := GetAccount(db, customer)
+	if err != nil {
+		return
+	}
+	tier, _ := TierFor(account.Lifetime)
+	points := EarnedPoints(PointsBase(order), tier)
+	if points <= 0 {
+		return
+	}
+	entry := model.PointsLedger{
+		Type:        model.PointsEarn,
+		Points:      points,
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		Tier:        tier.Name,
+		Multiplier:  tier.Multiplier,
+		Description: fmt.Sprintf("Pesanan %s selesai", order.OrderNumber),
+	}
+	return apply(db, customer, bson.M{"_id": customer}, bson.M{"balance": points, "lifetime": points}, entry, true)
+}
+
+// Quote menghitung poin yang dipakai dan potongan yang didapat dari penukaran poin.
+// Penukaran menu gratis memotong harga satu porsi menu tersebut (termasuk modifier) yang ada di keranjang.
+func Quote(db *mongo.Database, items []model.OrderItem, total float64, points int, rewardMenuID primitive.ObjectID) (used int, discount float64, err error) {
+	var reward model.Menu
+	if !rewardMenuID.IsZero() {
+		menu, errMenu := atdb.GetOneDoc[model.Menu](db, "menu", atdb.ExcludeDeleted(bson.M{"_id": rewardMenuID}))
+		if errMenu != nil || menu.PointsPrice <= 0 {
+			err = fmt.Errorf("%w: menu %s tidak bisa ditukar dengan poin", ErrPointsInvalid, rewardMenuID.Hex())
+			return
+		}
+		reward = menu
+	}
+	return quote(items, total, points, reward)
+}
+
+// quote menghitung penukaran poin dari data menu hadiah, reward kosong jika tidak menukar menu gratis
+func quote(items []model.OrderItem, total float64, points int, reward model.Menu) (used int, discount float64, err error) {
+	if points < 0 {
+		err = fmt.Errorf("%w: jumlah poin tidak boleh minus", ErrPointsInvalid)
+		return
+	}
+	if !reward.ID.IsZero() {
+		found := false
+		for _, item := range items {
+			if item.MenuID == reward.ID {
+				discount = item.Price
+				found = true
+				break
+			}
+		}
+		if !found {
+			err = fmt.Errorf("%w: menu %s harus ada di keranjang untuk ditukar", ErrPointsInvalid, reward.Name)
+			return
+		}
+		used = reward.PointsPrice
+	}
+	used += points
+	discount += float64(points * PointValue)
+	if discount > total {
+		err = fmt.Errorf("%w: potongan poin melebihi total pesanan", ErrPointsInvalid)
+	}
+	return
+}
+
+// Burn menukar poin pelanggan untuk sebuah pesanan, hanya jika saldonya cukup
+func Burn(db *mongo.Database, customer string, points int, order model.Order) (err error) {
+	if points <= 0 {
+		return
+	}
+	entry := model.PointsLedger{
+		Type:        model.PointsBurn,
+		Points:      -points,
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		Description: "Tukar poin untuk pesanan", // nomor order diisi SetOrderNumber setelah nomor antrean diambil
+	}
+	filter := bson.M{"_id": customer, "balance": bson.M{"$gte": points}}
+	err = apply(db, customer, filter, bson.M{"balance": -points}, entry, false)
+	if err == mongo.ErrNoDocuments {
+		err = fmt.Errorf("%w: saldo poin tidak cukup", ErrPointsInvalid)
+	}
+	return
+}
+
+// SetOrderNumber mengisi nomor order pada entri ledger yang dicatat sebelum nomor antrean pesanan diambil.
+// Hanya referensi nomor order yang diisi, jumlah poin dan saldo pada ledger tidak berubah.
+func SetOrderNumber(db *mongo.Database, order model.Order) (err error) {
+	filter := bson.M{"order_id": order.ID, "type": model.PointsBurn, "order_number": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{
+		"order_number": order.OrderNumber,
+		"description":  fmt.Sprintf("Tukar poin untuk pesanan %s", order.OrderNumber),
+	}}
+	_, err = atdb.UpdateManyDocs(db, "loyalty_ledger", filter, update)
+	return
+}
+
+// Reverse membalik seluruh perubahan poin dari sebuah pesanan sesuai isi ledger, sehingga pembatalan
+// mengembalikan persis poin yang ditukar atau mencabut poin yang diberikan. Aman dipanggil berulang.
+func Reverse(db *mongo.Database, order model.Order) (err error) {
+	entries, err := atdb.GetAllDoc[[]model.PointsLedger](db, "loyalty_ledger", bson.M{"order_id": order.ID})
+	if err != nil || len(entries) == 0 {
+		return
+	}
+	customer := entries[0].Phone
+	net, earned := reversal(entries)
+	if net == 0 {
+		return
+	}
+	entry := model.PointsLedger{
+		Type:        model.PointsReverse,
+		Points:      -net,
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		Description: fmt.Sprintf("Pembalikan poin pesanan %s", order.OrderNumber),
+	}
+	return apply(db, customer, bson.M{"_id": customer}, bson.M{"balance": -net, "lifetime": -earned}, entry, false)
+}
+
+// reversal menghitung sisa poin bersih pesanan dari ledger dan poin earn yang harus dicabut dari lifetime.
+// lifetime hanya dikurangi sekali, pembalikan sebelumnya sudah mencabut poin earn dari tier.
+func reversal(entries []model.PointsLedger) (net, earned int) {
+	reversed := false
+	for _, e := range entries {
+		net += e.Points
+		switch e.Type {
+		case model.PointsEarn:
+			earned += e.Points
+		case model.PointsReverse:
+			reversed = true
+		}
+	}
+	if reversed {
+		earned = 0
+	}
+	return
+}
+
+// apply mengubah saldo akun secara atomik lalu mencatat entri ledger dengan saldo setelahnya.
+// Akun baru hanya dibuat (upsert) saat menambah poin.
+func apply(db *mongo.Database, customer string, filter bson.M, inc bson.M, entry model.PointsLedger, upsert bool) (err error) {
+	now := time.Now()
+	update := bson.M{"$inc": inc, "$set": bson.M{"updated_at": now}}
+	var account model.LoyaltyAccount
+	if upsert {
+		account, err = atdb.FindOneAndUpsertDoc[model.LoyaltyAccount](db, "loyalty_account", filter, update)
+	} else {
+		account, err = atdb.FindOneAndUpdateDoc[model.LoyaltyAccount](db, "loyalty_account", filter, update)
+	}
+	if err != nil {
+		return
+	}
+	entry.Phone = customer
+	entry.BalanceAfter = account.Balance
+	entry.At = now
+	_, err = atdb.InsertOneDoc(db, "loyalty_ledger", entry)
+	return
+}
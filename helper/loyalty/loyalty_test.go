@@ -0,0 +1,121 @@
+package loyalty
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPointsBase(t *testing.T) {
+	tests := []struct {
+		name  string
+		order model.Order
+		want  float64
+	}{
+		{"ambil di toko", model.Order{Total: 45000}, 45000},
+		{"antar tanpa ongkir", model.Order{Total: 45000, Delivery: &model.OrderDelivery{}}, 45000},
+		{"ongkir tidak dapat poin", model.Order{Total: 55000, Delivery: &model.OrderDelivery{Fee: 10000}}, 45000},
+		{"potongan penuh hanya sisa ongkir", model.Order{Total: 8000, Delivery: &model.OrderDelivery{Fee: 8000}}, 0},
+	}
+	for _, tt := range tests {
+		if got := PointsBase(tt.order); got != tt.want {
+			t.Errorf("%s: PointsBase = %.0f, want %.0f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEarnedPoints(t *testing.T) {
+	gold, _ := TierFor(2000)
+	tests := []struct {
+		total float64
+		tier  Tier
+		want  int
+	}{
+		{999, Tiers[0], 0},
+		{45000, Tiers[0], 45},
+		{45500, Tiers[0], 45},
+		{45000, gold, 67},
+	}
+	for _, tt := range tests {
+		if got := EarnedPoints(tt.total, tt.tier); got != tt.want {
+			t.Errorf("EarnedPoints(%.0f, %s) = %d, want %d", tt.total, tt.tier.Name, got, tt.want)
+		}
+	}
+}
+
+func TestTierFor(t *testing.T) {
+	tests := []struct {
+		lifetime int
+		want     string
+		next     string
+	}{
+		{0, "Bronze", "Silver"},
+		{499, "Bronze", "Silver"},
+		{500, "Silver", "Gold"},
+		{5000, "Gold", ""},
+	}
+	for _, tt := range tests {
+		tier, next := TierFor(tt.lifetime)
+		var nextName string
+		if next != nil {
+			nextName = next.Name
+		}
+		if tier.Name != tt.want || nextName != tt.next {
+			t.Errorf("TierFor(%d) = %s, %q, want %s, %q", tt.lifetime, tier.Name, nextName, tt.want, tt.next)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	kopi := model.Menu{ID: primitive.NewObjectID(), Name: "Kopi Susu", PointsPrice: 150}
+	items := []model.OrderItem{{MenuID: kopi.ID, Price: 25000, Quantity: 2}}
+	tests := []struct {
+		name         string
+		points       int
+		reward       model.Menu
+		total        float64
+		wantUsed     int
+		wantDiscount float64
+		wantErr      bool
+	}{
+		{"potongan poin", 100, model.Menu{}, 50000, 100, 10000, false},
+		{"menu gratis satu porsi termasuk modifier", 0, kopi, 50000, 150, 25000, false},
+		{"menu gratis ditambah poin", 50, kopi, 50000, 200, 30000, false},
+		{"poin minus", -10, model.Menu{}, 50000, 0, 0, true},
+		{"melebihi total", 600, model.Menu{}, 50000, 0, 0, true},
+		{"menu tidak ada di keranjang", 0, model.Menu{ID: primitive.NewObjectID(), PointsPrice: 100}, 50000, 0, 0, true},
+	}
+	for _, tt := range tests {
+		used, discount, err := quote(items, tt.total, tt.points, tt.reward)
+		if tt.wantErr {
+			if !errors.Is(err, ErrPointsInvalid) {
+				t.Errorf("%s: err = %v, want ErrPointsInvalid", tt.name, err)
+			}
+			continue
+		}
+		if err != nil || used != tt.wantUsed || discount != tt.wantDiscount {
+			t.Errorf("%s: quote = %d, %.0f, %v, want %d, %.0f", tt.name, used, discount, err, tt.wantUsed, tt.wantDiscount)
+		}
+	}
+}
+
+func TestReversal(t *testing.T) {
+	tests := []struct {
+		name       string
+		entries    []model.PointsLedger
+		wantNet    int
+		wantEarned int
+	}{
+		{"cabut poin pesanan selesai", []model.PointsLedger{{Type: model.PointsEarn, Points: 45}}, 45, 45},
+		{"kembalikan poin yang ditukar", []model.PointsLedger{{Type: model.PointsBurn, Points: -100}}, -100, 0},
+		{"tukar lalu dapat poin", []model.PointsLedger{{Type: model.PointsBurn, Points: -100}, {Type: model.PointsEarn, Points: 30}}, -70, 30},
+		{"sudah dibalik", []model.PointsLedger{{Type: model.PointsEarn, Points: 45}, {Type: model.PointsReverse, Points: -45}}, 0, 0},
+	}
+	for _, tt := range tests {
+		if net, earned := reversal(tt.entries); net != tt.wantNet || earned != tt.wantEarned {
+			t.Errorf("%s: reversal = %d, %d, want %d, %d", tt.name, net, earned, tt.wantNet, tt.wantEarned)
+		}
+	}
+}
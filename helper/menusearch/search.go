@@ -0,0 +1,244 @@
+package menusearch
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/kimseok"
+	"github.com/gocroot/helper/module"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Synonyms sinonim bawaan, kata kiri dianggap sama dengan frasa kanan.
+// Sinonim tambahan bisa diatur admin di koleksi menu_synonym dengan format {"from": "regex", "to": "pengganti"}
+// seperti koleksi typo pada module.NormalizeAndTypoCorrection.
+var Synonyms = map[string]string{
+	"kopsu":     "kopi susu",
+	"coffee":    "kopi",
+	"kofi":      "kopi",
+	"milk":      "susu",
+	"dingin":    "es",
+	"ice":       "es",
+	"iced":      "es",
+	"hot":       "panas",
+	"anget":     "panas",
+	"hangat":    "panas",
+	"tea":       "teh",
+	"coklat":    "cokelat",
+	"chocolate": "cokelat",
+	"choco":     "cokelat",
+	"sugar":     "gula",
+	"palm":      "aren",
+}
+
+// bobot kecocokan per field menu
+const (
+	weightName        = 3.0
+	weightCategory    = 2.0
+	weightDescription = 1.0
+)
+
+// Batas kemiripan Jaro-Winkler supaya kata dianggap salah ketik dari kata lain
+const (
+	FuzzyThreshold      = 0.85
+	SuggestionThreshold = 0.8
+	MinScore            = 0.25 // skor minimal menu untuk masuk hasil pencarian
+)
+
+// Result satu menu hasil pencarian, Score antara 0 dan 1
+type Result struct {
+	Menu     model.Menu
+	Category string
+	Score    float64
+}
+
+// term satu kata pencarian beserta alternatifnya dari sinonim, sudah di-stem
+type term []string
+
+type document struct {
+	menu     model.Menu
+	category string
+	fields   [3][]string // kata ter-stem pada nama, kategori, deskripsi
+}
+
+var weights = [3]float64{weightName, weightCategory, weightDescription}
+
+// Search mencari menu berdasarkan nama, deskripsi dan kategori. Query dinormalisasi dengan koleksi typo dan
+// menu_synonym, lalu setiap kata di-stem dan dicocokkan dengan toleransi salah ketik (Jaro-Winkler).
+// suggestion berisi query yang sudah dikoreksi ("mungkin maksud anda") jika ada kata yang tidak dikenal.
+func Search(db *mongo.Database, query string, limit int) (results []Result, suggestion string, err error) {
+	menus, err := atdb.GetAllDoc[[]model.Menu](db, "menu", atdb.ExcludeDeleted(bson.M{}))
+	if err != nil {
+		return
+	}
+	categories, err := atdb.GetAllDoc[[]model.Category](db, "category", atdb.ExcludeDeleted(bson.M{}))
+	if err != nil {
+		return
+	}
+	categoryNames := make(map[primitive.ObjectID]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	query = strings.ToLower(query)
+	module.NormalizeAndTypoCorrection(&query, db, "typo")
+	module.NormalizeAndTypoCorrection(&query, db, "menu_synonym")
+	words := tokenize(query)
+	if len(words) == 0 {
+		return
+	}
+
+	docs := make([]document, 0, len(menus))
+	var vocabulary []string
+	for _, menu := range menus {
+		doc := document{menu: menu, category: categoryNames[menu.CategoryID]}
+		doc.fields[0] = stems(tokenize(menu.Name))
+		doc.fields[1] = stems(tokenize(doc.category))
+		doc.fields[2] = stems(tokenize(menu.Description))
+		docs = append(docs, doc)
+		vocabulary = append(vocabulary, tokenize(menu.Name)...)
+		vocabulary = append(vocabulary, tokenize(doc.category)...)
+	}
+
+	terms := expand(words)
+	for _, doc := range docs {
+		if score := doc.score(terms); score >= MinScore {
+			results = append(results, Result{Menu: doc.menu, Category: doc.category, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		// skor sama, nama yang lebih pendek lebih tepat (Kopi Susu sebelum Kopi Susu Gula Aren)
+		if len(results[i].Menu.Name) != len(results[j].Menu.Name) {
+			return len(results[i].Menu.Name) < len(results[j].Menu.Name)
+		}
+		return results[i].Menu.Name < results[j].Menu.Name
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, Suggest(words, vocabulary), nil
+}
+
+// score rata-rata kecocokan terbaik setiap kata pencarian, dibagi bobot tertinggi supaya hasilnya 0 sampai 1
+func (doc document) score(terms []term) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	var total float64
+	for _, t := range terms {
+		var best float64
+		for _, alt := range t {
+			for i, field := range doc.fields {
+				for _, word := range field {
+					if s := Similarity(alt, word) * weights[i]; s > best {
+						best = s
+					}
+				}
+			}
+		}
+		total += best
+	}
+	return total / (weightName * float64(len(terms)))
+}
+
+// Similarity kemiripan kata pencarian dengan kata pada menu: sama persis 1, awalan 0.9,
+// salah ketik di atas FuzzyThreshold sedikit di bawah nilai Jaro-Winkler-nya, selain itu 0
+func Similarity(queryWord, word string) float64 {
+	if queryWord == word {
+		return 1
+	}
+	if len(queryWord) >= 3 && strings.HasPrefix(word, queryWord) {
+		return 0.9
+	}
+	if jw := kimseok.JaroWinkler(queryWord, word); jw >= FuzzyThreshold {
+		return jw * 0.9
+	}
+	return 0
+}
+
+// Suggest mengganti kata yang tidak dikenal dengan kata paling mirip dari vocabulary,
+// kosong jika semua kata sudah dikenal atau tidak ada yang cukup mirip
+func Suggest(words, vocabulary []string) string {
+	known := make(map[string]bool, len(vocabulary))
+	for _, word := range vocabulary {
+		known[word] = true
+	}
+	corrected := make([]string, len(words))
+	changed := false
+	for i, word := range words {
+		corrected[i] = word
+		if known[word] || Synonyms[word] != "" {
+			continue
+		}
+		var best float64
+		for candidate := range known {
+			if jw := kimseok.JaroWinkler(word, candidate); jw >= SuggestionThreshold && jw > best {
+				best, corrected[i] = jw, candidate
+			}
+		}
+		changed = changed || corrected[i] != word
+	}
+	if !changed {
+		return ""
+	}
+	return strings.Join(corrected, " ")
+}
+
+// expand mengganti kata dengan sinonimnya, sinonim satu kata menjadi alternatif, sinonim frasa menjadi beberapa kata
+func expand(words []string) (terms []term) {
+	for _, word := range words {
+		synonym := tokenize(Synonyms[word])
+		switch len(synonym) {
+		case 0:
+			terms = append(terms, term{stem(word)})
+		case 1:
+			terms = append(terms, term{stem(word), stem(synonym[0])})
+		default:
+			for _, s := range synonym {
+				terms = append(terms, term{stem(s)})
+			}
+		}
+	}
+	return
+}
+
+// tokenize huruf kecil, dipisah selain huruf dan angka
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stemCache kata dasar kosakata menu dan kategori. Kata dari query pengguna tidak disimpan,
+// supaya cache tidak tumbuh tanpa batas oleh input bebas.
+var stemCache sync.Map
+
+// stem kata dasar dengan stemmer sastrawi, memakai cache jika kata tersebut ada di kosakata menu
+func stem(word string) string {
+	if s, ok := stemCache.Load(word); ok {
+		return s.(string)
+	}
+	s := kimseok.Stemmer(word)
+	if s == "" {
+		s = word // angka atau kata yang dibuang tokenizer sastrawi
+	}
+	return s
+}
+
+// stems men-stem kata menu dan kategori, hasilnya disimpan karena kata yang sama dipakai di setiap pencarian
+func stems(words []string) []string {
+	for i, word := range words {
+		words[i] = stem(word)
+		stemCache.Store(word, words[i])
+	}
+	return words
+}
@@ -0,0 +1,76 @@
+package menusearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		queryWord, word string
+		want            float64
+		fuzzy           bool // salah ketik, nilainya di antara FuzzyThreshold*0.9 dan 0.9
+	}{
+		{queryWord: "kopi", word: "kopi", want: 1},
+		{queryWord: "lat", word: "latte", want: 0.9},
+		{queryWord: "la", word: "latte", want: 0}, // awalan terlalu pendek
+		{queryWord: "americano", word: "teh", want: 0},
+		{queryWord: "kopu", word: "kopi", fuzzy: true},
+	}
+	for _, tt := range tests {
+		got := Similarity(tt.queryWord, tt.word)
+		if tt.fuzzy {
+			if got < FuzzyThreshold*0.9 || got >= 0.9 {
+				t.Errorf("Similarity(%q, %q) = %v, want salah ketik", tt.queryWord, tt.word, got)
+			}
+		} else if got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.queryWord, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	vocabulary := []string{"kopi", "susu", "gula", "aren", "latte", "matcha"}
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"kopi", "susu"}, ""},
+		{[]string{"kopu", "susu"}, "kopi susu"},
+		{[]string{"macha", "latte"}, "matcha latte"},
+		{[]string{"coffee", "sugar"}, ""}, // kata sinonim dianggap dikenal
+		{[]string{"xyz"}, ""},             // tidak ada yang cukup mirip
+	}
+	for _, tt := range tests {
+		if got := Suggest(tt.words, vocabulary); got != tt.want {
+			t.Errorf("Suggest(%v) = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  []term
+	}{
+		{[]string{"latte"}, []term{{"latte"}}},
+		{[]string{"iced", "latte"}, []term{{"iced", "es"}, {"latte"}}},
+		{[]string{"kopsu"}, []term{{"kopi"}, {"susu"}}},
+	}
+	for _, tt := range tests {
+		if got := expand(tt.words); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expand(%v) = %v, want %v", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestStemCacheOnlyVocabulary(t *testing.T) {
+	stem("kopikuuu")
+	if _, ok := stemCache.Load("kopikuuu"); ok {
+		t.Error("kata dari query pengguna ikut disimpan di cache")
+	}
+	stems([]string{"minuman"})
+	if _, ok := stemCache.Load("minuman"); !ok {
+		t.Error("kata menu tidak disimpan di cache")
+	}
+}
@@ -1,6 +1,7 @@
 package module
 
 import (
+	"log"
 	"regexp"
 
 	"github.com/gocroot/helper/atdb"
@@ -8,11 +9,21 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// NormalizeAndTypoCorrection mengganti setiap pola regex From di koleksi TypoCollection dengan To.
+// Pola yang tidak valid dilewati supaya satu dokumen yang salah tidak membuat panic.
 func NormalizeAndTypoCorrection(message *string, MongoConn *mongo.Database, TypoCollection string) {
 	typos, _ := atdb.GetAllDoc[[]Typo](MongoConn, TypoCollection, bson.M{})
+	ApplyTypos(message, typos)
+}
+
+// ApplyTypos mengganti pola regex From dengan To secara berurutan, tanpa membedakan huruf besar kecil
+func ApplyTypos(message *string, typos []Typo) {
 	for _, typo := range typos {
-		re := regexp.MustCompile(`(?i)` + typo.From + ``)
+		re, err := regexp.Compile(`(?i)` + typo.From)
+		if err != nil {
+			log.Println("Pola typo tidak valid " + typo.From + ": " + err.Error())
+			continue
+		}
 		*message = re.ReplaceAllString(*message, typo.To)
 	}
-
 }
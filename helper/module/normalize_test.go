@@ -0,0 +1,16 @@
+package module
+
+import "testing"
+
+func TestApplyTypos(t *testing.T) {
+	typos := []Typo{
+		{From: "kopsu", To: "kopi susu"},
+		{From: "(latte", To: "rusak"},
+		{From: `\bes\b`, To: "ice"},
+	}
+	message := "Kopsu es"
+	ApplyTypos(&message, typos)
+	if message != "kopi susu ice" {
+		t.Errorf("ApplyTypos = %q, want %q", message, "kopi susu ice")
+	}
+}
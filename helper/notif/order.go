@@ -0,0 +1,50 @@
+package notif
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/atapi"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/phone"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// orderNotifTimeout - Batas waktu kirim satu notifikasi status order ke WhatsApp API
+const orderNotifTimeout = 10 * time.Second
+
+// SendOrderStatus mengirim pesan WhatsApp ke pelanggan sesuai status order saat ini.
+// Error dikembalikan hanya untuk dicatat, pemanggil tidak boleh menggagalkan update order karenanya.
+func SendOrderStatus(order model.Order, db *mongo.Database) (err error) {
+	templ := pesanan.GetOrderTemplate(order.Status, db)
+	if templ == "" {
+		return // status ini tidak perlu notifikasi
+	}
+	to := phone.NormalizePhoneNumber(order.UserInfo.Whatsapp)
+	if to == "" {
+		return errors.New("nomor whatsapp pelanggan kosong")
+	}
+	dt := model.SendText{
+		To:       to,
+		IsGroup:  false,
+		Messages: pesanan.OrderStatusMessage(order, templ),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), orderNotifTimeout)
+	defer cancel()
+	_, _, err = atapi.PostStructWithTokenContext[model.Response](ctx, "token", config.WAAPIToken, dt, config.WAAPIMessage)
+	return
+}
+
+// SendOrderStatusAsync mengirim notifikasi status order di goroutine terpisah, supaya request checkout
+// atau update status tidak menunggu WhatsApp API. Kegagalan kirim hanya dicatat ke log.
+func SendOrderStatusAsync(order model.Order, db *mongo.Database) {
+	go func() {
+		if err := SendOrderStatus(order, db); err != nil {
+			log.Println("Gagal kirim notifikasi order " + order.OrderNumber + ": " + err.Error())
+		}
+	}()
+}
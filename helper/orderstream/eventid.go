@@ -0,0 +1,36 @@
+package orderstream
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// EventID menyusun ID event SSE stream pesanan: <unix milidetik>:<resume token hex>,
+// tanpa bagian resume token jika event berasal dari polling
+func EventID(at time.Time, resumeToken bson.Raw) string {
+	id := strconv.FormatInt(at.UnixMilli(), 10)
+	if len(resumeToken) > 0 {
+		id += ":" + hex.EncodeToString(resumeToken)
+	}
+	return id
+}
+
+// ParseEventID memecah ID event (header Last-Event-ID) menjadi waktu dan resume token change stream (jika ada).
+// Bagian yang tidak valid diabaikan, sehingga stream dimulai ulang dari awal.
+func ParseEventID(id string) (since time.Time, resumeToken bson.Raw) {
+	if id == "" {
+		return
+	}
+	millis, token, _ := strings.Cut(id, ":")
+	if ms, err := strconv.ParseInt(millis, 10, 64); err == nil {
+		since = time.UnixMilli(ms)
+	}
+	if raw, err := hex.DecodeString(token); err == nil && len(raw) > 0 {
+		resumeToken = bson.Raw(raw)
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package orderstream
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestEventID(t *testing.T) {
+	at := time.UnixMilli(1727751600123)
+	token := bson.Raw{0x0d, 0x00, 0x00, 0x00}
+	tests := []struct {
+		at    time.Time
+		token bson.Raw
+		want  string
+	}{
+		{at, token, "1727751600123:0d000000"},
+		{at, nil, "1727751600123"},
+	}
+	for _, tt := range tests {
+		if got := EventID(tt.at, tt.token); got != tt.want {
+			t.Errorf("EventID = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestParseEventID(t *testing.T) {
+	tests := []struct {
+		id        string
+		wantSince time.Time
+		wantToken bson.Raw
+	}{
+		{"1727751600123:0d000000", time.UnixMilli(1727751600123), bson.Raw{0x0d, 0x00, 0x00, 0x00}},
+		{"1727751600123", time.UnixMilli(1727751600123), nil},
+		{"1727751600123:bukanhex", time.UnixMilli(1727751600123), nil},
+		{"abc:0d000000", time.Time{}, bson.Raw{0x0d, 0x00, 0x00, 0x00}},
+		{"", time.Time{}, nil},
+	}
+	for _, tt := range tests {
+		since, token := ParseEventID(tt.id)
+		if !since.Equal(tt.wantSince) || !bytes.Equal(token, tt.wantToken) {
+			t.Errorf("ParseEventID(%q) = %v, %x, want %v, %x", tt.id, since, token, tt.wantSince, tt.wantToken)
+		}
+	}
+}
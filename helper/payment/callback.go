@@ -0,0 +1,45 @@
+package payment
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// SignCallback menghasilkan tanda tangan HMAC-SHA256 (hex) dari body callback
+func SignCallback(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseSignedCallback membaca body callback dan mencocokkan header X-Signature dengan HMAC body
+func ParseSignedCallback(r *http.Request, secret string) (cb Callback, err error) {
+	if secret == "" {
+		err = errors.New("secret callback pembayaran belum diatur")
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	signature := r.Header.Get("X-Signature")
+	if !hmac.Equal([]byte(signature), []byte(SignCallback(secret, body))) {
+		err = errors.New("tanda tangan callback tidak valid")
+		return
+	}
+	if err = json.Unmarshal(body, &cb); err != nil {
+		return
+	}
+	if cb.Status != CallbackPaid && cb.Status != CallbackExpired {
+		err = errors.New("status callback tidak dikenal: " + cb.Status)
+	}
+	return
+}
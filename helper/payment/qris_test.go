@@ -0,0 +1,25 @@
+package payment
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDynamicQRIS(t *testing.T) {
+	qr, err := DynamicQRIS(mockMerchantPayload, 25000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidQRIS(qr) {
+		t.Errorf("CRC QRIS dinamis tidak valid: %s", qr)
+	}
+	if !strings.Contains(qr, "010212") {
+		t.Errorf("point of initiation harus 12 (dinamis): %s", qr)
+	}
+	if !strings.Contains(qr, "540525000"+"5802ID") {
+		t.Errorf("tag nominal tidak disisipkan sebelum kode negara: %s", qr)
+	}
+	if _, err := DynamicQRIS("000201010211", 1000); err == nil {
+		t.Error("payload tanpa CRC seharusnya ditolak")
+	}
+}
@@ -0,0 +1,36 @@
+package payment
+
+import (
+	"net/http"
+	"time"
+)
+
+// Status pembayaran yang dikirim oleh provider lewat callback
+const (
+	CallbackPaid    = "paid"
+	CallbackExpired = "expired"
+)
+
+// Provider adalah kontrak yang harus dipenuhi setiap payment gateway
+type Provider interface {
+	// Name nama provider, dipakai juga sebagai parameter URL webhook: /webhook/payment/:provider
+	Name() string
+	// CreateIntent membuat tagihan (QRIS dinamis) untuk sebuah order, orderID dipakai sebagai referensi tagihan
+	CreateIntent(orderID string, amount float64) (Intent, error)
+	// ParseCallback memverifikasi tanda tangan callback dan mengembalikan isinya
+	ParseCallback(r *http.Request) (Callback, error)
+}
+
+type Intent struct {
+	Provider  string    `json:"provider" bson:"provider"`
+	Reference string    `json:"reference" bson:"reference"`
+	Amount    float64   `json:"amount" bson:"amount"`
+	QRString  string    `json:"qr_string,omitempty" bson:"qr_string,omitempty"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+type Callback struct {
+	Reference string  `json:"reference"`
+	Status    string  `json:"status"` // paid atau expired
+	Amount    float64 `json:"amount"`
+}
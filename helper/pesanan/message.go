@@ -0,0 +1,63 @@
+package pesanan
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/rupiah"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// template bawaan jika belum ada di collection prefill dengan key order-<status>
+var defaultOrderTemplates = map[string]string{
+	"terkirim":     "Halo *##NAMA##*, pesanan *##ORDERNUMBER##* sudah kami terima.\nNomor antrean: *##QUEUE##*\n##ITEMS##\nTotal: *##TOTAL##*\nPembayaran: ##PAYMENT##",
+	"diproses":     "Halo *##NAMA##*, pesanan *##ORDERNUMBER##* (antrean ##QUEUE##) sedang dibuat oleh barista kami.",
+	"siap diambil": "Halo *##NAMA##*, pesanan *##ORDERNUMBER##* (antrean ##QUEUE##) sudah *siap diambil* di kasir. Selamat menikmati!",
+	"dibatalkan":   "Halo *##NAMA##*, pesanan *##ORDERNUMBER##* telah dibatalkan.\n##REASON##",
+	"ditolak":      "Mohon maaf *##NAMA##*, pesanan *##ORDERNUMBER##* tidak dapat kami proses.\n##REASON##",
+}
+
+// GetOrderTemplate mengambil template pesan status order dari collection prefill, key: order-<status>
+// contoh key: order-terkirim, order-diproses, order-siap-diambil, order-dibatalkan, order-ditolak
+func GetOrderTemplate(status string, db *mongo.Database) (templ string) {
+	key := "order-" + strings.ReplaceAll(status, " ", "-")
+	prefill, err := atdb.GetOneDoc[model.Prefill](db, "prefill", bson.M{"key": key})
+	if err == nil && prefill.Value != "" {
+		return strings.ReplaceAll(prefill.Value, "\\n", "\n")
+	}
+	return defaultOrderTemplates[status]
+}
+
+// OrderStatusMessage mengisi template dengan data order
+func OrderStatusMessage(order model.Order, templ string) string {
+	var items []string
+	for _, item := range order.Orders {
+		line := "- " + item.MenuName
+		if len(item.Modifiers) > 0 {
+			var options []string
+			for _, m := range item.Modifiers {
+				options = append(options, m.Option)
+			}
+			line += " (" + strings.Join(options, ", ") + ")"
+		}
+		items = append(items, line+" x"+strconv.Itoa(item.Quantity))
+	}
+	var reason string
+	if n := len(order.StatusHistory); n > 0 && order.StatusHistory[n-1].Reason != "" {
+		reason = "Alasan: " + order.StatusHistory[n-1].Reason
+	}
+	replacer := strings.NewReplacer(
+		"##NAMA##", order.UserInfo.Name,
+		"##ORDERNUMBER##", order.OrderNumber,
+		"##QUEUE##", strconv.Itoa(order.QueueNumber),
+		"##ITEMS##", strings.Join(items, "\n"),
+		"##TOTAL##", rupiah.Format(order.Total),
+		"##PAYMENT##", order.PaymentMethod,
+		"##STATUS##", order.Status,
+		"##REASON##", reason,
+	)
+	return strings.TrimSpace(replacer.Replace(templ))
+}
@@ -0,0 +1,62 @@
+package pesanan
+
+import (
+	"testing"
+
+	"github.com/gocroot/model"
+)
+
+func TestOrderStatusMessage(t *testing.T) {
+	order := model.Order{
+		OrderNumber:   "LGC2024100100013",
+		QueueNumber:   1,
+		UserInfo:      model.UserInfo{Name: "Budi"},
+		PaymentMethod: "Cash",
+		Status:        model.OrderStatusTerkirim,
+		Total:         47000,
+		Orders: []model.OrderItem{
+			{MenuName: "Kopi Susu", Quantity: 2, Modifiers: []model.SelectedModifier{{Group: "Ukuran", Option: "Large"}, {Group: "Gula", Option: "Less Sugar"}}},
+			{MenuName: "Croissant", Quantity: 1},
+		},
+	}
+	dibatalkan := order
+	dibatalkan.Status = model.OrderStatusDibatalkan
+	dibatalkan.StatusHistory = []model.OrderStatusHistory{{To: model.OrderStatusDibatalkan, Reason: "stok habis"}}
+
+	tests := []struct {
+		name  string
+		order model.Order
+		templ string
+		want  string
+	}{
+		{
+			name:  "template terkirim",
+			order: order,
+			templ: defaultOrderTemplates["terkirim"],
+			want:  "Halo *Budi*, pesanan *LGC2024100100013* sudah kami terima.\nNomor antrean: *1*\n- Kopi Susu (Large, Less Sugar) x2\n- Croissant x1\nTotal: *Rp 47.000,00*\nPembayaran: Cash",
+		},
+		{
+			name:  "alasan pembatalan dari riwayat status",
+			order: dibatalkan,
+			templ: defaultOrderTemplates["dibatalkan"],
+			want:  "Halo *Budi*, pesanan *LGC2024100100013* telah dibatalkan.\nAlasan: stok habis",
+		},
+		{
+			name:  "tanpa alasan baris kosong dibuang",
+			order: order,
+			templ: defaultOrderTemplates["ditolak"],
+			want:  "Mohon maaf *Budi*, pesanan *LGC2024100100013* tidak dapat kami proses.",
+		},
+		{
+			name:  "template dari prefill",
+			order: order,
+			templ: "Status ##ORDERNUMBER##: ##STATUS##",
+			want:  "Status LGC2024100100013: terkirim",
+		},
+	}
+	for _, tt := range tests {
+		if got := OrderStatusMessage(tt.order, tt.templ); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
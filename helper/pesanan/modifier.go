@@ -0,0 +1,121 @@
+package pesanan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gocroot/model"
+)
+
+// ModifierLimits - Jumlah opsi minimal dan maksimal yang boleh dipilih dalam satu grup, max 0 berarti tidak dibatasi
+func ModifierLimits(group model.ModifierGroup) (min, max int) {
+	min, max = group.Min, group.Max
+	if group.Type == model.ModifierSingle {
+		min, max = 0, 1
+	}
+	if group.Required && min < 1 {
+		min = 1
+	}
+	return
+}
+
+// ValidateModifierGroups - Mengecek susunan grup modifier sebelum disimpan ke menu dengan harga price.
+// Selisih harga boleh minus (misalnya ukuran kecil), asalkan tidak membuat harga menu minus.
+func ValidateModifierGroups(groups []model.ModifierGroup, price float64) error {
+	groupNames := make(map[string]bool)
+	for _, group := range groups {
+		if strings.TrimSpace(group.Name) == "" {
+			return errors.New("nama grup modifier wajib diisi")
+		}
+		if groupNames[group.Name] {
+			return errors.New("grup modifier '" + group.Name + "' tercantum lebih dari sekali")
+		}
+		groupNames[group.Name] = true
+		if group.Type != model.ModifierSingle && group.Type != model.ModifierMulti {
+			return errors.New("tipe grup '" + group.Name + "' harus 'single' atau 'multi'")
+		}
+		if len(group.Options) == 0 {
+			return errors.New("grup '" + group.Name + "' belum memiliki opsi")
+		}
+		min, max := ModifierLimits(group)
+		if min < 0 || max < 0 || (max > 0 && min > max) || min > len(group.Options) {
+			return errors.New("batas minimal/maksimal grup '" + group.Name + "' tidak valid")
+		}
+		optionNames := make(map[string]bool)
+		for _, option := range group.Options {
+			if strings.TrimSpace(option.Name) == "" {
+				return errors.New("nama opsi pada grup '" + group.Name + "' wajib diisi")
+			}
+			if optionNames[option.Name] {
+				return errors.New("opsi '" + option.Name + "' pada grup '" + group.Name + "' tercantum lebih dari sekali")
+			}
+			optionNames[option.Name] = true
+			if price+option.PriceDelta < 0 {
+				return fmt.Errorf("selisih harga opsi '%s' pada grup '%s' melebihi harga menu %.0f", option.Name, group.Name, price)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyModifiers - Memvalidasi opsi yang dipilih pelanggan terhadap grup modifier menu.
+// Selisih harga diambil dari data menu, bukan dari client. Urutan hasil mengikuti urutan di menu.
+// Kombinasi opsi yang membuat harga satuan minus ditolak.
+func ApplyModifiers(menu model.Menu, selected []model.SelectedModifier) (resolved []model.SelectedModifier, delta float64, err error) {
+	chosen := make(map[string]map[string]bool)
+	for _, s := range selected {
+		if chosen[s.Group] == nil {
+			chosen[s.Group] = make(map[string]bool)
+		}
+		if chosen[s.Group][s.Option] {
+			err = fmt.Errorf("%w: opsi '%s' pada menu %s dipilih lebih dari sekali", ErrOrderItemInvalid, s.Option, menu.Name)
+			return
+		}
+		chosen[s.Group][s.Option] = true
+	}
+
+	for _, group := range menu.Modifiers {
+		picks := chosen[group.Name]
+		delete(chosen, group.Name)
+		min, max := ModifierLimits(group)
+		if len(picks) < min {
+			err = fmt.Errorf("%w: pilih minimal %d opsi '%s' untuk menu %s", ErrOrderItemInvalid, min, group.Name, menu.Name)
+			return
+		}
+		if max > 0 && len(picks) > max {
+			err = fmt.Errorf("%w: maksimal %d opsi '%s' untuk menu %s", ErrOrderItemInvalid, max, group.Name, menu.Name)
+			return
+		}
+		for _, option := range group.Options {
+			if !picks[option.Name] {
+				continue
+			}
+			resolved = append(resolved, model.SelectedModifier{Group: group.Name, Option: option.Name, PriceDelta: option.PriceDelta})
+			delta += option.PriceDelta
+			delete(picks, option.Name)
+		}
+		for option := range picks {
+			err = fmt.Errorf("%w: opsi '%s' tidak ada pada '%s' menu %s", ErrOrderItemInvalid, option, group.Name, menu.Name)
+			return
+		}
+	}
+	for group := range chosen {
+		err = fmt.Errorf("%w: menu %s tidak memiliki pilihan '%s'", ErrOrderItemInvalid, menu.Name, group)
+		return
+	}
+	if menu.Price+delta < 0 {
+		err = fmt.Errorf("%w: harga menu %s dengan opsi yang dipilih menjadi minus", ErrOrderItemInvalid, menu.Name)
+		return
+	}
+	return
+}
+
+// ModifierLabel - Teks opsi yang dipilih untuk struk dan notifikasi, misalnya "Large, Less Sugar"
+func ModifierLabel(modifiers []model.SelectedModifier) string {
+	var options []string
+	for _, m := range modifiers {
+		options = append(options, m.Option)
+	}
+	return strings.Join(options, ", ")
+}
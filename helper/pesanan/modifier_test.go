@@ -0,0 +1,146 @@
+package pesanan
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gocroot/model"
+)
+
+var testMenu = model.Menu{
+	Name:  "Kopi Susu",
+	Price: 20000,
+	Modifiers: []model.ModifierGroup{
+		{Name: "Ukuran", Type: model.ModifierSingle, Required: true, Options: []model.ModifierOption{
+			{Name: "Regular"},
+			{Name: "Large", PriceDelta: 5000},
+		}},
+		{Name: "Tambahan", Type: model.ModifierMulti, Max: 2, Options: []model.ModifierOption{
+			{Name: "Extra Shot", PriceDelta: 4000},
+			{Name: "Oat Milk", PriceDelta: 6000},
+			{Name: "Sirup", PriceDelta: 3000},
+		}},
+	},
+}
+
+func TestApplyModifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		selected  []model.SelectedModifier
+		want      []model.SelectedModifier
+		wantDelta float64
+		wantErr   bool
+	}{
+		{
+			name:     "urutan mengikuti menu dan harga dari server",
+			selected: []model.SelectedModifier{{Group: "Tambahan", Option: "Oat Milk", PriceDelta: 1}, {Group: "Ukuran", Option: "Large"}, {Group: "Tambahan", Option: "Extra Shot"}},
+			want: []model.SelectedModifier{
+				{Group: "Ukuran", Option: "Large", PriceDelta: 5000},
+				{Group: "Tambahan", Option: "Extra Shot", PriceDelta: 4000},
+				{Group: "Tambahan", Option: "Oat Milk", PriceDelta: 6000},
+			},
+			wantDelta: 15000,
+		},
+		{
+			name:     "opsi tanpa selisih harga",
+			selected: []model.SelectedModifier{{Group: "Ukuran", Option: "Regular"}},
+			want:     []model.SelectedModifier{{Group: "Ukuran", Option: "Regular"}},
+		},
+		{name: "grup wajib tidak dipilih", selected: []model.SelectedModifier{{Group: "Tambahan", Option: "Sirup"}}, wantErr: true},
+		{name: "single dipilih dua", selected: []model.SelectedModifier{{Group: "Ukuran", Option: "Regular"}, {Group: "Ukuran", Option: "Large"}}, wantErr: true},
+		{
+			name: "multi melebihi maksimal",
+			selected: []model.SelectedModifier{
+				{Group: "Ukuran", Option: "Regular"},
+				{Group: "Tambahan", Option: "Extra Shot"}, {Group: "Tambahan", Option: "Oat Milk"}, {Group: "Tambahan", Option: "Sirup"},
+			},
+			wantErr: true,
+		},
+		{name: "opsi dipilih dua kali", selected: []model.SelectedModifier{{Group: "Ukuran", Option: "Large"}, {Group: "Ukuran", Option: "Large"}}, wantErr: true},
+		{name: "opsi tidak dikenal", selected: []model.SelectedModifier{{Group: "Ukuran", Option: "Jumbo"}}, wantErr: true},
+		{name: "grup tidak dikenal", selected: []model.SelectedModifier{{Group: "Ukuran", Option: "Large"}, {Group: "Topping", Option: "Boba"}}, wantErr: true},
+	}
+	// setiap opsi lolos ValidateModifierGroups, tetapi gabungannya membuat harga minus
+	cheap := model.Menu{Name: "Teh", Price: 5000, Modifiers: []model.ModifierGroup{
+		{Name: "Promo", Type: model.ModifierMulti, Options: []model.ModifierOption{{Name: "Tanpa Gula", PriceDelta: -3000}, {Name: "Tanpa Es", PriceDelta: -3000}}},
+	}}
+	if _, _, err := ApplyModifiers(cheap, []model.SelectedModifier{{Group: "Promo", Option: "Tanpa Gula"}}); err != nil {
+		t.Errorf("harga 2000: %v", err)
+	}
+	if _, _, err := ApplyModifiers(cheap, []model.SelectedModifier{{Group: "Promo", Option: "Tanpa Gula"}, {Group: "Promo", Option: "Tanpa Es"}}); !errors.Is(err, ErrOrderItemInvalid) {
+		t.Errorf("harga minus: err = %v, want ErrOrderItemInvalid", err)
+	}
+
+	for _, tt := range tests {
+		got, delta, err := ApplyModifiers(testMenu, tt.selected)
+		if tt.wantErr {
+			if !errors.Is(err, ErrOrderItemInvalid) {
+				t.Errorf("%s: err = %v, want ErrOrderItemInvalid", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) || delta != tt.wantDelta {
+			t.Errorf("%s: got %+v delta %.0f, want %+v delta %.0f", tt.name, got, delta, tt.want, tt.wantDelta)
+		}
+	}
+}
+
+func TestModifierLimits(t *testing.T) {
+	tests := []struct {
+		group    model.ModifierGroup
+		min, max int
+	}{
+		{model.ModifierGroup{Type: model.ModifierSingle, Min: 2, Max: 3}, 0, 1},
+		{model.ModifierGroup{Type: model.ModifierSingle, Required: true}, 1, 1},
+		{model.ModifierGroup{Type: model.ModifierMulti, Max: 2}, 0, 2},
+		{model.ModifierGroup{Type: model.ModifierMulti, Required: true}, 1, 0},
+		{model.ModifierGroup{Type: model.ModifierMulti, Required: true, Min: 2}, 2, 0},
+	}
+	for _, tt := range tests {
+		if min, max := ModifierLimits(tt.group); min != tt.min || max != tt.max {
+			t.Errorf("ModifierLimits(%+v) = %d, %d, want %d, %d", tt.group, min, max, tt.min, tt.max)
+		}
+	}
+}
+
+func TestValidateModifierGroups(t *testing.T) {
+	option := []model.ModifierOption{{Name: "Regular"}}
+	tests := []struct {
+		name    string
+		groups  []model.ModifierGroup
+		wantErr bool
+	}{
+		{"tanpa modifier", nil, false},
+		{"valid", testMenu.Modifiers, false},
+		{"nama grup kosong", []model.ModifierGroup{{Name: " ", Type: model.ModifierSingle, Options: option}}, true},
+		{"grup ganda", []model.ModifierGroup{{Name: "Ukuran", Type: model.ModifierSingle, Options: option}, {Name: "Ukuran", Type: model.ModifierSingle, Options: option}}, true},
+		{"tipe salah", []model.ModifierGroup{{Name: "Ukuran", Type: "radio", Options: option}}, true},
+		{"tanpa opsi", []model.ModifierGroup{{Name: "Ukuran", Type: model.ModifierSingle}}, true},
+		{"min melebihi max", []model.ModifierGroup{{Name: "Tambahan", Type: model.ModifierMulti, Min: 3, Max: 2, Options: option}}, true},
+		{"min melebihi jumlah opsi", []model.ModifierGroup{{Name: "Tambahan", Type: model.ModifierMulti, Min: 2, Options: option}}, true},
+		{"nama opsi kosong", []model.ModifierGroup{{Name: "Ukuran", Type: model.ModifierSingle, Options: []model.ModifierOption{{Name: ""}}}}, true},
+		{"opsi ganda", []model.ModifierGroup{{Name: "Ukuran", Type: model.ModifierSingle, Options: []model.ModifierOption{{Name: "Large"}, {Name: "Large"}}}}, true},
+		{"potongan sama dengan harga", []model.ModifierGroup{{Name: "Ukuran", Type: model.ModifierSingle, Options: []model.ModifierOption{{Name: "Small", PriceDelta: -20000}}}}, false},
+		{"potongan melebihi harga", []model.ModifierGroup{{Name: "Ukuran", Type: model.ModifierSingle, Options: []model.ModifierOption{{Name: "Small", PriceDelta: -25000}}}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateModifierGroups(tt.groups, testMenu.Price); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestModifierLabel(t *testing.T) {
+	modifiers := []model.SelectedModifier{{Group: "Ukuran", Option: "Large"}, {Group: "Gula", Option: "Less Sugar"}}
+	if got := ModifierLabel(modifiers); got != "Large, Less Sugar" {
+		t.Errorf("ModifierLabel = %q", got)
+	}
+	if got := ModifierLabel(nil); got != "" {
+		t.Errorf("ModifierLabel(nil) = %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package pesanan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GenerateOrderNumber - Menghasilkan nomor order dan nomor antrean baru.
+// Nomor antrean diambil dari counter di MongoDB per hari (zona waktu Asia/Jakarta),
+// sehingga tetap unik dan berurutan walaupun Cloud Function berjalan di banyak instance.
+func GenerateOrderNumber(db *mongo.Database) (orderNumber string, queueNumber int, err error) {
+	return OrderNumberAt(db, time.Now())
+}
+
+// OrderNumberAt - Seperti GenerateOrderNumber untuk tanggal tertentu, dipakai saat migrasi order lama
+func OrderNumberAt(db *mongo.Database, t time.Time) (orderNumber string, queueNumber int, err error) {
+	location, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return
+	}
+	currentDate := t.In(location).Format("20060102")
+
+	queueNumber, err = atdb.IncrementCounter(db, "counter", "queue-"+currentDate)
+	if err != nil {
+		return
+	}
+
+	// Membuat nomor order dengan format: LGCYYYYMMDD + queueNumber + digit pengecek
+	digits := fmt.Sprintf("%s%04d", currentDate, queueNumber)
+	orderNumber = "LGC" + digits + strconv.Itoa(OrderCheckDigit(digits))
+	return
+}
+
+// OrderCheckDigit - Menghitung digit pengecek Luhn dari deretan angka nomor order
+func OrderCheckDigit(digits string) int {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
+
+// IsValidOrderNumber - Memeriksa format dan digit pengecek nomor order LGC, untuk mendeteksi salah ketik kasir
+func IsValidOrderNumber(orderNumber string) bool {
+	digits, found := strings.CutPrefix(strings.ToUpper(strings.TrimSpace(orderNumber)), "LGC")
+	if !found || len(digits) < 2 {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	last := len(digits) - 1
+	return OrderCheckDigit(digits[:last]) == int(digits[last]-'0')
+}
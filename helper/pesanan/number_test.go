@@ -0,0 +1,40 @@
+package pesanan
+
+import "testing"
+
+func TestOrderCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   int
+	}{
+		{"7992739871", 3}, // contoh baku algoritma Luhn
+		{"202410010001", 3},
+		{"202412310042", 0},
+	}
+	for _, tt := range tests {
+		if got := OrderCheckDigit(tt.digits); got != tt.want {
+			t.Errorf("OrderCheckDigit(%s) = %d, want %d", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidOrderNumber(t *testing.T) {
+	tests := []struct {
+		orderNumber string
+		want        bool
+	}{
+		{"LGC2024100100013", true},
+		{" lgc2024123100420 ", true},
+		{"LGC2024100100014", false}, // digit pengecek salah
+		{"LGC2024100100031", false}, // angka antrean tertukar
+		{"2024100100013", false},
+		{"LGC20241001000A3", false},
+		{"LGC3", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidOrderNumber(tt.orderNumber); got != tt.want {
+			t.Errorf("IsValidOrderNumber(%q) = %v, want %v", tt.orderNumber, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package pesanan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrOrderItemInvalid - Item pesanan tidak dapat diproses (menu tidak ada, tidak tersedia, atau kuantitas salah)
+var ErrOrderItemInvalid = errors.New("item pesanan tidak valid")
+
+// IsMenuAvailable - Mengecek apakah status menu yang diatur admin masih bisa dipesan
+func IsMenuAvailable(status string) bool {
+	return !strings.EqualFold(status, "Tidak Tersedia") && !strings.EqualFold(status, "habis")
+}
+
+// IsMenuOrderable - Menu bisa dipesan jika statusnya tersedia dan stok bahan resepnya cukup
+func IsMenuOrderable(menu model.Menu) bool {
+	return IsMenuAvailable(menu.Status) && !menu.OutOfStock
+}
+
+// PriceOrderItems - Mencocokkan setiap item dengan koleksi menu, menyalin nama dan harga resmi,
+// lalu menghitung total pesanan di server. Harga dari client hanya dipakai untuk dilaporkan bila berbeda.
+func PriceOrderItems(db *mongo.Database, items []model.OrderItem) (priced []model.OrderItem, total float64, discrepancies []model.PriceDiscrepancy, err error) {
+	if len(items) == 0 {
+		err = fmt.Errorf("%w: pesanan tidak memiliki item", ErrOrderItemInvalid)
+		return
+	}
+
+	// Ambil semua menu yang dipesan dalam satu query
+	var menuIDs []primitive.ObjectID
+	for _, item := range items {
+		menuIDs = append(menuIDs, item.MenuID)
+	}
+	// menu yang sudah dihapus (soft delete) tidak bisa dipesan lagi
+	menus, err := atdb.GetAllDoc[[]model.Menu](db, "menu", atdb.ExcludeDeleted(bson.M{"_id": bson.M{"$in": menuIDs}}))
+	if err != nil {
+		return
+	}
+	menuByID := make(map[primitive.ObjectID]model.Menu, len(menus))
+	for _, menu := range menus {
+		menuByID[menu.ID] = menu
+	}
+	return priceItems(items, menuByID)
+}
+
+// priceItems - Menghitung harga setiap item dari data menu, dipisah dari query supaya bisa diuji tanpa database
+func priceItems(items []model.OrderItem, menuByID map[primitive.ObjectID]model.Menu) (priced []model.OrderItem, total float64, discrepancies []model.PriceDiscrepancy, err error) {
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			err = fmt.Errorf("%w: kuantitas untuk menu %s harus lebih dari 0", ErrOrderItemInvalid, item.MenuID.Hex())
+			return
+		}
+		menu, ok := menuByID[item.MenuID]
+		if !ok {
+			err = fmt.Errorf("%w: menu %s tidak ditemukan", ErrOrderItemInvalid, item.MenuID.Hex())
+			return
+		}
+		if !IsMenuOrderable(menu) {
+			err = fmt.Errorf("%w: menu %s sedang tidak tersedia", ErrOrderItemInvalid, menu.Name)
+			return
+		}
+		// Harga satuan = harga menu + selisih harga opsi yang dipilih
+		modifiers, delta, errModifier := ApplyModifiers(menu, item.Modifiers)
+		if errModifier != nil {
+			err = errModifier
+			return
+		}
+		price := menu.Price + delta
+		if item.Price != price {
+			discrepancies = append(discrepancies, model.PriceDiscrepancy{
+				MenuID:      menu.ID,
+				MenuName:    menu.Name,
+				ClientPrice: item.Price,
+				ServerPrice: price,
+			})
+		}
+		priced = append(priced, model.OrderItem{
+			MenuID:    menu.ID,
+			MenuName:  menu.Name,
+			Price:     price,
+			Quantity:  item.Quantity,
+			Modifiers: modifiers,
+		})
+		total += price * float64(item.Quantity)
+	}
+	return
+}
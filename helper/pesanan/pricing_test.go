@@ -0,0 +1,89 @@
+package pesanan
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIsMenuAvailable(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{"Tersedia", true},
+		{"", true},
+		{"Tidak Tersedia", false},
+		{"tidak tersedia", false},
+		{"Habis", false},
+	}
+	for _, tt := range tests {
+		if got := IsMenuAvailable(tt.status); got != tt.want {
+			t.Errorf("IsMenuAvailable(%q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestPriceItems(t *testing.T) {
+	kopi := model.Menu{ID: primitive.NewObjectID(), Name: "Kopi Susu", Price: 20000, Status: "Tersedia"}
+	croissant := model.Menu{ID: primitive.NewObjectID(), Name: "Croissant", Price: 15000, Status: "Tersedia"}
+	habis := model.Menu{ID: primitive.NewObjectID(), Name: "Matcha", Price: 25000, Status: "Tidak Tersedia"}
+	menuByID := map[primitive.ObjectID]model.Menu{kopi.ID: kopi, croissant.ID: croissant, habis.ID: habis}
+
+	// harga dan nama dari client diabaikan, selisih harga dilaporkan
+	priced, total, discrepancies, err := priceItems([]model.OrderItem{
+		{MenuID: kopi.ID, MenuName: "Kopi Murah", Price: 1000, Quantity: 2},
+		{MenuID: croissant.ID, Price: 15000, Quantity: 1},
+	}, menuByID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPriced := []model.OrderItem{
+		{MenuID: kopi.ID, MenuName: "Kopi Susu", Price: 20000, Quantity: 2},
+		{MenuID: croissant.ID, MenuName: "Croissant", Price: 15000, Quantity: 1},
+	}
+	if !reflect.DeepEqual(priced, wantPriced) {
+		t.Errorf("priced = %+v, want %+v", priced, wantPriced)
+	}
+	if total != 55000 {
+		t.Errorf("total = %.0f, want 55000", total)
+	}
+	wantDiscrepancies := []model.PriceDiscrepancy{{MenuID: kopi.ID, MenuName: "Kopi Susu", ClientPrice: 1000, ServerPrice: 20000}}
+	if !reflect.DeepEqual(discrepancies, wantDiscrepancies) {
+		t.Errorf("discrepancies = %+v, want %+v", discrepancies, wantDiscrepancies)
+	}
+
+	invalid := []struct {
+		name string
+		item model.OrderItem
+	}{
+		{"kuantitas nol", model.OrderItem{MenuID: kopi.ID, Quantity: 0}},
+		{"menu tidak ada", model.OrderItem{MenuID: primitive.NewObjectID(), Quantity: 1}},
+		{"menu tidak tersedia", model.OrderItem{MenuID: habis.ID, Quantity: 1}},
+	}
+	for _, tt := range invalid {
+		if _, _, _, err := priceItems([]model.OrderItem{tt.item}, menuByID); !errors.Is(err, ErrOrderItemInvalid) {
+			t.Errorf("%s: err = %v, want ErrOrderItemInvalid", tt.name, err)
+		}
+	}
+}
+
+func TestIsMenuOrderable(t *testing.T) {
+	tests := []struct {
+		menu model.Menu
+		want bool
+	}{
+		{model.Menu{Status: "Tersedia"}, true},
+		{model.Menu{Status: "Tersedia", OutOfStock: true}, false},
+		{model.Menu{Status: "Tidak Tersedia"}, false},
+		{model.Menu{Status: "Tidak Tersedia", OutOfStock: true}, false},
+	}
+	for _, tt := range tests {
+		if got := IsMenuOrderable(tt.menu); got != tt.want {
+			t.Errorf("IsMenuOrderable(%q, out_of_stock %v) = %v, want %v", tt.menu.Status, tt.menu.OutOfStock, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package pesanan
+
+import (
+	"errors"
+
+	"github.com/gocroot/model"
+)
+
+// Pelaku yang boleh memindahkan status pesanan
+const (
+	ActorStaff   = "staff"   // kasir/admin
+	ActorOwner   = "owner"   // pelanggan pemilik pesanan
+	ActorKitchen = "kitchen" // barista/dapur yang menandai item siap dari layar stasiun
+)
+
+// orderTransitions - status asal -> status tujuan -> siapa yang boleh
+var orderTransitions = map[string]map[string][]string{
+	model.OrderStatusTerkirim: {
+		model.OrderStatusDiproses:   {ActorStaff},
+		model.OrderStatusDibatalkan: {ActorStaff, ActorOwner},
+		model.OrderStatusDitolak:    {ActorStaff},
+	},
+	model.OrderStatusDiproses: {
+		model.OrderStatusSiapDiambil: {ActorStaff, ActorKitchen},
+		model.OrderStatusDibatalkan:  {ActorStaff},
+	},
+	model.OrderStatusSiapDiambil: {
+		model.OrderStatusSelesai: {ActorStaff},
+	},
+}
+
+// CheckTransition - Mengecek apakah status pesanan boleh dipindah ke status tujuan oleh salah satu actors.
+// prepaid true jika pesanan dibayar lewat payment provider, sehingga harus lunas sebelum diproses.
+func CheckTransition(order model.Order, to string, prepaid bool, actors ...string) error {
+	next, ok := orderTransitions[order.Status]
+	if !ok {
+		return errors.New("pesanan dengan status '" + order.Status + "' sudah tidak dapat diubah")
+	}
+	allowed, ok := next[to]
+	if !ok {
+		return errors.New("status tidak dapat diubah dari '" + order.Status + "' menjadi '" + to + "'")
+	}
+	if to == model.OrderStatusDiproses && prepaid && order.PaymentStatus != model.PaymentStatusLunas {
+		return errors.New("pesanan " + order.PaymentMethod + " belum dibayar, status pembayaran: '" + order.PaymentStatus + "'")
+	}
+	for _, actor := range actors {
+		for _, a := range allowed {
+			if actor == a {
+				return nil
+			}
+		}
+	}
+	return errors.New("anda tidak memiliki akses untuk mengubah status pesanan menjadi '" + to + "'")
+}
@@ -0,0 +1,41 @@
+package pesanan
+
+import (
+	"testing"
+
+	"github.com/gocroot/model"
+)
+
+func TestCheckTransition(t *testing.T) {
+	terkirim := model.Order{Status: model.OrderStatusTerkirim, PaymentMethod: "Cash"}
+	belumBayar := model.Order{Status: model.OrderStatusTerkirim, PaymentMethod: "QRIS", PaymentStatus: model.PaymentStatusMenunggu}
+	lunas := model.Order{Status: model.OrderStatusTerkirim, PaymentMethod: "QRIS", PaymentStatus: model.PaymentStatusLunas}
+	diproses := model.Order{Status: model.OrderStatusDiproses}
+
+	tests := []struct {
+		name    string
+		order   model.Order
+		to      string
+		prepaid bool
+		actors  []string
+		wantErr bool
+	}{
+		{"kasir memproses pesanan tunai", terkirim, model.OrderStatusDiproses, false, []string{ActorStaff}, false},
+		{"pelanggan tidak bisa memproses", terkirim, model.OrderStatusDiproses, false, []string{ActorOwner}, true},
+		{"pelanggan membatalkan pesanannya", terkirim, model.OrderStatusDibatalkan, false, []string{ActorOwner}, false},
+		{"pelanggan tidak bisa membatalkan yang sudah diproses", diproses, model.OrderStatusDibatalkan, false, []string{ActorOwner}, true},
+		{"dapur menandai siap diambil", diproses, model.OrderStatusSiapDiambil, false, []string{ActorKitchen}, false},
+		{"dapur tidak bisa menolak pesanan", terkirim, model.OrderStatusDitolak, false, []string{ActorKitchen}, true},
+		{"tanpa peran", terkirim, model.OrderStatusDibatalkan, false, nil, true},
+		{"tidak bisa melompati status", terkirim, model.OrderStatusSelesai, false, []string{ActorStaff}, true},
+		{"status akhir tidak bisa diubah", model.Order{Status: model.OrderStatusSelesai}, model.OrderStatusDiproses, false, []string{ActorStaff}, true},
+		{"QRIS belum dibayar tidak bisa diproses", belumBayar, model.OrderStatusDiproses, true, []string{ActorStaff}, true},
+		{"QRIS belum dibayar tetap bisa dibatalkan", belumBayar, model.OrderStatusDibatalkan, true, []string{ActorStaff}, false},
+		{"QRIS lunas diproses", lunas, model.OrderStatusDiproses, true, []string{ActorStaff}, false},
+	}
+	for _, tt := range tests {
+		if err := CheckTransition(tt.order, tt.to, tt.prepaid, tt.actors...); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -8,3 +8,18 @@ func MaskPhoneNumber(phone string) string {
 	// Ambil bagian pertama dari nomor telepon hingga posisi ke-6, tambahkan "xxx", lalu tambahkan sisa dari digit ke-9
 	return phone[:6] + "xxx" + phone[9:]
 }
+
+// NormalizePhoneNumber mengubah nomor 08xx / +628xx menjadi format 628xx yang dipakai WhatsApp API
+func NormalizePhoneNumber(phone string) string {
+	var digits []rune
+	for _, c := range phone {
+		if c >= '0' && c <= '9' {
+			digits = append(digits, c)
+		}
+	}
+	normalized := string(digits)
+	if len(normalized) > 0 && normalized[0] == '0' {
+		normalized = "62" + normalized[1:]
+	}
+	return normalized
+}
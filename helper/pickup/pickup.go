@@ -0,0 +1,247 @@
+package pickup
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/kitchen"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collection nama koleksi pengaturan pesanan terjadwal, hanya berisi satu dokumen dengan _id scheduleID
+const Collection = "pickup_schedule"
+
+const scheduleID = "default"
+
+// slotCollection counter kuota minuman per slot, _id berformat keyLayout pada zona Asia/Jakarta
+const slotCollection = "pickup_slot"
+
+const keyLayout = "2006-01-02T15:04"
+
+// ErrSlotInvalid - Waktu ambil di luar jam buka, sudah lewat atau terlalu jauh ke depan
+var ErrSlotInvalid = errors.New("waktu ambil tidak tersedia")
+
+// ErrSlotFull - Kuota minuman slot tidak cukup untuk pesanan ini
+var ErrSlotFull = errors.New("slot waktu ambil sudah penuh")
+
+// Slot satu rentang waktu ambil beserta pemakaian kuotanya
+type Slot struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Capacity  int       `json:"capacity"` // 0 berarti tidak dibatasi
+	Used      int       `json:"used"`
+	Available bool      `json:"available"`
+}
+
+// DefaultSchedule dipakai selama admin belum menyimpan pengaturan: setiap hari 07:00-21:00,
+// slot 15 menit dengan 20 minuman, masuk antrean 20 menit sebelum diambil, bisa dipesan 7 hari ke depan
+func DefaultSchedule() model.PickupSchedule {
+	s := model.PickupSchedule{ID: scheduleID, LeadMinutes: 20, MaxDaysAhead: 7}
+	for weekday := 0; weekday < 7; weekday++ {
+		s.Days = append(s.Days, model.PickupDay{Weekday: weekday, Open: "07:00", Close: "21:00", SlotMinutes: 15, MaxDrinks: 20})
+	}
+	return s
+}
+
+// GetSchedule mengambil pengaturan dari database, atau DefaultSchedule jika belum ada
+func GetSchedule(db *mongo.Database) (model.PickupSchedule, error) {
+	s, err := atdb.GetOneDoc[model.PickupSchedule](db, Collection, bson.M{"_id": scheduleID})
+	if err == mongo.ErrNoDocuments {
+		return DefaultSchedule(), nil
+	}
+	return s, err
+}
+
+// SaveSchedule menyimpan pengaturan setelah divalidasi
+func SaveSchedule(db *mongo.Database, s model.PickupSchedule) (model.PickupSchedule, error) {
+	s.ID = scheduleID
+	_, err := atdb.ReplaceOneDoc(db, Collection, bson.M{"_id": scheduleID}, s)
+	return s, err
+}
+
+// Validate - Mengecek pengaturan sebelum disimpan oleh admin. Hari yang tidak diisi dianggap tutup.
+func Validate(s model.PickupSchedule) error {
+	seen := make(map[int]bool, len(s.Days))
+	for _, day := range s.Days {
+		if day.Weekday < 0 || day.Weekday > 6 {
+			return errors.New("weekday harus 0 (Minggu) sampai 6 (Sabtu)")
+		}
+		if seen[day.Weekday] {
+			return fmt.Errorf("weekday %d diisi lebih dari sekali", day.Weekday)
+		}
+		seen[day.Weekday] = true
+		if day.Closed {
+			continue
+		}
+		opensAt, errOpen := parseClock(day.Open)
+		closesAt, errClose := parseClock(day.Close)
+		if errOpen != nil || errClose != nil {
+			return fmt.Errorf("jam buka dan tutup weekday %d harus berformat HH:MM", day.Weekday)
+		}
+		if closesAt <= opensAt {
+			return fmt.Errorf("jam tutup weekday %d harus setelah jam buka", day.Weekday)
+		}
+		if day.SlotMinutes < 5 || day.SlotMinutes > 240 {
+			return fmt.Errorf("panjang slot weekday %d harus 5 sampai 240 menit", day.Weekday)
+		}
+		if day.MaxDrinks < 0 {
+			return fmt.Errorf("kuota minuman weekday %d tidak boleh minus", day.Weekday)
+		}
+	}
+	if s.LeadMinutes < 0 || s.LeadMinutes > 24*60 {
+		return errors.New("lead_minutes harus 0 sampai 1440 menit")
+	}
+	if s.MaxDaysAhead < 0 || s.MaxDaysAhead > 60 {
+		return errors.New("max_days_ahead harus 0 sampai 60 hari")
+	}
+	return nil
+}
+
+// parseClock mengubah "HH:MM" menjadi jumlah menit sejak tengah malam
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// day mengambil pengaturan hari tersebut, ok false jika toko tutup
+func day(s model.PickupSchedule, weekday time.Weekday) (d model.PickupDay, ok bool) {
+	for _, d = range s.Days {
+		if d.Weekday == int(weekday) {
+			return d, !d.Closed
+		}
+	}
+	return model.PickupDay{}, false
+}
+
+// midnight awal hari t pada zona waktu loc
+func midnight(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// DaySlots semua slot pada tanggal date (zona loc). used berisi pemakaian kuota per Key slot,
+// slot yang sudah dimulai atau sudah di luar batas hari ke depan ditandai tidak tersedia.
+func DaySlots(s model.PickupSchedule, date time.Time, loc *time.Location, now time.Time, used map[string]int) []Slot {
+	slots := []Slot{}
+	start := midnight(date, loc)
+	d, ok := day(s, start.Weekday())
+	if !ok {
+		return slots
+	}
+	opensAt, errOpen := parseClock(d.Open)
+	closesAt, errClose := parseClock(d.Close)
+	if errOpen != nil || errClose != nil || d.SlotMinutes <= 0 {
+		return slots
+	}
+	length := time.Duration(d.SlotMinutes) * time.Minute
+	lastDay := midnight(now, loc).AddDate(0, 0, s.MaxDaysAhead)
+	for offset := opensAt; offset+length <= closesAt; offset += length {
+		slot := Slot{Start: start.Add(offset), End: start.Add(offset + length), Capacity: d.MaxDrinks}
+		slot.Used = used[Key(slot.Start)]
+		slot.Available = slot.Start.After(now) && !start.After(lastDay) && (slot.Capacity == 0 || slot.Used < slot.Capacity)
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+// SlotFor mencari slot yang memuat waktu ambil yang diminta pelanggan. Slot yang sudah dimulai,
+// di luar jam buka atau melewati batas hari ke depan ditolak dengan ErrSlotInvalid. Kuota tidak dicek di sini.
+func SlotFor(s model.PickupSchedule, requested time.Time, loc *time.Location, now time.Time) (Slot, error) {
+	requested = requested.In(loc)
+	date := midnight(requested, loc)
+	d, ok := day(s, date.Weekday())
+	if !ok {
+		return Slot{}, fmt.Errorf("%w: toko tutup pada %s", ErrSlotInvalid, date.Format("02-01-2006"))
+	}
+	if date.After(midnight(now, loc).AddDate(0, 0, s.MaxDaysAhead)) {
+		return Slot{}, fmt.Errorf("%w: pesanan terjadwal paling lambat %d hari ke depan", ErrSlotInvalid, s.MaxDaysAhead)
+	}
+	for _, slot := range DaySlots(s, date, loc, now, nil) {
+		if requested.Before(slot.Start) || !requested.Before(slot.End) {
+			continue
+		}
+		if !slot.Start.After(now) {
+			return Slot{}, fmt.Errorf("%w: slot %s sudah dimulai, pilih slot berikutnya", ErrSlotInvalid, slot.Start.Format("15:04"))
+		}
+		return slot, nil
+	}
+	return Slot{}, fmt.Errorf("%w: di luar jam buka %s-%s", ErrSlotInvalid, d.Open, d.Close)
+}
+
+// QueueAt waktu pesanan terjadwal mulai tampil di antrean aktif kasir dan dapur
+func QueueAt(s model.PickupSchedule, pickupAt time.Time) time.Time {
+	return pickupAt.Add(-time.Duration(s.LeadMinutes) * time.Minute)
+}
+
+// Drinks jumlah minuman pada pesanan, yaitu kuantitas item yang disiapkan di stasiun bar
+func Drinks(items []model.OrderItem) (drinks int) {
+	for _, item := range items {
+		station := item.Station
+		if station == "" {
+			station = kitchen.DefaultStation
+		}
+		if station == model.StationBar {
+			drinks += item.Quantity
+		}
+	}
+	return
+}
+
+// Key id counter kuota untuk slot yang dimulai pada start
+func Key(start time.Time) string {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err == nil {
+		start = start.In(loc)
+	}
+	return start.Format(keyLayout)
+}
+
+// Claim memakai kuota minuman slot secara atomik, ErrSlotFull jika sisa kuota tidak cukup.
+// Kuota yang sudah diklaim harus dikembalikan dengan Release jika pesanan gagal dibuat atau dibatalkan.
+func Claim(db *mongo.Database, slot Slot, drinks int) error {
+	if drinks == 0 || slot.Capacity == 0 {
+		return nil
+	}
+	_, ok, err := atdb.AddCounterLimit(db, slotCollection, Key(slot.Start), drinks, slot.Capacity)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: slot %s tidak cukup untuk %d minuman", ErrSlotFull, slot.Start.Format("15:04"), drinks)
+	}
+	return nil
+}
+
+// Release mengembalikan kuota slot yang dipakai pesanan
+func Release(db *mongo.Database, order model.Order) error {
+	if order.PickupDrinks == 0 || order.PickupAt.IsZero() {
+		return nil
+	}
+	_, err := atdb.UpdateDoc(db, slotCollection, bson.M{"_id": Key(order.PickupAt)}, bson.M{"$inc": bson.M{"seq": -order.PickupDrinks}})
+	return err
+}
+
+// Used pemakaian kuota semua slot pada tanggal date, kunci map sama dengan Key
+func Used(db *mongo.Database, date time.Time, loc *time.Location) (map[string]int, error) {
+	prefix := "^" + regexp.QuoteMeta(midnight(date, loc).Format("2006-01-02")) + "T"
+	counters, err := atdb.GetAllDoc[[]struct {
+		Key string `bson:"_id"`
+		Seq int    `bson:"seq"`
+	}](db, slotCollection, bson.M{"_id": bson.M{"$regex": prefix}})
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]int, len(counters))
+	for _, counter := range counters {
+		used[counter.Key] = counter.Seq
+	}
+	return used, nil
+}
@@ -0,0 +1,81 @@
+package pickup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocroot/model"
+)
+
+func TestSlotFor(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip(err)
+	}
+	s := DefaultSchedule()
+	s.Days[0].Closed = true                         // Minggu tutup
+	now := time.Date(2024, 10, 1, 8, 20, 0, 0, loc) // Selasa
+
+	slot, err := SlotFor(s, time.Date(2024, 10, 2, 8, 40, 0, 0, loc), loc, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 10, 2, 8, 30, 0, 0, loc); !slot.Start.Equal(want) || slot.Capacity != 20 {
+		t.Errorf("slot = %+v, want mulai %v", slot, want)
+	}
+	if Key(slot.Start) != "2024-10-02T08:30" {
+		t.Errorf("Key = %s", Key(slot.Start))
+	}
+	if got := QueueAt(s, slot.Start); !got.Equal(slot.Start.Add(-20 * time.Minute)) {
+		t.Errorf("QueueAt = %v", got)
+	}
+
+	for name, requested := range map[string]time.Time{
+		"slot sudah dimulai": time.Date(2024, 10, 1, 8, 25, 0, 0, loc),
+		"sebelum buka":       time.Date(2024, 10, 2, 6, 30, 0, 0, loc),
+		"setelah tutup":      time.Date(2024, 10, 2, 21, 0, 0, 0, loc),
+		"hari tutup":         time.Date(2024, 10, 6, 9, 0, 0, 0, loc),
+		"terlalu jauh":       time.Date(2024, 10, 9, 9, 0, 0, 0, loc),
+	} {
+		if _, err := SlotFor(s, requested, loc, now); !errors.Is(err, ErrSlotInvalid) {
+			t.Errorf("%s: err = %v, want ErrSlotInvalid", name, err)
+		}
+	}
+
+	slots := DaySlots(s, now, loc, now, map[string]int{"2024-10-01T08:45": 20})
+	if len(slots) != 56 {
+		t.Fatalf("jumlah slot = %d, want 56", len(slots))
+	}
+	if slots[5].Available || !slots[6].Available || slots[7].Available || !slots[8].Available {
+		t.Errorf("ketersediaan slot 08:15-09:00 salah: %+v", slots[5:9])
+	}
+	if len(DaySlots(s, time.Date(2024, 10, 6, 0, 0, 0, 0, loc), loc, now, nil)) != 0 {
+		t.Error("hari tutup seharusnya tidak punya slot")
+	}
+}
+
+func TestDrinksAndValidate(t *testing.T) {
+	items := []model.OrderItem{
+		{MenuName: "Kopi Susu", Quantity: 2, Station: model.StationBar},
+		{MenuName: "Croissant", Quantity: 3, Station: model.StationPastry},
+		{MenuName: "Teh", Quantity: 1},
+	}
+	if got := Drinks(items); got != 3 {
+		t.Errorf("Drinks = %d, want 3", got)
+	}
+
+	if err := Validate(DefaultSchedule()); err != nil {
+		t.Errorf("jadwal bawaan tidak valid: %v", err)
+	}
+	invalid := DefaultSchedule()
+	invalid.Days[1].Close = "06:00"
+	if Validate(invalid) == nil {
+		t.Error("jam tutup sebelum jam buka seharusnya ditolak")
+	}
+	invalid = DefaultSchedule()
+	invalid.Days = append(invalid.Days, model.PickupDay{Weekday: 3, Closed: true})
+	if Validate(invalid) == nil {
+		t.Error("weekday ganda seharusnya ditolak")
+	}
+}
@@ -0,0 +1,156 @@
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Daftar permission yang dicek per route
+const (
+	CategoryWrite   = "category:write"
+	MenuWrite       = "menu:write"
+	BannerWrite     = "banner:write"
+	OrderRead       = "order:read"    // melihat semua pesanan, bukan hanya milik sendiri
+	OrderAdvance    = "order:advance" // memproses status pesanan sebagai staf toko
+	OrderDelete     = "order:delete"
+	InventoryRead   = "inventory:read"
+	InventoryAdjust = "inventory:adjust" // koreksi stok (barang masuk, rusak, opname)
+	InventoryWrite  = "inventory:write"  // tambah dan ubah data bahan
+	VoucherManage   = "voucher:manage"
+	ReportRead      = "report:read"
+	UserRead        = "user:read"
+	UserRole        = "user:role" // mengubah role user dan permission role
+	AuditRead       = "audit:read"
+	TableManage     = "table:manage"    // tambah, ubah meja dan cetak QR meja
+	KitchenRead     = "kitchen:read"    // melihat tiket layar stasiun (bar, kitchen, pastry)
+	KitchenBump     = "kitchen:bump"    // menandai item pesanan siap dari layar stasiun
+	PickupManage    = "pickup:manage"   // jam buka dan kuota slot pesanan terjadwal
+	DeliveryManage  = "delivery:manage" // zona layanan antar, titik toko dan tarif ongkir
+)
+
+// All semua permission yang dikenal, dipakai untuk validasi saat admin mengubah permission role
+var All = []string{
+	CategoryWrite, MenuWrite, BannerWrite,
+	OrderRead, OrderAdvance, OrderDelete,
+	InventoryRead, InventoryAdjust, InventoryWrite,
+	VoucherManage, ReportRead, UserRead, UserRole, AuditRead, TableManage,
+	KitchenRead, KitchenBump, PickupManage, DeliveryManage,
+}
+
+// DefaultPermissions dipakai untuk role yang belum punya dokumen di koleksi role_permission
+var DefaultPermissions = map[string][]string{
+	"admin":   {"*"},
+	"cashier": {OrderRead, OrderAdvance, InventoryRead, InventoryAdjust, KitchenRead, KitchenBump},
+}
+
+// cacheTTL lama permission disimpan di memori sebelum dibaca ulang dari MongoDB
+const cacheTTL = time.Minute
+
+var cache struct {
+	sync.Mutex
+	roles    map[string][]string
+	loadedAt time.Time
+}
+
+// Permissions mengambil permission role dari koleksi role_permission, jika belum ada memakai DefaultPermissions
+func Permissions(db *mongo.Database, role string) []string {
+	cache.Lock()
+	defer cache.Unlock()
+	load(db)
+	if perms, ok := cache.roles[role]; ok {
+		return perms
+	}
+	return DefaultPermissions[role]
+}
+
+// Roles mengembalikan permission efektif semua role, gabungan DefaultPermissions dan koleksi role_permission
+func Roles(db *mongo.Database) map[string][]string {
+	cache.Lock()
+	defer cache.Unlock()
+	load(db)
+	roles := make(map[string][]string, len(DefaultPermissions)+len(cache.roles))
+	for role, perms := range DefaultPermissions {
+		roles[role] = perms
+	}
+	for role, perms := range cache.roles {
+		roles[role] = perms
+	}
+	return roles
+}
+
+// load membaca ulang koleksi role_permission jika cache kosong atau kedaluwarsa, cache harus sudah di-lock
+func load(db *mongo.Database) {
+	if cache.roles != nil && time.Since(cache.loadedAt) <= cacheTTL {
+		return
+	}
+	docs, err := atdb.GetAllDoc[[]model.RolePermission](db, "role_permission", bson.M{})
+	if err != nil {
+		return
+	}
+	cache.roles = make(map[string][]string, len(docs))
+	for _, doc := range docs {
+		cache.roles[doc.Role] = doc.Permissions
+	}
+	cache.loadedAt = time.Now()
+}
+
+// Invalidate membuang cache, dipanggil setelah permission role diubah
+func Invalidate() {
+	cache.Lock()
+	cache.roles = nil
+	cache.Unlock()
+}
+
+// Allowed true jika role memiliki permission tersebut
+func Allowed(db *mongo.Database, role, permission string) bool {
+	if role == "" {
+		return false
+	}
+	return Match(Permissions(db, role), permission)
+}
+
+// Match mencocokkan permission dengan daftar yang dimiliki, mendukung "*" dan "resource:*"
+func Match(granted []string, permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, g := range granted {
+		if g == "*" || g == permission || g == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid true jika permission dikenal atau berupa wildcard resource yang dikenal
+func Valid(permission string) bool {
+	if permission == "*" {
+		return true
+	}
+	for _, p := range All {
+		resource, _, _ := strings.Cut(p, ":")
+		if permission == p || permission == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+type userKey struct{}
+
+// WithUser menyimpan user yang sudah login ke context request
+func WithUser(r *http.Request, user model.Userdomyikado) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), userKey{}, user))
+}
+
+// CurrentUser mengambil user yang disimpan middleware auth, ok false jika route tidak memakai middleware auth
+func CurrentUser(r *http.Request) (user model.Userdomyikado, ok bool) {
+	user, ok = r.Context().Value(userKey{}).(model.Userdomyikado)
+	return
+}
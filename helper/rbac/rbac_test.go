@@ -0,0 +1,36 @@
+package rbac
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		granted    []string
+		permission string
+		want       bool
+	}{
+		{[]string{"*"}, MenuWrite, true},
+		{[]string{MenuWrite}, MenuWrite, true},
+		{[]string{"order:*"}, OrderAdvance, true},
+		{[]string{"order:*"}, MenuWrite, false},
+		{[]string{OrderRead}, OrderAdvance, false},
+		{nil, OrderRead, false},
+	}
+	for _, c := range cases {
+		if got := Match(c.granted, c.permission); got != c.want {
+			t.Errorf("Match(%v, %q) = %v, want %v", c.granted, c.permission, got, c.want)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	for _, p := range []string{"*", MenuWrite, "inventory:*"} {
+		if !Valid(p) {
+			t.Errorf("Valid(%q) = false", p)
+		}
+	}
+	for _, p := range []string{"", "menu", "menu:delete", "kopi:*"} {
+		if Valid(p) {
+			t.Errorf("Valid(%q) = true", p)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package receipt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// tinggi halaman sementara untuk mengukur panjang struk pada kertas gulung
+const measureHeight = 2000
+
+// Generate membuat PDF struk sesuai layout: "a4", "80mm" atau "58mm"
+func Generate(r Receipt, layoutName string) (filecontent []byte, err error) {
+	layout, ok := Layouts[layoutName]
+	if !ok {
+		err = errors.New("layout struk harus salah satu dari a4, 80mm, 58mm")
+		return
+	}
+	var qr []byte
+	if r.StatusURL != "" {
+		if qr, err = qrcode.Encode(r.StatusURL, qrcode.Medium, 256); err != nil {
+			return
+		}
+	}
+
+	// Kertas gulung tidak punya tinggi tetap, isi struk dirender dulu untuk mengukur tingginya
+	height := layout.Height
+	if height == 0 {
+		pdf := newPDF(layout, measureHeight)
+		render(pdf, r, layout, qr)
+		if err = pdf.Error(); err != nil {
+			return
+		}
+		height = pdf.GetY() + layout.Margin
+	}
+
+	pdf := newPDF(layout, height)
+	render(pdf, r, layout, qr)
+	var buf bytes.Buffer
+	if err = pdf.Output(&buf); err != nil {
+		return
+	}
+	return buf.Bytes(), nil
+}
+
+func newPDF(layout Layout, height float64) *gofpdf.Fpdf {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: layout.Width, Ht: height},
+	})
+	pdf.SetMargins(layout.Margin, layout.Margin, layout.Margin)
+	pdf.SetAutoPageBreak(layout.Height != 0, layout.Margin)
+	pdf.AddPage()
+	return pdf
+}
+
+func render(pdf *gofpdf.Fpdf, r Receipt, layout Layout, qr []byte) {
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	width := layout.Width - 2*layout.Margin
+	fontSize := layout.FontSize
+	lineHeight := fontSize * 0.45
+
+	// baris label di kiri dan nilai rata kanan
+	row := func(label, value string) {
+		labelWidth := pdf.GetStringWidth(tr(label)) + 2
+		pdf.CellFormat(labelWidth, lineHeight, tr(label), "", 0, "L", false, 0, "")
+		pdf.CellFormat(width-labelWidth, lineHeight, tr(value), "", 1, "R", false, 0, "")
+	}
+	center := func(text string, style string, size float64) {
+		pdf.SetFont("Arial", style, size)
+		pdf.MultiCell(width, size*0.45, tr(text), "", "C", false)
+		pdf.SetFont("Arial", "", fontSize)
+	}
+	separator := func() {
+		y := pdf.GetY() + lineHeight/2
+		pdf.SetDashPattern([]float64{1, 1}, 0)
+		pdf.Line(layout.Margin, y, layout.Margin+width, y)
+		pdf.SetDashPattern([]float64{}, 0)
+		pdf.Ln(lineHeight)
+	}
+
+	// Kepala struk
+	center(r.ShopName, "B", fontSize+4)
+	if r.ShopAddress != "" {
+		center(r.ShopAddress, "", fontSize)
+	}
+	if r.ShopPhone != "" {
+		center(r.ShopPhone, "", fontSize)
+	}
+	separator()
+
+	center("No. Antrean", "", fontSize)
+	center(fmt.Sprint(r.QueueNumber), "B", fontSize+10)
+	pdf.Ln(lineHeight / 2)
+	row("No. Order", r.OrderNumber)
+	row("Tanggal", r.Date)
+	if r.Cashier != "" {
+		row("Kasir", r.Cashier)
+	}
+	if r.Customer != "" {
+		row("Pelanggan", r.Customer)
+	}
+	if r.Address != "" {
+		pdf.MultiCell(width, lineHeight, tr("Antar ke: "+r.Address), "", "L", false)
+	}
+	separator()
+
+	// Daftar item: nama menu, opsi yang dipilih, lalu qty x harga satuan dan subtotal
+	for _, item := range r.Items {
+		pdf.SetFont("Arial", "B", fontSize)
+		pdf.MultiCell(width, lineHeight, tr(item.Name), "", "L", false)
+		pdf.SetFont("Arial", "", fontSize)
+		if item.Options != "" {
+			pdf.SetFont("Arial", "I", fontSize-1)
+			pdf.MultiCell(width, lineHeight, tr(item.Options), "", "L", false)
+			pdf.SetFont("Arial", "", fontSize)
+		}
+		row(fmt.Sprintf("%d x %s", item.Quantity, item.UnitPrice), item.Subtotal)
+	}
+	separator()
+
+	if r.Subtotal != "" {
+		row("Subtotal", r.Subtotal)
+	}
+	if r.Discount != "" {
+		row("Diskon "+r.VoucherCode, "-"+r.Discount)
+	}
+	if r.PointsValue != "" {
+		row(fmt.Sprintf("Tukar %d Poin", r.PointsUsed), "-"+r.PointsValue)
+	}
+	if r.DeliveryFee != "" {
+		row("Ongkir", r.DeliveryFee)
+	}
+	pdf.SetFont("Arial", "B", fontSize+1)
+	row("TOTAL", r.Total)
+	pdf.SetFont("Arial", "", fontSize)
+	row("Pembayaran", r.PaymentMethod)
+	if r.PaymentStatus != "" {
+		row("Status Bayar", r.PaymentStatus)
+	}
+	separator()
+
+	if qr != nil {
+		options := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader("qr", options, bytes.NewReader(qr))
+		y := pdf.GetY()
+		pdf.ImageOptions("qr", (layout.Width-layout.QRSize)/2, y, layout.QRSize, layout.QRSize, false, options, 0, "")
+		pdf.SetY(y + layout.QRSize + 1)
+		center("Scan untuk cek status pesanan", "", fontSize-1)
+		pdf.Ln(lineHeight / 2)
+	}
+	center("Terima kasih", "B", fontSize)
+}
@@ -0,0 +1,82 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var mediaBox = regexp.MustCompile(`/MediaBox \[0 0 ([\d.]+) ([\d.]+)\]`)
+
+// pageSize ukuran halaman pertama PDF dalam milimeter
+func pageSize(t *testing.T, pdf []byte) (width, height float64) {
+	t.Helper()
+	m := mediaBox.FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("MediaBox tidak ditemukan di PDF")
+	}
+	width, _ = strconv.ParseFloat(string(m[1]), 64)
+	height, _ = strconv.ParseFloat(string(m[2]), 64)
+	return width * 25.4 / 72, height * 25.4 / 72
+}
+
+func testReceipt(items int) Receipt {
+	r := Receipt{
+		ShopName:      "Logiccoffee",
+		OrderNumber:   "LGC2024100100013",
+		QueueNumber:   1,
+		Date:          "01/10/2024 10:00",
+		Total:         "Rp 20.000,00",
+		PaymentMethod: "Cash",
+		StatusURL:     "https://logiccoffee.id/status/LGC2024100100013",
+	}
+	for i := 0; i < items; i++ {
+		r.Items = append(r.Items, Item{Name: fmt.Sprintf("Kopi Susu %d", i+1), Options: "Large, Less Sugar", Quantity: 1, UnitPrice: "Rp 20.000,00", Subtotal: "Rp 20.000,00"})
+	}
+	return r
+}
+
+func TestGenerateLayouts(t *testing.T) {
+	for name, layout := range Layouts {
+		pdf, err := Generate(testReceipt(2), name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+			t.Errorf("%s: bukan file PDF", name)
+			continue
+		}
+		width, height := pageSize(t, pdf)
+		if width < layout.Width-0.1 || width > layout.Width+0.1 {
+			t.Errorf("%s: lebar %.1fmm, want %.0fmm", name, width, layout.Width)
+		}
+		if layout.Height != 0 && (height < layout.Height-0.1 || height > layout.Height+0.1) {
+			t.Errorf("%s: tinggi %.1fmm, want %.0fmm", name, height, layout.Height)
+		}
+	}
+	if _, err := Generate(testReceipt(1), "letter"); err == nil {
+		t.Error("layout tidak dikenal tidak error")
+	}
+}
+
+func TestGenerateRollHeight(t *testing.T) {
+	short, err := Generate(testReceipt(1), "58mm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	long, err := Generate(testReceipt(20), "58mm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, shortHeight := pageSize(t, short)
+	_, longHeight := pageSize(t, long)
+	if shortHeight >= longHeight {
+		t.Errorf("tinggi kertas gulung tidak mengikuti isi: 1 item %.1fmm, 20 item %.1fmm", shortHeight, longHeight)
+	}
+	if longHeight >= measureHeight {
+		t.Errorf("tinggi kertas gulung %.1fmm masih memakai tinggi pengukuran", longHeight)
+	}
+}
@@ -0,0 +1,49 @@
+package receipt
+
+// Receipt isi struk yang sudah diformat (rupiah, waktu lokal) oleh pemanggil
+type Receipt struct {
+	ShopName      string
+	ShopAddress   string
+	ShopPhone     string
+	OrderNumber   string
+	QueueNumber   int
+	Date          string
+	Cashier       string
+	Customer      string
+	Items         []Item
+	Subtotal      string // diisi jika ada potongan voucher, poin atau ongkir
+	Discount      string
+	VoucherCode   string
+	PointsUsed    int    // poin loyalitas yang ditukar
+	PointsValue   string // potongan dari penukaran poin
+	DeliveryFee   string // ongkir pesanan antar
+	Address       string // alamat antar
+	Total         string
+	PaymentMethod string
+	PaymentStatus string
+	StatusURL     string // link halaman status pesanan, dicetak sebagai QR code
+}
+
+type Item struct {
+	Name      string
+	Options   string // opsi yang dipilih, misalnya "Large, Less Sugar"
+	Quantity  int
+	UnitPrice string
+	Subtotal  string
+}
+
+// Layout ukuran kertas struk dalam milimeter
+type Layout struct {
+	Width    float64
+	Height   float64 // 0 untuk kertas gulung, tinggi halaman mengikuti isi struk
+	Margin   float64
+	FontSize float64
+	QRSize   float64
+}
+
+// Layouts ukuran yang didukung: A4 dan printer thermal 58mm/80mm
+var Layouts = map[string]Layout{
+	"a4":   {Width: 210, Height: 297, Margin: 20, FontSize: 11, QRSize: 40},
+	"80mm": {Width: 80, Margin: 4, FontSize: 8, QRSize: 30},
+	"58mm": {Width: 58, Margin: 3, FontSize: 7, QRSize: 24},
+}
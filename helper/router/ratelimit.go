@@ -0,0 +1,46 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gocroot/helper/at"
+	"github.com/gocroot/model"
+)
+
+// RateLimit membatasi jumlah request per IP client dalam satu jendela waktu.
+// Hitungan disimpan di memori, jadi batasnya berlaku per instance Cloud Function.
+func RateLimit(limit int, window time.Duration) Middleware {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	windowStart := time.Now()
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ip, err := at.GetClientIP(r)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+			mu.Lock()
+			if time.Since(windowStart) >= window {
+				hits = map[string]int{}
+				windowStart = time.Now()
+			}
+			hits[ip]++
+			count := hits[ip]
+			retryAfter := time.Until(windowStart.Add(window))
+			mu.Unlock()
+
+			if count > limit {
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+				at.WriteJSON(w, http.StatusTooManyRequests, model.Response{
+					Status:   "Error: Terlalu Banyak Request",
+					Response: "Silakan coba lagi beberapa saat lagi",
+				})
+				return
+			}
+			next(w, r)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Middleware membungkus handler, misalnya cek token, cek role atau rate limit
+type Middleware func(http.HandlerFunc) http.HandlerFunc
+
+// Router tabel route dengan parameter bernama (/data/order/:id) dan middleware per route.
+// Segmen terakhir boleh berupa catch-all (/files/*key) yang menangkap sisa path termasuk garis miring.
+// Segmen statis selalu didahulukan dari parameter, sehingga /data/order/number/:ordernumber
+// tidak tertangkap oleh /data/order/:id/receipt walaupun jumlah segmennya sama.
+type Router struct {
+	routes           []*route
+	NotFound         http.HandlerFunc // dipanggil jika tidak ada route dengan path tersebut
+	MethodNotAllowed http.HandlerFunc // dipanggil jika path ada tetapi method salah, header Allow sudah diisi
+}
+
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  http.HandlerFunc
+}
+
+type paramsKey struct{}
+
+// New membuat router kosong dengan respons 404 dan 405 bawaan net/http
+func New() *Router {
+	return &Router{
+		NotFound: http.NotFound,
+		MethodNotAllowed: func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		},
+	}
+}
+
+// Handle mendaftarkan route. Middleware dijalankan berurutan dari kiri ke kanan sebelum handler.
+// Panic jika route bentrok dengan route yang sudah terdaftar, supaya kesalahan tabel route ketahuan saat startup.
+func (rt *Router) Handle(method, pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	newRoute := &route{method: method, pattern: pattern, segments: split(pattern), handler: handler}
+	for _, existing := range rt.routes {
+		if existing.method == method && sameShape(existing.segments, newRoute.segments) {
+			panic(fmt.Sprintf("router: route %s %s bentrok dengan %s %s", method, pattern, existing.method, existing.pattern))
+		}
+	}
+	rt.routes = append(rt.routes, newRoute)
+}
+
+func (rt *Router) GET(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	rt.Handle(http.MethodGet, pattern, handler, middlewares...)
+}
+
+func (rt *Router) POST(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	rt.Handle(http.MethodPost, pattern, handler, middlewares...)
+}
+
+func (rt *Router) PUT(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	rt.Handle(http.MethodPut, pattern, handler, middlewares...)
+}
+
+func (rt *Router) DELETE(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	rt.Handle(http.MethodDelete, pattern, handler, middlewares...)
+}
+
+// ServeHTTP mencari route paling spesifik untuk path request. Jika path cocok tetapi method
+// tidak terdaftar, router membalas 405 dengan header Allow berisi method yang tersedia.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := split(r.URL.Path)
+	var match *route
+	var params map[string]string
+	allowed := map[string]bool{}
+	for _, candidate := range rt.routes {
+		found, ok := candidate.match(segments)
+		if !ok {
+			continue
+		}
+		if candidate.method != r.Method {
+			allowed[candidate.method] = true
+			continue
+		}
+		if match == nil || moreSpecific(candidate.segments, match.segments) {
+			match, params = candidate, found
+		}
+	}
+	if match == nil {
+		if len(allowed) == 0 {
+			rt.NotFound(w, r)
+			return
+		}
+		methods := make([]string, 0, len(allowed))
+		for method := range allowed {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		rt.MethodNotAllowed(w, r)
+		return
+	}
+	if len(params) > 0 {
+		r = r.WithContext(context.WithValue(r.Context(), paramsKey{}, params))
+	}
+	match.handler(w, r)
+}
+
+// Param mengambil nilai parameter bernama dari path request, misalnya Param(r, "id") untuk /data/order/:id
+func Param(r *http.Request, name string) string {
+	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return params[name]
+}
+
+// match mencocokkan segmen path dengan pola route dan mengembalikan nilai parameternya
+func (rt *route) match(segments []string) (params map[string]string, ok bool) {
+	last := len(rt.segments) - 1
+	catchAll := last >= 0 && strings.HasPrefix(rt.segments[last], "*")
+	if len(segments) != len(rt.segments) && !(catchAll && len(segments) > last) {
+		return nil, false
+	}
+	for i, seg := range rt.segments {
+		if name, isCatchAll := strings.CutPrefix(seg, "*"); isCatchAll {
+			if params == nil {
+				params = map[string]string{}
+			}
+			params[name] = strings.Join(segments[i:], "/")
+		} else if name, isParam := strings.CutPrefix(seg, ":"); isParam {
+			if segments[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = map[string]string{}
+			}
+			params[name] = segments[i]
+		} else if seg != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// split memecah path menjadi segmen, garis miring di akhir diabaikan
+func split(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// sameShape true jika dua pola selalu cocok dengan path yang sama (nama parameter boleh berbeda)
+func sameShape(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if kind(a[i]) != kind(b[i]) || (kind(a[i]) == segmentStatic && a[i] != b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecific true jika a lebih spesifik dari b: pada segmen pertama yang jenisnya berbeda,
+// statis menang atas parameter dan parameter menang atas catch-all
+func moreSpecific(a, b []string) bool {
+	for i := range a {
+		if i >= len(b) {
+			return true
+		}
+		if kind(a[i]) != kind(b[i]) {
+			return kind(a[i]) < kind(b[i])
+		}
+	}
+	return false
+}
+
+// jenis segmen pola, urut dari yang paling spesifik
+const (
+	segmentStatic = iota
+	segmentParam
+	segmentCatchAll
+)
+
+func kind(seg string) int {
+	switch {
+	case strings.HasPrefix(seg, ":"):
+		return segmentParam
+	case strings.HasPrefix(seg, "*"):
+		return segmentCatchAll
+	}
+	return segmentStatic
+}
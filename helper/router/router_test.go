@@ -0,0 +1,104 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func handlerWriting(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body + ":" + Param(r, "id")))
+	}
+}
+
+func serve(rt *Router, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestStaticSegmentWinsOverParam(t *testing.T) {
+	rt := New()
+	rt.GET("/data/order/:id", handlerWriting("byid"))
+	rt.GET("/data/order/:id/receipt", handlerWriting("receipt"))
+	rt.GET("/data/order/number/:ordernumber", handlerWriting("bynumber"))
+
+	cases := map[string]string{
+		"/data/order/abc":          "byid:abc",
+		"/data/order/abc/":         "byid:abc",
+		"/data/order/abc/receipt":  "receipt:abc",
+		"/data/order/number/LGC01": "bynumber:",
+	}
+	for path, want := range cases {
+		if got := serve(rt, http.MethodGet, path).Body.String(); got != want {
+			t.Errorf("GET %s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestCatchAll(t *testing.T) {
+	rt := New()
+	rt.GET("/files/*key", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("file:" + Param(r, "key"))) })
+	rt.GET("/files/:id/info", handlerWriting("info"))
+
+	cases := map[string]string{
+		"/files/menu/abc.jpg": "file:menu/abc.jpg",
+		"/files/a/b/c.png":    "file:a/b/c.png",
+		"/files/abc/info":     "info:abc",
+	}
+	for path, want := range cases {
+		if got := serve(rt, http.MethodGet, path).Body.String(); got != want {
+			t.Errorf("GET %s = %q, want %q", path, got, want)
+		}
+	}
+	if w := serve(rt, http.MethodGet, "/files"); w.Code != http.StatusNotFound {
+		t.Errorf("GET /files status = %d, want 404", w.Code)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	rt := New()
+	rt.GET("/data/menu/:id", handlerWriting("get"))
+	rt.DELETE("/data/menu/:id", handlerWriting("delete"))
+
+	w := serve(rt, http.MethodPost, "/data/menu/1")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want 405", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "DELETE, GET" {
+		t.Errorf("Allow = %q", allow)
+	}
+	if w := serve(rt, http.MethodGet, "/data/unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next(w, r)
+			}
+		}
+	}
+	rt := New()
+	rt.GET("/", func(w http.ResponseWriter, r *http.Request) { calls = append(calls, "handler") }, mw("auth"), mw("role"))
+	serve(rt, http.MethodGet, "/")
+	if len(calls) != 3 || calls[0] != "auth" || calls[1] != "role" || calls[2] != "handler" {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestConflictPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for conflicting routes")
+		}
+	}()
+	rt := New()
+	rt.POST("/webhook/nomor/:nomorwa", handlerWriting("a"))
+	rt.POST("/webhook/nomor/:nomor", handlerWriting("b"))
+}
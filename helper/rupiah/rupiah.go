@@ -0,0 +1,12 @@
+package rupiah
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Format mengubah nominal ke format rupiah Indonesia, misalnya 25000 menjadi "Rp 25.000,00".
+// Dipakai respons API, struk, notifikasi WhatsApp dan balasan bot supaya tampilan harga seragam.
+func Format(price float64) string {
+	return message.NewPrinter(language.Indonesian).Sprintf("Rp %.2f", price)
+}
@@ -0,0 +1,18 @@
+package rupiah
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := map[float64]string{
+		0:       "Rp 0,00",
+		2500:    "Rp 2.500,00",
+		25000.5: "Rp 25.000,50",
+		1250000: "Rp 1.250.000,00",
+		-10000:  "Rp -10.000,00",
+	}
+	for price, want := range tests {
+		if got := Format(price); got != want {
+			t.Errorf("Format(%v) = %q, want %q", price, got, want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package sales
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ukuran bucket yang didukung $dateTrunc
+var Buckets = map[string]bool{"hour": true, "day": true, "week": true, "month": true}
+
+const Timezone = "Asia/Jakarta"
+
+// ParseQuery - Membaca parameter ?from=2024-10-01&to=2024-10-31&bucket=day&top=5.
+// Tanggal dalam zona waktu Timezone, tanggal akhir ikut dihitung (inklusif). Default 30 hari terakhir sampai now.
+func ParseQuery(params url.Values, now time.Time) (q Query, err error) {
+	location, err := time.LoadLocation(Timezone)
+	if err != nil {
+		return
+	}
+	now = now.In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	q.From = today.AddDate(0, 0, -29)
+	q.To = today.AddDate(0, 0, 1)
+	q.Bucket = "day"
+	q.Top = 5
+
+	if from := params.Get("from"); from != "" {
+		if q.From, err = time.ParseInLocation("2006-01-02", from, location); err != nil {
+			return
+		}
+	}
+	if to := params.Get("to"); to != "" {
+		if q.To, err = time.ParseInLocation("2006-01-02", to, location); err != nil {
+			return
+		}
+		q.To = q.To.AddDate(0, 0, 1)
+	}
+	if bucket := params.Get("bucket"); bucket != "" {
+		q.Bucket = bucket
+	}
+	if top := params.Get("top"); top != "" {
+		if q.Top, err = strconv.Atoi(top); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// GetSalesSummary menghitung ringkasan penjualan dari collection orders.
+// Pendapatan hanya dihitung dari order berstatus selesai, rasio pembatalan dari semua order pada rentang waktu.
+func GetSalesSummary(db *mongo.Database, q Query) (summary Summary, err error) {
+	if q, err = checkQuery(q); err != nil {
+		return
+	}
+
+	dateRange := bson.M{"$gte": q.From, "$lt": q.To}
+	menuGroup := bson.D{{Key: "$group", Value: bson.M{
+		"_id":      "$orders.menu_id",
+		"name":     bson.M{"$first": "$orders.menu_name"},
+		"quantity": bson.M{"$sum": "$orders.quantity"},
+		"revenue":  bson.M{"$sum": bson.M{"$multiply": bson.A{"$orders.price", "$orders.quantity"}}},
+	}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"orderDate": dateRange, "status": model.OrderStatusSelesai}}},
+		{{Key: "$facet", Value: bson.M{
+			"totals": bson.A{
+				bson.M{"$group": bson.M{"_id": nil, "revenue": bson.M{"$sum": "$total"}, "count": bson.M{"$sum": 1}}},
+			},
+			"series": bson.A{
+				bson.M{"$group": bson.M{"_id": bson.M{"$dateTrunc": dateTrunc(q.Bucket)}, "revenue": bson.M{"$sum": "$total"}, "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+			"topquantity": bson.A{
+				bson.M{"$unwind": "$orders"}, menuGroup,
+				bson.M{"$sort": bson.D{{Key: "quantity", Value: -1}, {Key: "revenue", Value: -1}}},
+				bson.M{"$limit": q.Top},
+			},
+			"toprevenue": bson.A{
+				bson.M{"$unwind": "$orders"}, menuGroup,
+				bson.M{"$sort": bson.D{{Key: "revenue", Value: -1}, {Key: "quantity", Value: -1}}},
+				bson.M{"$limit": q.Top},
+			},
+			"hours": bson.A{
+				bson.M{"$group": bson.M{"_id": bson.M{"$hour": bson.M{"date": "$orderDate", "timezone": Timezone}}, "revenue": bson.M{"$sum": "$total"}, "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+			"payments": bson.A{
+				bson.M{"$group": bson.M{"_id": "$payment_method", "revenue": bson.M{"$sum": "$total"}, "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.M{"revenue": -1}},
+			},
+		}}},
+	}
+	facets, err := atdb.AggregateDocs[salesFacet](db, "orders", pipeline)
+	if err != nil {
+		return
+	}
+
+	// rasio pembatalan dari seluruh order (semua status) pada rentang waktu
+	statuses, err := atdb.AggregateDocs[statusCount](db, "orders", mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"orderDate": dateRange}}},
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return
+	}
+	return summarize(q, facets, statuses)
+}
+
+// checkQuery memeriksa bucket dan rentang tanggal, jumlah menu terlaris default 5
+func checkQuery(q Query) (Query, error) {
+	if !Buckets[q.Bucket] {
+		return q, errors.New("bucket harus salah satu dari hour, day, week, month")
+	}
+	if !q.To.After(q.From) {
+		return q, errors.New("tanggal akhir harus setelah tanggal awal")
+	}
+	if q.Top <= 0 {
+		q.Top = 5
+	}
+	return q, nil
+}
+
+// dateTrunc ekspresi $dateTrunc untuk mengelompokkan orderDate per bucket, minggu dimulai hari Senin
+func dateTrunc(bucket string) bson.M {
+	trunc := bson.M{"date": "$orderDate", "unit": bucket, "timezone": Timezone}
+	if bucket == "week" {
+		trunc["startOfWeek"] = "monday"
+	}
+	return trunc
+}
+
+// summarize menyusun Summary dari hasil aggregation, periode dan rentang tanggal dalam zona waktu Timezone
+func summarize(q Query, facets []salesFacet, statuses []statusCount) (summary Summary, err error) {
+	location, err := time.LoadLocation(Timezone)
+	if err != nil {
+		return
+	}
+	summary = Summary{
+		From:   q.From.In(location).Format(time.RFC3339),
+		To:     q.To.In(location).Format(time.RFC3339),
+		Bucket: q.Bucket,
+	}
+	if len(facets) > 0 {
+		facet := facets[0]
+		if len(facet.Totals) > 0 {
+			summary.Revenue = facet.Totals[0].Revenue
+			summary.OrderCount = facet.Totals[0].Count
+			if summary.OrderCount > 0 {
+				summary.AverageTicket = summary.Revenue / float64(summary.OrderCount)
+			}
+		}
+		for _, s := range facet.Series {
+			summary.Series = append(summary.Series, SeriesPoint{Period: s.ID.In(location).Format(time.RFC3339), Revenue: s.Revenue, OrderCount: s.Count})
+		}
+		summary.TopByQuantity = facet.TopQuantity
+		summary.TopByRevenue = facet.TopRevenue
+		for _, h := range facet.Hours {
+			summary.PeakHours = append(summary.PeakHours, HourSales{Hour: h.ID, Revenue: h.Revenue, OrderCount: h.Count})
+		}
+		for _, p := range facet.Payments {
+			summary.ByPaymentMethod = append(summary.ByPaymentMethod, PaymentSales{PaymentMethod: p.ID, Revenue: p.Revenue, OrderCount: p.Count})
+		}
+	}
+
+	var all int
+	for _, st := range statuses {
+		all += st.Count
+		if st.ID == model.OrderStatusDibatalkan || st.ID == model.OrderStatusDitolak {
+			summary.CancelledCount += st.Count
+		}
+	}
+	if all > 0 {
+		summary.CancellationRate = float64(summary.CancelledCount) / float64(all)
+	}
+	return
+}
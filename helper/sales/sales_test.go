@@ -0,0 +1,116 @@
+package sales
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseQuery(t *testing.T) {
+	location, _ := time.LoadLocation(Timezone)
+	// 2024-10-15 23:30 WIB, sudah tanggal 15 walaupun di UTC masih pukul 16:30
+	now := time.Date(2024, 10, 15, 16, 30, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, location) }
+
+	tests := []struct {
+		query   string
+		want    Query
+		wantErr bool
+	}{
+		{"", Query{From: day(2024, 9, 16), To: day(2024, 10, 16), Bucket: "day", Top: 5}, false},
+		{"from=2024-10-01&to=2024-10-31&bucket=week&top=10", Query{From: day(2024, 10, 1), To: day(2024, 11, 1), Bucket: "week", Top: 10}, false},
+		{"from=01-10-2024", Query{}, true},
+		{"to=kemarin", Query{}, true},
+		{"top=lima", Query{}, true},
+	}
+	for _, tt := range tests {
+		params, _ := url.ParseQuery(tt.query)
+		got, err := ParseQuery(params, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseQuery(%q) tidak error", tt.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if !got.From.Equal(tt.want.From) || !got.To.Equal(tt.want.To) || got.Bucket != tt.want.Bucket || got.Top != tt.want.Top {
+			t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCheckQuery(t *testing.T) {
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	tests := []struct {
+		name    string
+		q       Query
+		wantTop int
+		wantErr bool
+	}{
+		{"valid dengan top default", Query{From: from, To: to, Bucket: "day"}, 5, false},
+		{"top dari query", Query{From: from, To: to, Bucket: "hour", Top: 3}, 3, false},
+		{"bucket tidak dikenal", Query{From: from, To: to, Bucket: "year"}, 0, true},
+		{"tanggal akhir sama dengan awal", Query{From: from, To: from, Bucket: "day"}, 0, true},
+		{"tanggal terbalik", Query{From: to, To: from, Bucket: "day"}, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := checkQuery(tt.q)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.Top != tt.wantTop {
+			t.Errorf("%s: Top = %d, want %d", tt.name, got.Top, tt.wantTop)
+		}
+	}
+}
+
+func TestDateTrunc(t *testing.T) {
+	if got, want := dateTrunc("day"), (bson.M{"date": "$orderDate", "unit": "day", "timezone": Timezone}); !reflect.DeepEqual(got, want) {
+		t.Errorf("dateTrunc(day) = %v, want %v", got, want)
+	}
+	if got := dateTrunc("week"); got["startOfWeek"] != "monday" {
+		t.Errorf("dateTrunc(week) = %v, minggu harus dimulai hari Senin", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	from := time.Date(2024, 9, 30, 17, 0, 0, 0, time.UTC) // 2024-10-01 00:00 WIB
+	q := Query{From: from, To: from.AddDate(0, 0, 2), Bucket: "day", Top: 5}
+	facet := salesFacet{
+		Totals: []facetGroup[any]{{Revenue: 100000, Count: 4}},
+		Series: []facetGroup[time.Time]{{ID: from, Revenue: 100000, Count: 4}},
+	}
+	statuses := []statusCount{{ID: "selesai", Count: 4}, {ID: "dibatalkan", Count: 3}, {ID: "ditolak", Count: 1}}
+
+	got, err := summarize(q, []salesFacet{facet}, statuses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.From != "2024-10-01T00:00:00+07:00" || got.To != "2024-10-03T00:00:00+07:00" {
+		t.Errorf("rentang = %s - %s", got.From, got.To)
+	}
+	if got.Revenue != 100000 || got.OrderCount != 4 || got.AverageTicket != 25000 {
+		t.Errorf("total = %+v", got)
+	}
+	if got.CancelledCount != 4 || got.CancellationRate != 0.5 {
+		t.Errorf("pembatalan = %d, rasio %v, want 4, 0.5", got.CancelledCount, got.CancellationRate)
+	}
+	wantSeries := []SeriesPoint{{Period: "2024-10-01T00:00:00+07:00", Revenue: 100000, OrderCount: 4}}
+	if !reflect.DeepEqual(got.Series, wantSeries) {
+		t.Errorf("Series = %+v, want %+v", got.Series, wantSeries)
+	}
+
+	// tanpa order sama sekali tidak membagi dengan nol
+	empty, err := summarize(q, nil, nil)
+	if err != nil || empty.AverageTicket != 0 || empty.CancellationRate != 0 {
+		t.Errorf("summarize kosong = %+v, %v", empty, err)
+	}
+}
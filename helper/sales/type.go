@@ -0,0 +1,77 @@
+package sales
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Query struct {
+	From   time.Time
+	To     time.Time
+	Bucket string // hour, day, week, month
+	Top    int    // jumlah menu terlaris yang ditampilkan
+}
+
+type Summary struct {
+	From             string         `json:"from"`
+	To               string         `json:"to"`
+	Bucket           string         `json:"bucket"`
+	Revenue          float64        `json:"revenue"`
+	OrderCount       int            `json:"order_count"`
+	AverageTicket    float64        `json:"average_ticket"`
+	CancelledCount   int            `json:"cancelled_count"`
+	CancellationRate float64        `json:"cancellation_rate"`
+	Series           []SeriesPoint  `json:"series"`
+	TopByQuantity    []MenuSales    `json:"top_by_quantity"`
+	TopByRevenue     []MenuSales    `json:"top_by_revenue"`
+	PeakHours        []HourSales    `json:"peak_hours"`
+	ByPaymentMethod  []PaymentSales `json:"by_payment_method"`
+}
+
+type SeriesPoint struct {
+	Period     string  `json:"period"`
+	Revenue    float64 `json:"revenue"`
+	OrderCount int     `json:"order_count"`
+}
+
+type MenuSales struct {
+	MenuID   primitive.ObjectID `json:"menu_id" bson:"_id"`
+	Name     string             `json:"name" bson:"name"`
+	Quantity int                `json:"quantity" bson:"quantity"`
+	Revenue  float64            `json:"revenue" bson:"revenue"`
+}
+
+type HourSales struct {
+	Hour       int     `json:"hour"`
+	Revenue    float64 `json:"revenue"`
+	OrderCount int     `json:"order_count"`
+}
+
+type PaymentSales struct {
+	PaymentMethod string  `json:"payment_method"`
+	Revenue       float64 `json:"revenue"`
+	OrderCount    int     `json:"order_count"`
+}
+
+// hasil $facet dari aggregation orders
+type salesFacet struct {
+	Totals      []facetGroup[any]       `bson:"totals"`
+	Series      []facetGroup[time.Time] `bson:"series"`
+	TopQuantity []MenuSales             `bson:"topquantity"`
+	TopRevenue  []MenuSales             `bson:"toprevenue"`
+	Hours       []facetGroup[int]       `bson:"hours"`
+	Payments    []facetGroup[string]    `bson:"payments"`
+}
+
+// facetGroup satu hasil $group dengan pendapatan dan jumlah order per _id
+type facetGroup[T any] struct {
+	ID      T       `bson:"_id"`
+	Revenue float64 `bson:"revenue"`
+	Count   int     `bson:"count"`
+}
+
+type statusCount struct {
+	ID    string `bson:"_id"`
+	Count int    `bson:"count"`
+}
@@ -0,0 +1,45 @@
+package storage
+
+import "github.com/gocroot/helper/ghupload"
+
+// GitHubStorage menyimpan file sebagai commit di repository GitHub (branch main),
+// URL publiknya raw.githubusercontent.com sehingga bisa langsung ditampilkan sebagai gambar
+type GitHubStorage struct {
+	Token       string
+	Org         string
+	Repo        string
+	AuthorName  string // kosong berarti commit atas nama pemilik token
+	AuthorEmail string
+}
+
+func (s GitHubStorage) Name() string {
+	return "github"
+}
+
+func (s GitHubStorage) Put(key string, content []byte, contentType string) (obj Object, err error) {
+	if key, err = CleanKey(key); err != nil {
+		return
+	}
+	if _, _, err = ghupload.GithubUpload(s.Token, s.AuthorName, s.AuthorEmail, content, s.Org, s.Repo, key, true); err != nil {
+		return
+	}
+	return Object{Key: key, URL: s.URL(key)}, nil
+}
+
+func (s GitHubStorage) Get(key string) (content []byte, err error) {
+	if key, err = CleanKey(key); err != nil {
+		return
+	}
+	return ghupload.GithubGetFile(s.Token, s.Org, s.Repo, key)
+}
+
+func (s GitHubStorage) Delete(key string) (err error) {
+	if key, err = CleanKey(key); err != nil {
+		return
+	}
+	return ghupload.GithubDelete(s.Token, s.Org, s.Repo, key)
+}
+
+func (s GitHubStorage) URL(key string) string {
+	return "https://raw.githubusercontent.com/" + s.Org + "/" + s.Repo + "/main/" + key
+}
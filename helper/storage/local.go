@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage menyimpan file di folder lokal, untuk pengembangan atau server dengan disk tetap.
+// File dilayani oleh Serve pada BaseURL, misalnya BaseURL "https://api.logiccoffee.id/files"
+// dengan route GET /files/*key.
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func (s LocalStorage) Name() string {
+	return "local"
+}
+
+func (s LocalStorage) Put(key string, content []byte, contentType string) (obj Object, err error) {
+	if key, err = CleanKey(key); err != nil {
+		return
+	}
+	file := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err = os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return
+	}
+	if err = os.WriteFile(file, content, 0o644); err != nil {
+		return
+	}
+	return Object{Key: key, URL: s.URL(key)}, nil
+}
+
+func (s LocalStorage) Get(key string) (content []byte, err error) {
+	if key, err = CleanKey(key); err != nil {
+		return
+	}
+	return os.ReadFile(filepath.Join(s.Dir, filepath.FromSlash(key)))
+}
+
+func (s LocalStorage) Delete(key string) (err error) {
+	if key, err = CleanKey(key); err != nil {
+		return
+	}
+	err = os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return
+}
+
+func (s LocalStorage) URL(key string) string {
+	return joinURL(s.BaseURL, key)
+}
+
+// Serve mengirim file dengan key tertentu, 404 jika key tidak valid atau file tidak ada
+func (s LocalStorage) Serve(w http.ResponseWriter, r *http.Request, key string) {
+	key, err := CleanKey(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	file := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if info, err := os.Stat(file); err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, file)
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Storage menyimpan file di bucket S3 atau layanan yang kompatibel (MinIO, Cloudflare R2, dll).
+// Request ditandatangani dengan AWS Signature Version 4 memakai alamat path-style: Endpoint/Bucket/key.
+type S3Storage struct {
+	Endpoint  string // contoh: https://s3.ap-southeast-1.amazonaws.com atau http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string // opsional, misalnya CDN di depan bucket; default Endpoint/Bucket
+	Client    *http.Client
+}
+
+func (s S3Storage) Name() string {
+	return "s3"
+}
+
+func (s S3Storage) Put(key string, content []byte, contentType string) (obj Object, err error) {
+	if key, err = CleanKey(key); err != nil {
+		return
+	}
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(key), bytes.NewReader(content))
+	if err != nil {
+		return
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if _, err = s.do(req, content); err != nil {
+		return
+	}
+	return Object{Key: key, URL: s.URL(key)}, nil
+}
+
+func (s S3Storage) Get(key string) (content []byte, err error) {
+	if key, err = CleanKey(key); err != nil {
+		return
+	}
+	req, err := http.NewRequest(http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return
+	}
+	return s.do(req, nil)
+}
+
+func (s S3Storage) Delete(key string) (err error) {
+	if key, err = CleanKey(key); err != nil {
+		return
+	}
+	req, err := http.NewRequest(http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return
+	}
+	// S3 membalas 204 walaupun object tidak ada
+	_, err = s.do(req, nil)
+	return
+}
+
+func (s S3Storage) URL(key string) string {
+	if s.PublicURL != "" {
+		return joinURL(s.PublicURL, key)
+	}
+	return s.objectURL(key)
+}
+
+func (s S3Storage) objectURL(key string) string {
+	return joinURL(joinURL(s.Endpoint, s.Bucket), (&url.URL{Path: key}).EscapedPath())
+}
+
+// do mengirim request yang sudah ditandatangani dan mengembalikan isi respons
+func (s S3Storage) do(req *http.Request, body []byte) ([]byte, error) {
+	s.sign(req, body, time.Now().UTC())
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 %s %s: %s %s", req.Method, req.URL.Path, resp.Status, msg)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// sign menambahkan header Authorization AWS Signature Version 4, semua header yang sudah diset ikut ditandatangani
+func (s S3Storage) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// Storage adalah kontrak penyimpanan file (gambar menu, foto profil, dokumen)
+type Storage interface {
+	// Name nama backend: github, local atau s3
+	Name() string
+	// Put menyimpan file dengan key tertentu, file lama dengan key yang sama ditimpa
+	Put(key string, content []byte, contentType string) (Object, error)
+	// Get membaca isi file, untuk dokumen privat yang tidak dibuka lewat URL publik
+	Get(key string) ([]byte, error)
+	// Delete menghapus file, key yang sudah tidak ada tidak dianggap error
+	Delete(key string) error
+	// URL alamat publik file yang bisa langsung dipakai di tag img
+	URL(key string) string
+}
+
+// Object file yang tersimpan. Key disimpan bersama URL supaya file bisa dihapus saat diganti.
+type Object struct {
+	Key string `json:"key" bson:"key"`
+	URL string `json:"url" bson:"url"`
+}
+
+// CleanKey merapikan key menjadi path relatif tanpa "..", supaya tidak bisa keluar dari folder/bucket
+func CleanKey(key string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(key, "\\", "/")), "/")
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", errors.New("key file tidak valid: " + key)
+	}
+	return cleaned, nil
+}
+
+// joinURL menggabungkan base URL dan key dengan satu garis miring
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
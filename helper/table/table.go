@@ -0,0 +1,235 @@
+package table
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/watoken"
+	"github.com/gocroot/model"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collection nama koleksi meja
+const Collection = "tables"
+
+// TokenLifetime masa berlaku token QR meja. QR dicetak dan ditempel di meja, jadi dibuat panjang;
+// QR lama dibatalkan dengan menaikkan TokenVersion, bukan menunggu kedaluwarsa.
+const TokenLifetime = 5 * 365 * 24 * time.Hour
+
+// tokenPrefix id token meja, supaya token meja tidak pernah cocok dengan nomor telepon user di AuthMiddleware
+const tokenPrefix = "meja:"
+
+// ErrTokenInvalid - Token QR meja rusak, kedaluwarsa, sudah diganti atau mejanya tidak aktif
+var ErrTokenInvalid = errors.New("QR meja tidak valid")
+
+// ErrNotSettleable - Meja belum bisa ditutup karena masih ada pesanan yang belum selesai atau belum dibayar
+var ErrNotSettleable = errors.New("meja belum bisa ditutup")
+
+// Validate - Mengecek isian meja sebelum disimpan oleh admin
+func Validate(t model.Table) error {
+	if strings.TrimSpace(t.Number) == "" {
+		return errors.New("nomor meja wajib diisi")
+	}
+	if t.Seats < 0 {
+		return errors.New("jumlah kursi tidak boleh minus")
+	}
+	return nil
+}
+
+// EncodeToken membuat token QR untuk meja dengan versi token meja saat ini
+func EncodeToken(t model.Table, privateKey string) (string, error) {
+	data := model.TableToken{TableID: t.ID, Number: t.Number, Version: t.TokenVersion}
+	return watoken.EncodeWithStructDuration(tokenPrefix+t.ID.Hex(), &data, privateKey, TokenLifetime)
+}
+
+// ParseToken memeriksa tanda tangan dan masa berlaku token QR meja tanpa membaca database
+func ParseToken(publicKey, token string) (model.TableToken, error) {
+	payload, err := watoken.DecodeWithStruct[model.TableToken](publicKey, token)
+	if err != nil {
+		return model.TableToken{}, fmt.Errorf("%w: %s", ErrTokenInvalid, err.Error())
+	}
+	if payload.Id != tokenPrefix+payload.Data.TableID.Hex() || payload.Data.TableID.IsZero() {
+		return model.TableToken{}, ErrTokenInvalid
+	}
+	return payload.Data, nil
+}
+
+// Verify memeriksa token QR meja lalu mengambil mejanya. Token ditolak jika meja sudah dihapus,
+// dinonaktifkan, atau QR-nya sudah diganti (versi token tidak sama).
+func Verify(db *mongo.Database, publicKey, token string) (model.Table, error) {
+	data, err := ParseToken(publicKey, token)
+	if err != nil {
+		return model.Table{}, err
+	}
+	t, err := atdb.GetOneDoc[model.Table](db, Collection, atdb.ExcludeDeleted(bson.M{"_id": data.TableID}))
+	if err == mongo.ErrNoDocuments {
+		return model.Table{}, fmt.Errorf("%w: meja tidak ditemukan", ErrTokenInvalid)
+	} else if err != nil {
+		return model.Table{}, err
+	}
+	if !t.Active {
+		return model.Table{}, fmt.Errorf("%w: meja %s sedang tidak aktif", ErrTokenInvalid, t.Number)
+	}
+	if data.Version != t.TokenVersion {
+		return model.Table{}, fmt.Errorf("%w: QR meja %s sudah diganti", ErrTokenInvalid, t.Number)
+	}
+	return t, nil
+}
+
+type tableKey struct{}
+
+// WithTable menyimpan meja dari token QR ke context request, dipakai middleware untuk pesanan dine-in
+func WithTable(r *http.Request, t model.Table) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), tableKey{}, t))
+}
+
+// CurrentTable mengambil meja yang disimpan middleware, ok false jika pesanan tidak memakai QR meja
+func CurrentTable(r *http.Request) (t model.Table, ok bool) {
+	t, ok = r.Context().Value(tableKey{}).(model.Table)
+	return
+}
+
+// OrderURL alamat halaman pesan di frontend yang dibuka saat QR meja dipindai
+func OrderURL(baseURL, token string) string {
+	return baseURL + url.QueryEscape(token)
+}
+
+// QRCode membuat gambar PNG QR code dari alamat pesan meja
+func QRCode(orderURL string, size int) ([]byte, error) {
+	return qrcode.Encode(orderURL, qrcode.Medium, size)
+}
+
+// Card membuat kartu QR meja siap cetak (PDF A6) berisi nama toko, nomor meja dan QR code
+func Card(shopName string, t model.Table, orderURL string) ([]byte, error) {
+	qr, err := QRCode(orderURL, 512)
+	if err != nil {
+		return nil, err
+	}
+	pdf := gofpdf.New("P", "mm", "A6", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(false, 10)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	width, _ := pdf.GetPageSize()
+	content := width - 20
+
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(content, 8, tr(shopName), "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "B", 28)
+	pdf.CellFormat(content, 14, tr("Meja "+t.Number), "", 1, "C", false, 0, "")
+	if t.Area != "" {
+		pdf.SetFont("Arial", "", 11)
+		pdf.CellFormat(content, 6, tr(t.Area), "", 1, "C", false, 0, "")
+	}
+
+	qrSize := 70.0
+	pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+	pdf.ImageOptions("qr", (width-qrSize)/2, pdf.GetY()+4, qrSize, qrSize, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	pdf.SetY(pdf.GetY() + qrSize + 8)
+
+	pdf.SetFont("Arial", "", 11)
+	pdf.MultiCell(content, 5, tr("Pindai QR untuk melihat menu dan memesan langsung dari meja ini"), "", "C", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// OpenFilter filter pesanan meja yang belum ditutup kasir, pesanan dibatalkan dan ditolak tidak dihitung.
+// tableID kosong berarti semua meja.
+func OpenFilter(tableID primitive.ObjectID) bson.M {
+	filter := bson.M{
+		"table_id":   bson.M{"$exists": true},
+		"settled_at": bson.M{"$exists": false},
+		"status":     bson.M{"$nin": []string{model.OrderStatusDibatalkan, model.OrderStatusDitolak}},
+	}
+	if !tableID.IsZero() {
+		filter["table_id"] = tableID
+	}
+	return filter
+}
+
+// OpenTable ringkasan tagihan satu meja yang masih terbuka
+type OpenTable struct {
+	TableID     primitive.ObjectID `json:"table_id"`
+	TableNumber string             `json:"table_number"`
+	Orders      []model.Order      `json:"orders"`
+	Total       float64            `json:"total"`
+	Paid        float64            `json:"paid"`   // Sudah lunas, misalnya QRIS
+	Unpaid      float64            `json:"unpaid"` // Masih harus dibayar di kasir
+	OpenedAt    time.Time          `json:"opened_at"`
+}
+
+// Group mengelompokkan pesanan terbuka per meja, diurutkan dari meja yang paling lama terbuka
+func Group(orders []model.Order) []OpenTable {
+	index := make(map[primitive.ObjectID]int)
+	var tables []OpenTable
+	for _, order := range orders {
+		i, ok := index[order.TableID]
+		if !ok {
+			i = len(tables)
+			index[order.TableID] = i
+			tables = append(tables, OpenTable{TableID: order.TableID, TableNumber: order.TableNumber, OpenedAt: order.OrderDate})
+		}
+		t := &tables[i]
+		t.Orders = append(t.Orders, order)
+		t.Total += order.Total
+		if order.PaymentStatus == model.PaymentStatusLunas {
+			t.Paid += order.Total
+		} else {
+			t.Unpaid += order.Total
+		}
+		if order.OrderDate.Before(t.OpenedAt) {
+			t.OpenedAt = order.OrderDate
+		}
+	}
+	for i := range tables {
+		sort.Slice(tables[i].Orders, func(a, b int) bool { return tables[i].Orders[a].OrderDate.Before(tables[i].Orders[b].OrderDate) })
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].OpenedAt.Before(tables[j].OpenedAt) })
+	return tables
+}
+
+// CheckSettle memastikan semua pesanan meja sudah selesai dan tidak ada tagihan QRIS yang belum lunas.
+// Pesanan Cash yang belum dibayar dianggap dibayar di kasir saat meja ditutup.
+func CheckSettle(orders []model.Order) error {
+	var pending []string
+	for _, order := range orders {
+		switch {
+		case order.Status != model.OrderStatusSelesai:
+			pending = append(pending, order.OrderNumber+" masih "+order.Status)
+		case order.PaymentStatus != model.PaymentStatusLunas && order.PaymentStatus != model.PaymentStatusBelumDibayar:
+			pending = append(pending, order.OrderNumber+" "+order.PaymentStatus)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotSettleable, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Settle menutup meja: pesanan yang masih terbuka ditandai settled dan pesanan Cash yang belum dibayar menjadi lunas.
+// Hanya pesanan yang sudah diperiksa (ids) yang diubah, pesanan baru yang masuk bersamaan tetap terbuka.
+func Settle(db *mongo.Database, ids []primitive.ObjectID, by string, at time.Time) error {
+	unpaid := bson.M{"_id": bson.M{"$in": ids}, "settled_at": bson.M{"$exists": false}, "payment_status": model.PaymentStatusBelumDibayar}
+	if _, err := atdb.UpdateManyDocs(db, "orders", unpaid, bson.M{"$set": bson.M{"payment_status": model.PaymentStatusLunas}}); err != nil {
+		return err
+	}
+	filter := bson.M{"_id": bson.M{"$in": ids}, "settled_at": bson.M{"$exists": false}}
+	_, err := atdb.UpdateManyDocs(db, "orders", filter, bson.M{"$set": bson.M{"settled_at": at, "settled_by": by}})
+	return err
+}
@@ -0,0 +1,65 @@
+package table
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocroot/helper/watoken"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	privateKey, publicKey := watoken.GenerateKey()
+	meja := model.Table{ID: primitive.NewObjectID(), Number: "7", TokenVersion: 3}
+	token, err := EncodeToken(meja, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ParseToken(publicKey, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TableID != meja.ID || data.Number != "7" || data.Version != 3 {
+		t.Errorf("data = %+v", data)
+	}
+
+	// token login user tidak boleh dipakai sebagai token meja
+	login, _ := watoken.EncodeWithStruct(meja.ID.Hex(), &model.TableToken{TableID: meja.ID}, privateKey)
+	if _, err := ParseToken(publicKey, login); !errors.Is(err, ErrTokenInvalid) {
+		t.Errorf("token login: err = %v, want ErrTokenInvalid", err)
+	}
+	_, otherPublic := watoken.GenerateKey()
+	if _, err := ParseToken(otherPublic, token); !errors.Is(err, ErrTokenInvalid) {
+		t.Errorf("kunci lain: err = %v, want ErrTokenInvalid", err)
+	}
+}
+
+func TestGroupAndCheckSettle(t *testing.T) {
+	meja1, meja2 := primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Now()
+	orders := []model.Order{
+		{OrderNumber: "A", TableID: meja2, TableNumber: "2", OrderDate: now, Total: 20000, Status: model.OrderStatusSelesai, PaymentStatus: model.PaymentStatusBelumDibayar},
+		{OrderNumber: "B", TableID: meja1, TableNumber: "1", OrderDate: now.Add(-time.Hour), Total: 15000, Status: model.OrderStatusSelesai, PaymentStatus: model.PaymentStatusLunas},
+		{OrderNumber: "C", TableID: meja2, TableNumber: "2", OrderDate: now.Add(-30 * time.Minute), Total: 30000, Status: model.OrderStatusDiproses, PaymentStatus: model.PaymentStatusLunas},
+	}
+	tables := Group(orders)
+	if len(tables) != 2 || tables[0].TableNumber != "1" || tables[1].TableNumber != "2" {
+		t.Fatalf("tables = %+v", tables)
+	}
+	if m := tables[1]; m.Total != 50000 || m.Paid != 30000 || m.Unpaid != 20000 || m.Orders[0].OrderNumber != "C" {
+		t.Errorf("meja 2 = %+v", m)
+	}
+
+	if err := CheckSettle(tables[0].Orders); err != nil {
+		t.Errorf("meja 1: %v", err)
+	}
+	if err := CheckSettle(tables[1].Orders); !errors.Is(err, ErrNotSettleable) {
+		t.Errorf("meja 2: err = %v, want ErrNotSettleable", err)
+	}
+	qris := model.Order{OrderNumber: "D", Status: model.OrderStatusSelesai, PaymentStatus: model.PaymentStatusMenunggu}
+	if err := CheckSettle([]model.Order{qris}); !errors.Is(err, ErrNotSettleable) {
+		t.Errorf("QRIS belum lunas: err = %v, want ErrNotSettleable", err)
+	}
+}
@@ -0,0 +1,339 @@
+package voucher
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrVoucherInvalid - Voucher tidak bisa dipakai untuk pesanan ini (tidak ada, kedaluwarsa, kuota habis, syarat tidak terpenuhi)
+var ErrVoucherInvalid = errors.New("voucher tidak dapat digunakan")
+
+// NormalizeCode - Kode voucher tidak membedakan huruf besar/kecil
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate - Mengecek isian voucher sebelum disimpan oleh admin
+func Validate(v model.Voucher) error {
+	if v.Code == "" || v.Name == "" {
+		return errors.New("kode dan nama voucher wajib diisi")
+	}
+	switch v.Type {
+	case model.VoucherPercentage:
+		if v.Value <= 0 || v.Value > 100 {
+			return errors.New("persentase potongan harus antara 0 dan 100")
+		}
+	case model.VoucherFixed:
+		if v.Value <= 0 {
+			return errors.New("nominal potongan harus lebih dari 0")
+		}
+	case model.VoucherBuyXGetY:
+		if v.BuyQuantity <= 0 || v.GetQuantity <= 0 {
+			return errors.New("jumlah beli dan gratis harus lebih dari 0")
+		}
+	default:
+		return errors.New("tipe voucher harus percentage, fixed atau bxgy")
+	}
+	if v.MaxDiscount < 0 || v.MinSpend < 0 || v.UsageLimit < 0 || v.PerUserLimit < 0 {
+		return errors.New("batas potongan, minimal belanja dan kuota tidak boleh minus")
+	}
+	if !v.EndAt.IsZero() && !v.EndAt.After(v.StartAt) {
+		return errors.New("waktu berakhir harus setelah waktu mulai")
+	}
+	return nil
+}
+
+// Calculate menghitung potongan voucher untuk item pesanan yang sudah dihargai server.
+// categories memetakan menu ke kategorinya, dipakai jika voucher dibatasi per kategori.
+func Calculate(v model.Voucher, items []model.OrderItem, categories map[primitive.ObjectID]primitive.ObjectID, now time.Time) (discount model.OrderDiscount, err error) {
+	if !v.Active {
+		err = fmt.Errorf("%w: voucher %s tidak aktif", ErrVoucherInvalid, v.Code)
+		return
+	}
+	if now.Before(v.StartAt) || (!v.EndAt.IsZero() && !now.Before(v.EndAt)) {
+		err = fmt.Errorf("%w: voucher %s di luar masa berlaku", ErrVoucherInvalid, v.Code)
+		return
+	}
+
+	var subtotal float64
+	var eligible []model.OrderItem
+	for _, item := range items {
+		subtotal += item.Price * float64(item.Quantity)
+		if isEligible(v, item.MenuID, categories[item.MenuID]) {
+			eligible = append(eligible, item)
+		}
+	}
+	if subtotal < v.MinSpend {
+		err = fmt.Errorf("%w: minimal belanja voucher %s adalah %.0f", ErrVoucherInvalid, v.Code, v.MinSpend)
+		return
+	}
+	if len(eligible) == 0 {
+		err = fmt.Errorf("%w: tidak ada item yang berlaku untuk voucher %s", ErrVoucherInvalid, v.Code)
+		return
+	}
+
+	var lines []model.DiscountLine
+	switch v.Type {
+	case model.VoucherPercentage:
+		var eligibleTotal float64
+		for _, item := range eligible {
+			eligibleTotal += item.Price * float64(item.Quantity)
+		}
+		amount := eligibleTotal * v.Value / 100
+		if v.MaxDiscount > 0 && amount > v.MaxDiscount {
+			amount = v.MaxDiscount
+		}
+		lines = spread(eligible, amount)
+	case model.VoucherFixed:
+		lines = spread(eligible, v.Value)
+	case model.VoucherBuyXGetY:
+		lines = buyXGetY(eligible, v.BuyQuantity, v.GetQuantity, v.MaxDiscount)
+	}
+
+	discount = model.OrderDiscount{VoucherID: v.ID, Code: v.Code, Type: v.Type, Items: lines}
+	for _, line := range lines {
+		discount.Amount += line.Amount
+	}
+	if discount.Amount <= 0 {
+		err = fmt.Errorf("%w: voucher %s tidak memberikan potongan untuk pesanan ini", ErrVoucherInvalid, v.Code)
+	}
+	return
+}
+
+func isEligible(v model.Voucher, menuID, categoryID primitive.ObjectID) bool {
+	if len(v.MenuIDs) == 0 && len(v.CategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range v.MenuIDs {
+		if id == menuID {
+			return true
+		}
+	}
+	for _, id := range v.CategoryIDs {
+		if id == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// spread membagi potongan ke setiap item secara proporsional terhadap subtotal item, dibulatkan ke rupiah.
+// Potongan tidak pernah melebihi subtotal item yang berlaku.
+func spread(items []model.OrderItem, amount float64) (lines []model.DiscountLine) {
+	var total float64
+	for _, item := range items {
+		total += item.Price * float64(item.Quantity)
+	}
+	amount = math.Round(math.Min(amount, total))
+	remaining := amount
+	for i, item := range items {
+		share := math.Round(amount * item.Price * float64(item.Quantity) / total)
+		if i == len(items)-1 || share > remaining {
+			share = remaining
+		}
+		remaining -= share
+		lines = append(lines, model.DiscountLine{MenuID: item.MenuID, MenuName: item.MenuName, Amount: share})
+	}
+	return
+}
+
+// buyXGetY - Dari setiap kelompok buy+get porsi (diurutkan dari yang termahal), get porsi termurah digratiskan
+func buyXGetY(items []model.OrderItem, buy, get int, maxDiscount float64) (lines []model.DiscountLine) {
+	type unit struct {
+		index int
+		price float64
+	}
+	var units []unit
+	for i, item := range items {
+		for q := 0; q < item.Quantity; q++ {
+			units = append(units, unit{index: i, price: item.Price})
+		}
+	}
+	sort.SliceStable(units, func(a, b int) bool { return units[a].price > units[b].price })
+
+	free := make([]int, len(items))
+	amounts := make([]float64, len(items))
+	var total float64
+	for start := 0; start+buy+get <= len(units); start += buy + get {
+		for _, u := range units[start+buy : start+buy+get] {
+			if maxDiscount > 0 && total+u.price > maxDiscount {
+				continue
+			}
+			free[u.index]++
+			amounts[u.index] += u.price
+			total += u.price
+		}
+	}
+	for i, item := range items {
+		if free[i] > 0 {
+			lines = append(lines, model.DiscountLine{MenuID: item.MenuID, MenuName: item.MenuName, FreeQuantity: free[i], Amount: amounts[i]})
+		}
+	}
+	return
+}
+
+// Apply mencari voucher berdasarkan kode, menghitung potongannya, lalu mengklaim kuota pemakaian secara atomik.
+// customer adalah nomor WhatsApp terverifikasi pemesan, kosong jika tidak ada; voucher dengan batas per pelanggan
+// ditolak tanpa nomor terverifikasi. Jika pesanan gagal disimpan, kuota harus dikembalikan dengan Release.
+func Apply(db *mongo.Database, code string, items []model.OrderItem, customer string, now time.Time) (discount model.OrderDiscount, err error) {
+	v, err := atdb.GetOneDoc[model.Voucher](db, "voucher", atdb.ExcludeDeleted(bson.M{"code": NormalizeCode(code)}))
+	if err == mongo.ErrNoDocuments {
+		err = fmt.Errorf("%w: kode voucher %s tidak ditemukan", ErrVoucherInvalid, NormalizeCode(code))
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	categories := make(map[primitive.ObjectID]primitive.ObjectID)
+	if len(v.CategoryIDs) > 0 {
+		var menuIDs []primitive.ObjectID
+		for _, item := range items {
+			menuIDs = append(menuIDs, item.MenuID)
+		}
+		menus, errMenu := atdb.GetAllDoc[[]model.Menu](db, "menu", bson.M{"_id": bson.M{"$in": menuIDs}})
+		if errMenu != nil {
+			err = errMenu
+			return
+		}
+		for _, menu := range menus {
+			categories[menu.ID] = menu.CategoryID
+		}
+	}
+
+	if discount, err = Calculate(v, items, categories, now); err != nil {
+		return
+	}
+	if err = claim(db, v, customer); err == nil {
+		discount.Customer = customer
+	}
+	return
+}
+
+// claim - Kuota per pelanggan diklaim lebih dulu lewat counter, lalu kuota total lewat update bersyarat pada voucher
+func claim(db *mongo.Database, v model.Voucher, customer string) (err error) {
+	if v.PerUserLimit > 0 && customer == "" {
+		return fmt.Errorf("%w: voucher %s hanya untuk pelanggan yang login dengan nomor WhatsApp terverifikasi", ErrVoucherInvalid, v.Code)
+	}
+	if v.PerUserLimit > 0 {
+		_, ok, errCounter := atdb.IncrementCounterLimit(db, "voucher_usage", usageKey(v.ID, customer), v.PerUserLimit)
+		if errCounter != nil {
+			return errCounter
+		}
+		if !ok {
+			return fmt.Errorf("%w: voucher %s sudah mencapai batas pemakaian untuk pelanggan ini", ErrVoucherInvalid, v.Code)
+		}
+	}
+	filter := bson.M{
+		"_id":        v.ID,
+		"active":     true,
+		"deleted_at": bson.M{"$exists": false},
+		"$or": bson.A{
+			bson.M{"usage_limit": bson.M{"$lte": 0}},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$used_count", "$usage_limit"}}},
+		},
+	}
+	result, err := atdb.UpdateDoc(db, "voucher", filter, bson.M{"$inc": bson.M{"used_count": 1}})
+	if err == nil && result.MatchedCount == 0 {
+		err = fmt.Errorf("%w: kuota voucher %s sudah habis", ErrVoucherInvalid, v.Code)
+	}
+	if err != nil && v.PerUserLimit > 0 {
+		atdb.UpdateDoc(db, "voucher_usage", bson.M{"_id": usageKey(v.ID, customer)}, bson.M{"$inc": bson.M{"seq": -1}})
+	}
+	return
+}
+
+// Release mengembalikan kuota voucher, dipakai saat pesanan gagal disimpan atau dibatalkan
+func Release(db *mongo.Database, voucherID primitive.ObjectID, customer string) (err error) {
+	if _, err = atdb.UpdateDoc(db, "voucher", bson.M{"_id": voucherID, "used_count": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"used_count": -1}}); err != nil || customer == "" {
+		return
+	}
+	_, err = atdb.UpdateDoc(db, "voucher_usage", bson.M{"_id": usageKey(voucherID, customer), "seq": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"seq": -1}})
+	return
+}
+
+// Redeem mencatat pemakaian voucher setelah pesanan tersimpan
+func Redeem(db *mongo.Database, order model.Order) (err error) {
+	_, err = atdb.InsertOneDoc(db, "voucher_redemption", model.VoucherRedemption{
+		VoucherID:   order.Discount.VoucherID,
+		Code:        order.Discount.Code,
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		Customer:    order.Discount.Customer,
+		Discount:    order.Discount.Amount,
+		At:          order.OrderDate,
+	})
+	return
+}
+
+// Cancel mengembalikan kuota voucher dari pesanan yang dibatalkan/ditolak dan menandai pemakaiannya batal.
+// Klaim kuota dibaca dari potongan pada pesanan, sehingga tetap dikembalikan walaupun Redeem gagal mencatat pemakaian.
+// Tanda released pada pesanan memastikan kuota hanya dikembalikan sekali.
+func Cancel(db *mongo.Database, order model.Order) (err error) {
+	if order.Discount == nil {
+		return
+	}
+	filter := bson.M{"_id": order.ID, "discount": bson.M{"$exists": true}, "discount.released": bson.M{"$ne": true}}
+	result, err := atdb.UpdateDoc(db, "orders", filter, bson.M{"$set": bson.M{"discount.released": true}})
+	if err != nil || result.MatchedCount == 0 {
+		return
+	}
+	customer := order.Discount.Customer
+	redemption, errRedemption := atdb.FindOneAndUpdateDoc[model.VoucherRedemption](db, "voucher_redemption",
+		bson.M{"order_id": order.ID, "cancelled": false}, bson.M{"$set": bson.M{"cancelled": true}})
+	if errRedemption == nil && customer == "" {
+		customer = redemption.Customer // pesanan lama sebelum klaim dicatat pada pesanan
+	}
+	if err = Release(db, order.Discount.VoucherID, customer); err != nil {
+		return
+	}
+	if errRedemption != mongo.ErrNoDocuments {
+		err = errRedemption
+	}
+	return
+}
+
+func usageKey(voucherID primitive.ObjectID, customer string) string {
+	return voucherID.Hex() + ":" + customer
+}
+
+// Stats ringkasan pemakaian sebuah voucher
+type Stats struct {
+	Redemptions     int       `json:"redemptions" bson:"redemptions"` // Pemakaian yang tidak dibatalkan
+	Cancelled       int       `json:"cancelled" bson:"cancelled"`
+	TotalDiscount   float64   `json:"total_discount" bson:"total_discount"`
+	UniqueCustomers int       `json:"unique_customers" bson:"unique_customers"`
+	LastRedeemedAt  time.Time `json:"last_redeemed_at,omitempty" bson:"last_redeemed_at"`
+}
+
+// GetStats menghitung statistik pemakaian voucher dari collection voucher_redemption
+func GetStats(db *mongo.Database, voucherID primitive.ObjectID) (stats Stats, err error) {
+	active := bson.M{"$eq": bson.A{"$cancelled", false}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"voucher_id": voucherID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":              nil,
+			"redemptions":      bson.M{"$sum": bson.M{"$cond": bson.A{active, 1, 0}}},
+			"cancelled":        bson.M{"$sum": bson.M{"$cond": bson.A{active, 0, 1}}},
+			"total_discount":   bson.M{"$sum": bson.M{"$cond": bson.A{active, "$discount", 0}}},
+			"customers":        bson.M{"$addToSet": bson.M{"$cond": bson.A{bson.M{"$and": bson.A{active, bson.M{"$ne": bson.A{"$customer", ""}}}}, "$customer", "$$REMOVE"}}},
+			"last_redeemed_at": bson.M{"$max": "$at"},
+		}}},
+		{{Key: "$addFields", Value: bson.M{"unique_customers": bson.M{"$size": "$customers"}}}},
+	}
+	result, err := atdb.AggregateDocs[Stats](db, "voucher_redemption", pipeline)
+	if err != nil || len(result) == 0 {
+		return
+	}
+	return result[0], nil
+}
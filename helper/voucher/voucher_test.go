@@ -0,0 +1,168 @@
+package voucher
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCalculate(t *testing.T) {
+	now := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+	kopi, latte, croissant := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	minuman, pastry := primitive.NewObjectID(), primitive.NewObjectID()
+	categories := map[primitive.ObjectID]primitive.ObjectID{kopi: minuman, latte: minuman, croissant: pastry}
+
+	// subtotal 55.000
+	items := []model.OrderItem{
+		{MenuID: kopi, MenuName: "Kopi Susu", Price: 20000, Quantity: 2},
+		{MenuID: croissant, MenuName: "Croissant", Price: 15000, Quantity: 1},
+	}
+	base := model.Voucher{Code: "HEMAT", Active: true, StartAt: now.Add(-time.Hour)}
+	with := func(change func(v *model.Voucher)) model.Voucher {
+		v := base
+		change(&v)
+		return v
+	}
+
+	tests := []struct {
+		name    string
+		voucher model.Voucher
+		items   []model.OrderItem
+		want    []model.DiscountLine
+		wantErr bool
+	}{
+		{
+			name:    "persen dibagi proporsional",
+			voucher: with(func(v *model.Voucher) { v.Type, v.Value = model.VoucherPercentage, 10 }),
+			items:   items,
+			want: []model.DiscountLine{
+				{MenuID: kopi, MenuName: "Kopi Susu", Amount: 4000},
+				{MenuID: croissant, MenuName: "Croissant", Amount: 1500},
+			},
+		},
+		{
+			name:    "persen dibatasi max discount",
+			voucher: with(func(v *model.Voucher) { v.Type, v.Value, v.MaxDiscount = model.VoucherPercentage, 50, 10000 }),
+			items:   items,
+			want: []model.DiscountLine{
+				{MenuID: kopi, MenuName: "Kopi Susu", Amount: 7273},
+				{MenuID: croissant, MenuName: "Croissant", Amount: 2727},
+			},
+		},
+		{
+			name:    "minimal belanja tidak terpenuhi",
+			voucher: with(func(v *model.Voucher) { v.Type, v.Value, v.MinSpend = model.VoucherFixed, 5000, 60000 }),
+			items:   items,
+			wantErr: true,
+		},
+		{
+			name: "hanya menu tertentu",
+			voucher: with(func(v *model.Voucher) {
+				v.Type, v.Value, v.MenuIDs = model.VoucherPercentage, 20, []primitive.ObjectID{croissant}
+			}),
+			items: items,
+			want:  []model.DiscountLine{{MenuID: croissant, MenuName: "Croissant", Amount: 3000}},
+		},
+		{
+			name: "hanya kategori tertentu",
+			voucher: with(func(v *model.Voucher) {
+				v.Type, v.Value, v.CategoryIDs = model.VoucherPercentage, 10, []primitive.ObjectID{minuman}
+			}),
+			items: items,
+			want:  []model.DiscountLine{{MenuID: kopi, MenuName: "Kopi Susu", Amount: 4000}},
+		},
+		{
+			name: "tidak ada item yang berlaku",
+			voucher: with(func(v *model.Voucher) {
+				v.Type, v.Value, v.MenuIDs = model.VoucherFixed, 5000, []primitive.ObjectID{latte}
+			}),
+			items:   items,
+			wantErr: true,
+		},
+		{
+			name:    "nominal tidak melebihi subtotal",
+			voucher: with(func(v *model.Voucher) { v.Type, v.Value = model.VoucherFixed, 100000 }),
+			items:   items,
+			want: []model.DiscountLine{
+				{MenuID: kopi, MenuName: "Kopi Susu", Amount: 40000},
+				{MenuID: croissant, MenuName: "Croissant", Amount: 15000},
+			},
+		},
+		{
+			name:    "pembulatan sisa ke item terakhir",
+			voucher: with(func(v *model.Voucher) { v.Type, v.Value = model.VoucherFixed, 1000 }),
+			items: []model.OrderItem{
+				{MenuID: kopi, MenuName: "Kopi Susu", Price: 10000, Quantity: 1},
+				{MenuID: latte, MenuName: "Latte", Price: 10000, Quantity: 1},
+				{MenuID: croissant, MenuName: "Croissant", Price: 10000, Quantity: 1},
+			},
+			want: []model.DiscountLine{
+				{MenuID: kopi, MenuName: "Kopi Susu", Amount: 333},
+				{MenuID: latte, MenuName: "Latte", Amount: 333},
+				{MenuID: croissant, MenuName: "Croissant", Amount: 334},
+			},
+		},
+		{
+			name:    "beli 1 gratis 1 porsi termurah dari pasangan",
+			voucher: with(func(v *model.Voucher) { v.Type, v.BuyQuantity, v.GetQuantity = model.VoucherBuyXGetY, 1, 1 }),
+			items:   items,
+			want:    []model.DiscountLine{{MenuID: kopi, MenuName: "Kopi Susu", FreeQuantity: 1, Amount: 20000}},
+		},
+		{
+			name:    "beli 2 gratis 1",
+			voucher: with(func(v *model.Voucher) { v.Type, v.BuyQuantity, v.GetQuantity = model.VoucherBuyXGetY, 2, 1 }),
+			items:   items,
+			want:    []model.DiscountLine{{MenuID: croissant, MenuName: "Croissant", FreeQuantity: 1, Amount: 15000}},
+		},
+		{
+			name: "beli 1 gratis 1 dibatasi max discount",
+			voucher: with(func(v *model.Voucher) {
+				v.Type, v.BuyQuantity, v.GetQuantity, v.MaxDiscount = model.VoucherBuyXGetY, 1, 1, 15000
+			}),
+			items: []model.OrderItem{
+				{MenuID: kopi, MenuName: "Kopi Susu", Price: 20000, Quantity: 2},
+				{MenuID: croissant, MenuName: "Croissant", Price: 15000, Quantity: 2},
+			},
+			want: []model.DiscountLine{{MenuID: croissant, MenuName: "Croissant", FreeQuantity: 1, Amount: 15000}},
+		},
+		{
+			name:    "voucher tidak aktif",
+			voucher: with(func(v *model.Voucher) { v.Type, v.Value, v.Active = model.VoucherFixed, 5000, false }),
+			items:   items,
+			wantErr: true,
+		},
+		{
+			name:    "masa berlaku habis",
+			voucher: with(func(v *model.Voucher) { v.Type, v.Value, v.EndAt = model.VoucherFixed, 5000, now }),
+			items:   items,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := Calculate(tt.voucher, tt.items, categories, now)
+		if tt.wantErr {
+			if !errors.Is(err, ErrVoucherInvalid) {
+				t.Errorf("%s: err = %v, want ErrVoucherInvalid", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Items, tt.want) {
+			t.Errorf("%s: Items = %+v, want %+v", tt.name, got.Items, tt.want)
+		}
+		var amount float64
+		for _, line := range tt.want {
+			amount += line.Amount
+		}
+		if got.Amount != amount {
+			t.Errorf("%s: Amount = %.0f, want %.0f", tt.name, got.Amount, amount)
+		}
+	}
+}
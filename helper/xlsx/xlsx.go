@@ -0,0 +1,114 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sheet satu lembar kerja, setiap baris berisi nilai string atau angka
+type Sheet struct {
+	Name string
+	Rows [][]interface{}
+}
+
+// Write membuat file .xlsx (SpreadsheetML) minimal tanpa style dari beberapa sheet
+func Write(sheets []Sheet) (filecontent []byte, err error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	var overrides, workbookSheets, workbookRels strings.Builder
+	for i, sheet := range sheets {
+		n := strconv.Itoa(i + 1)
+		overrides.WriteString(`<Override PartName="/xl/worksheets/sheet` + n + `.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`)
+		workbookSheets.WriteString(`<sheet name="` + escape(sheetName(sheet.Name, i)) + `" sheetId="` + n + `" r:id="rId` + n + `"/>`)
+		workbookRels.WriteString(`<Relationship Id="rId` + n + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet` + n + `.xml"/>`)
+	}
+
+	files := []struct{ name, body string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + workbookSheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			workbookRels.String() + `</Relationships>`},
+	}
+	for i, sheet := range sheets {
+		files = append(files, struct{ name, body string }{"xl/worksheets/sheet" + strconv.Itoa(i+1) + ".xml", sheetXML(sheet)})
+	}
+
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = w.Write([]byte(f.body)); err != nil {
+			return nil, err
+		}
+	}
+	if err = zw.Close(); err != nil {
+		return
+	}
+	return buf.Bytes(), nil
+}
+
+func sheetXML(sheet Sheet) string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range sheet.Rows {
+		rowNum := strconv.Itoa(r + 1)
+		sb.WriteString(`<row r="` + rowNum + `">`)
+		for c, value := range row {
+			ref := ColumnName(c) + rowNum
+			switch v := value.(type) {
+			case int:
+				sb.WriteString(`<c r="` + ref + `"><v>` + strconv.Itoa(v) + `</v></c>`)
+			case int64:
+				sb.WriteString(`<c r="` + ref + `"><v>` + strconv.FormatInt(v, 10) + `</v></c>`)
+			case float64:
+				sb.WriteString(`<c r="` + ref + `"><v>` + strconv.FormatFloat(v, 'f', -1, 64) + `</v></c>`)
+			default:
+				sb.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t>` + escape(fmt.Sprint(v)) + `</t></is></c>`)
+			}
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+// ColumnName mengubah indeks kolom (mulai 0) menjadi nama kolom Excel: 0=A, 25=Z, 26=AA
+func ColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// nama sheet Excel maksimal 31 karakter dan tidak boleh kosong
+func sheetName(name string, index int) string {
+	if name == "" {
+		name = "Sheet" + strconv.Itoa(index+1)
+	}
+	if len(name) > 31 {
+		name = name[:31]
+	}
+	return name
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,99 @@
+package keranjang
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/rupiah"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Timeout keranjang yang tidak disentuh selama ini dianggap kosong
+const Timeout = 30 * time.Minute
+
+// Get mengambil keranjang nomor WhatsApp, keranjang yang belum ada atau sudah kedaluwarsa dikembalikan kosong
+func Get(db *mongo.Database, phonenumber string) (cart Cart, err error) {
+	cart, err = atdb.GetOneDoc[Cart](db, "cart_session", bson.M{"_id": phonenumber})
+	if err == mongo.ErrNoDocuments || (err == nil && Expired(cart, time.Now())) {
+		return Cart{Phone: phonenumber}, nil
+	}
+	return
+}
+
+// Expired keranjang kedaluwarsa jika tidak disentuh lebih dari Timeout
+func Expired(cart Cart, now time.Time) bool {
+	return now.Sub(cart.UpdatedAt) > Timeout
+}
+
+// Save menyimpan isi keranjang sekaligus memperpanjang waktu sesinya
+func Save(db *mongo.Database, cart Cart) (err error) {
+	_, err = atdb.UpdateOneDoc(db, "cart_session", bson.M{"_id": cart.Phone}, bson.M{
+		"items":      cart.Items,
+		"pending":    cart.Pending,
+		"updated_at": time.Now(),
+	})
+	return
+}
+
+// Clear menghapus keranjang, dipakai setelah order dibuat atau dibatalkan
+func Clear(db *mongo.Database, phonenumber string) (err error) {
+	_, err = atdb.DeleteOneDoc(db, "cart_session", bson.M{"_id": phonenumber})
+	return
+}
+
+// AddItem menambahkan item ke keranjang, item dengan menu dan opsi yang sama digabung kuantitasnya
+func AddItem(cart *Cart, item model.OrderItem) {
+	label := pesanan.ModifierLabel(item.Modifiers)
+	for i, existing := range cart.Items {
+		if existing.MenuID == item.MenuID && pesanan.ModifierLabel(existing.Modifiers) == label {
+			cart.Items[i].Quantity += item.Quantity
+			return
+		}
+	}
+	cart.Items = append(cart.Items, item)
+}
+
+// RemoveItem menghapus item berdasarkan nomor urut mulai dari 1, ok false jika nomornya tidak ada
+func RemoveItem(cart *Cart, no int) (removed model.OrderItem, ok bool) {
+	if no < 1 || no > len(cart.Items) {
+		return
+	}
+	removed = cart.Items[no-1]
+	cart.Items = append(cart.Items[:no-1], cart.Items[no:]...)
+	return removed, true
+}
+
+// Total jumlah harga semua item
+func Total(items []model.OrderItem) (total float64) {
+	for _, item := range items {
+		total += item.Price * float64(item.Quantity)
+	}
+	return
+}
+
+// ItemLines baris item untuk keranjang dan konfirmasi, misalnya "1. 2x Kopi Susu (Large) - Rp 50.000,00"
+func ItemLines(items []model.OrderItem) (lines string) {
+	for i, item := range items {
+		lines += strconv.Itoa(i+1) + ". " + strconv.Itoa(item.Quantity) + "x " + item.MenuName
+		if label := pesanan.ModifierLabel(item.Modifiers); label != "" {
+			lines += " (" + label + ")"
+		}
+		lines += " - " + rupiah.Format(item.Price*float64(item.Quantity)) + "\n"
+	}
+	return
+}
+
+// ChosenOptions opsi yang sudah dipilih dalam satu grup
+func ChosenOptions(selected []model.SelectedModifier, group string) map[string]bool {
+	chosen := make(map[string]bool)
+	for _, s := range selected {
+		if s.Group == group {
+			chosen[s.Option] = true
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,101 @@
+package keranjang
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	kopiID      = primitive.NewObjectID()
+	croissantID = primitive.NewObjectID()
+	large       = []model.SelectedModifier{{Group: "Ukuran", Option: "Large"}}
+)
+
+func TestAddItem(t *testing.T) {
+	var cart Cart
+	AddItem(&cart, model.OrderItem{MenuID: kopiID, MenuName: "Kopi Susu", Price: 20000, Quantity: 1})
+	AddItem(&cart, model.OrderItem{MenuID: kopiID, MenuName: "Kopi Susu", Price: 25000, Quantity: 1, Modifiers: large})
+	AddItem(&cart, model.OrderItem{MenuID: kopiID, MenuName: "Kopi Susu", Price: 20000, Quantity: 2})
+	AddItem(&cart, model.OrderItem{MenuID: kopiID, MenuName: "Kopi Susu", Price: 25000, Quantity: 1, Modifiers: large})
+
+	want := []model.OrderItem{
+		{MenuID: kopiID, MenuName: "Kopi Susu", Price: 20000, Quantity: 3},
+		{MenuID: kopiID, MenuName: "Kopi Susu", Price: 25000, Quantity: 2, Modifiers: large},
+	}
+	if !reflect.DeepEqual(cart.Items, want) {
+		t.Errorf("Items = %+v, want %+v", cart.Items, want)
+	}
+}
+
+func TestRemoveItem(t *testing.T) {
+	cart := Cart{Items: []model.OrderItem{
+		{MenuID: kopiID, MenuName: "Kopi Susu", Quantity: 1},
+		{MenuID: croissantID, MenuName: "Croissant", Quantity: 1},
+	}}
+	for _, no := range []int{0, 3, -1} {
+		if _, ok := RemoveItem(&cart, no); ok || len(cart.Items) != 2 {
+			t.Errorf("RemoveItem(%d) menghapus item yang tidak ada", no)
+		}
+	}
+	removed, ok := RemoveItem(&cart, 1)
+	if !ok || removed.MenuName != "Kopi Susu" {
+		t.Errorf("RemoveItem(1) = %+v, %v", removed, ok)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].MenuName != "Croissant" {
+		t.Errorf("Items setelah hapus = %+v", cart.Items)
+	}
+}
+
+func TestItemLinesAndTotal(t *testing.T) {
+	items := []model.OrderItem{
+		{MenuName: "Kopi Susu", Price: 25000, Quantity: 2, Modifiers: large},
+		{MenuName: "Croissant", Price: 15000, Quantity: 1},
+	}
+	want := "1. 2x Kopi Susu (Large) - Rp 50.000,00\n2. 1x Croissant - Rp 15.000,00\n"
+	if got := ItemLines(items); got != want {
+		t.Errorf("ItemLines = %q, want %q", got, want)
+	}
+	if got := Total(items); got != 65000 {
+		t.Errorf("Total = %.0f, want 65000", got)
+	}
+	if got := ItemLines(nil); got != "" {
+		t.Errorf("ItemLines(nil) = %q", got)
+	}
+}
+
+func TestChosenOptions(t *testing.T) {
+	selected := []model.SelectedModifier{
+		{Group: "Ukuran", Option: "Large"},
+		{Group: "Tambahan", Option: "Extra Shot"},
+		{Group: "Tambahan", Option: "Oat Milk"},
+	}
+	want := map[string]bool{"Extra Shot": true, "Oat Milk": true}
+	if got := ChosenOptions(selected, "Tambahan"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChosenOptions = %v, want %v", got, want)
+	}
+	if got := ChosenOptions(selected, "Gula"); len(got) != 0 {
+		t.Errorf("ChosenOptions grup kosong = %v", got)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		updatedAt time.Time
+		want      bool
+	}{
+		{now.Add(-time.Minute), false},
+		{now.Add(-Timeout), false},
+		{now.Add(-Timeout - time.Second), true},
+		{time.Time{}, true},
+	}
+	for _, tt := range tests {
+		if got := Expired(Cart{UpdatedAt: tt.updatedAt}, now); got != tt.want {
+			t.Errorf("Expired(%v) = %v, want %v", tt.updatedAt, got, tt.want)
+		}
+	}
+}
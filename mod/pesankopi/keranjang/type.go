@@ -0,0 +1,24 @@
+package keranjang
+
+import (
+	"time"
+
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cart keranjang pesanan kopi lewat WhatsApp, satu dokumen per nomor di koleksi cart_session
+type Cart struct {
+	Phone     string            `bson:"_id"`
+	Items     []model.OrderItem `bson:"items,omitempty"`
+	Pending   *PendingItem      `bson:"pending,omitempty"` // menu yang sedang dipilih opsinya, belum masuk keranjang
+	UpdatedAt time.Time         `bson:"updated_at"`
+}
+
+// PendingItem menu yang opsi modifiernya sedang ditanyakan satu grup per pesan
+type PendingItem struct {
+	MenuID    primitive.ObjectID       `bson:"menu_id"`
+	Quantity  int                      `bson:"quantity"`
+	Group     int                      `bson:"group"` // index grup modifier menu yang sedang ditanyakan
+	Modifiers []model.SelectedModifier `bson:"modifiers,omitempty"`
+}
@@ -0,0 +1,509 @@
+package pesankopi
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/audit"
+	"github.com/gocroot/helper/checkout"
+	"github.com/gocroot/helper/menu"
+	"github.com/gocroot/helper/menusearch"
+	"github.com/gocroot/helper/pesanan"
+	"github.com/gocroot/helper/phone"
+	"github.com/gocroot/helper/rupiah"
+	"github.com/gocroot/mod/pesankopi/keranjang"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Keyword awalan semua perintah pemesanan. Module didaftarkan di koleksi module dengan
+// {"name": "pesankopi", "keyword": ["pesankopi"], "personal": true, "phonenumbers": ["<nomor bot>"]}
+const Keyword = "pesankopi"
+
+// PesanKopi - Pemesanan kopi lewat WhatsApp, perintah yang dikenali:
+//
+//	pesankopi                    daftar kategori menu
+//	pesankopi kategori <id>      daftar menu dalam kategori
+//	pesankopi cari <kata>        cari menu, tahan salah ketik ("kopsu", "latte dingin")
+//	pesankopi tambah <id> [qty]  tambah menu ke keranjang, opsi modifier ditanyakan satu per satu
+//	pesankopi opsi <no|->        pilih opsi modifier, "-" untuk melewati atau selesai memilih
+//	pesankopi hapus <no>         hapus item dari keranjang
+//	pesankopi keranjang          lihat isi keranjang
+//	pesankopi checkout           cek total sebelum pesanan dibuat
+//	pesankopi konfirmasi         buat pesanan
+//	pesankopi batal              kosongkan keranjang
+//	pesankopi status             status pesanan yang masih berjalan
+//
+// Setiap balasan berisi daftar bernomor yang disimpan ke session menu, jadi pelanggan cukup membalas nomornya.
+func PesanKopi(Pesan itmodel.IteungMessage, db *mongo.Database) (reply string) {
+	args := commandArgs(Pesan.Message)
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+	switch cmd {
+	case "kategori":
+		return DaftarMenu(Pesan, args, db)
+	case "cari":
+		return CariMenu(Pesan, args, db)
+	case "tambah":
+		return TambahMenu(Pesan, args, db)
+	case "opsi":
+		return PilihOpsi(Pesan, args, db)
+	case "hapus":
+		return HapusItem(Pesan, args, db)
+	case "keranjang":
+		return LihatKeranjang(Pesan, db)
+	case "checkout":
+		return Checkout(Pesan, db)
+	case "konfirmasi":
+		return Konfirmasi(Pesan, db)
+	case "batal":
+		return BatalPesan(Pesan, db)
+	case "status":
+		return StatusPesanan(Pesan, db)
+	}
+	return DaftarKategori(Pesan, db)
+}
+
+// DaftarKategori menampilkan kategori menu
+func DaftarKategori(Pesan itmodel.IteungMessage, db *mongo.Database) (reply string) {
+	categories, err := atdb.GetAllDoc[[]model.Category](db, "category", atdb.ExcludeDeleted(bson.M{}))
+	if err != nil || len(categories) == 0 {
+		return "Mohon maaf kak, daftar menu belum tersedia"
+	}
+	var list []menu.MenuList
+	for _, category := range categories {
+		list = append(list, menu.MenuList{Keyword: Keyword + " kategori " + category.ID.Hex(), Konten: category.Name})
+	}
+	list = append(list, menu.MenuList{Keyword: Keyword + " keranjang", Konten: "🛒 Lihat keranjang"})
+	return numberedReply(Pesan.Phone_number, "☕ *Logic Coffee*\nHalo kak "+Pesan.Alias_name+", mau pesan apa hari ini? Pilih kategori:", list, db)
+}
+
+// DaftarMenu menampilkan menu yang masih tersedia dalam satu kategori
+func DaftarMenu(Pesan itmodel.IteungMessage, args []string, db *mongo.Database) (reply string) {
+	categoryID, err := objectIDArg(args)
+	if err != nil {
+		return "Kategori tidak dikenali kak, ketik *" + Keyword + "* untuk melihat daftar kategori"
+	}
+	menus, err := atdb.GetAllDoc[[]model.Menu](db, "menu", atdb.ExcludeDeleted(bson.M{"category_id": categoryID}))
+	if err != nil {
+		return "Mohon maaf kak, menu gagal diambil: " + err.Error()
+	}
+	var list []menu.MenuList
+	for _, kopi := range menus {
+		if !pesanan.IsMenuOrderable(kopi) {
+			continue
+		}
+		list = append(list, menu.MenuList{Keyword: Keyword + " tambah " + kopi.ID.Hex(), Konten: kopi.Name + " - " + rupiah.Format(kopi.Price)})
+	}
+	if len(list) == 0 {
+		return "Mohon maaf kak, menu di kategori ini sedang tidak tersedia. Ketik *" + Keyword + "* untuk pilih kategori lain"
+	}
+	list = append(list, menu.MenuList{Keyword: Keyword, Konten: "⬅️ Kembali ke kategori"})
+	return numberedReply(Pesan.Phone_number, "Pilih menu yang ingin ditambahkan ke keranjang:", list, db)
+}
+
+// CariMenu mencari menu yang tersedia dengan menusearch, jika ada kata yang salah ketik ditawarkan koreksinya
+func CariMenu(Pesan itmodel.IteungMessage, args []string, db *mongo.Database) (reply string) {
+	query := strings.Join(args, " ")
+	if query == "" {
+		return "Mau cari menu apa kak? contoh: *" + Keyword + " cari kopi susu*"
+	}
+	results, suggestion, err := menusearch.Search(db, query, 0)
+	if err != nil {
+		return "Mohon maaf kak, menu gagal dicari: " + err.Error()
+	}
+	var list []menu.MenuList
+	for _, result := range results {
+		if !pesanan.IsMenuOrderable(result.Menu) {
+			continue
+		}
+		list = append(list, menu.MenuList{Keyword: Keyword + " tambah " + result.Menu.ID.Hex(), Konten: result.Menu.Name + " - " + rupiah.Format(result.Menu.Price)})
+		if len(list) == 5 {
+			break
+		}
+	}
+	header := "Hasil pencarian *" + query + "*:"
+	if suggestion != "" {
+		list = append(list, menu.MenuList{Keyword: Keyword + " cari " + suggestion, Konten: "🔍 Mungkin maksud kakak: " + suggestion})
+	}
+	if len(results) == 0 && suggestion == "" {
+		return "Menu *" + query + "* tidak ditemukan kak, ketik *" + Keyword + "* untuk melihat daftar menu"
+	}
+	if len(list) == 0 {
+		return "Mohon maaf kak, menu *" + query + "* sedang tidak tersedia. Ketik *" + Keyword + "* untuk pilih menu lain"
+	}
+	list = append(list, menu.MenuList{Keyword: Keyword, Konten: "⬅️ Kembali ke kategori"})
+	return numberedReply(Pesan.Phone_number, header, list, db)
+}
+
+// TambahMenu menambahkan menu ke keranjang, jika menu punya modifier maka opsi ditanyakan dulu
+func TambahMenu(Pesan itmodel.IteungMessage, args []string, db *mongo.Database) (reply string) {
+	menuID, err := objectIDArg(args)
+	if err != nil {
+		return "Menu tidak dikenali kak, ketik *" + Keyword + "* untuk melihat daftar menu"
+	}
+	quantity := 1
+	if len(args) > 1 {
+		quantity, err = strconv.Atoi(args[1])
+		if err != nil || quantity <= 0 {
+			return "Jumlah pesanan harus angka lebih dari 0 kak, contoh: *" + Keyword + " tambah " + menuID.Hex() + " 2*"
+		}
+	}
+	kopi, err := atdb.GetOneDoc[model.Menu](db, "menu", atdb.ExcludeDeleted(bson.M{"_id": menuID}))
+	if err != nil {
+		return "Menu tidak ditemukan kak, ketik *" + Keyword + "* untuk melihat daftar menu"
+	}
+	if !pesanan.IsMenuOrderable(kopi) {
+		return "Mohon maaf kak, *" + kopi.Name + "* sedang tidak tersedia"
+	}
+	cart, err := keranjang.Get(db, Pesan.Phone_number)
+	if err != nil {
+		return "Mohon maaf kak, keranjang gagal dibaca: " + err.Error()
+	}
+	cart.Pending = &keranjang.PendingItem{MenuID: kopi.ID, Quantity: quantity}
+	if len(kopi.Modifiers) == 0 {
+		return finishPending(Pesan, &cart, kopi, db)
+	}
+	if err = keranjang.Save(db, cart); err != nil {
+		return "Mohon maaf kak, keranjang gagal disimpan: " + err.Error()
+	}
+	return askModifier(Pesan, cart, kopi, db)
+}
+
+// PilihOpsi memilih satu opsi modifier untuk menu yang sedang ditambahkan
+func PilihOpsi(Pesan itmodel.IteungMessage, args []string, db *mongo.Database) (reply string) {
+	cart, err := keranjang.Get(db, Pesan.Phone_number)
+	if err != nil {
+		return "Mohon maaf kak, keranjang gagal dibaca: " + err.Error()
+	}
+	if cart.Pending == nil {
+		return "Tidak ada menu yang sedang dipilih opsinya kak, ketik *" + Keyword + "* untuk melihat daftar menu"
+	}
+	kopi, err := atdb.GetOneDoc[model.Menu](db, "menu", atdb.ExcludeDeleted(bson.M{"_id": cart.Pending.MenuID}))
+	if err != nil || cart.Pending.Group >= len(kopi.Modifiers) {
+		cart.Pending = nil
+		_ = keranjang.Save(db, cart)
+		return "Menu sudah berubah kak, silakan pilih ulang menunya dengan ketik *" + Keyword + "*"
+	}
+	if len(args) == 0 {
+		return askModifier(Pesan, cart, kopi, db)
+	}
+
+	group := kopi.Modifiers[cart.Pending.Group]
+	min, max := pesanan.ModifierLimits(group)
+	chosen := keranjang.ChosenOptions(cart.Pending.Modifiers, group.Name)
+	if args[0] == "-" {
+		if len(chosen) < min {
+			return "Pilihan *" + group.Name + "* wajib diisi minimal " + strconv.Itoa(min) + " kak\n\n" + askModifier(Pesan, cart, kopi, db)
+		}
+		return nextModifierGroup(Pesan, &cart, kopi, db)
+	}
+	no, err := strconv.Atoi(args[0])
+	if err != nil || no < 1 || no > len(group.Options) {
+		return "Nomor opsi tidak ada kak\n\n" + askModifier(Pesan, cart, kopi, db)
+	}
+	option := group.Options[no-1]
+	if chosen[option.Name] {
+		return "Opsi *" + option.Name + "* sudah dipilih kak\n\n" + askModifier(Pesan, cart, kopi, db)
+	}
+	cart.Pending.Modifiers = append(cart.Pending.Modifiers, model.SelectedModifier{Group: group.Name, Option: option.Name})
+	if group.Type == model.ModifierSingle || (max > 0 && len(chosen)+1 >= max) {
+		return nextModifierGroup(Pesan, &cart, kopi, db)
+	}
+	if err = keranjang.Save(db, cart); err != nil {
+		return "Mohon maaf kak, keranjang gagal disimpan: " + err.Error()
+	}
+	return askModifier(Pesan, cart, kopi, db)
+}
+
+// HapusItem menghapus satu item dari keranjang berdasarkan nomor urutnya
+func HapusItem(Pesan itmodel.IteungMessage, args []string, db *mongo.Database) (reply string) {
+	cart, err := keranjang.Get(db, Pesan.Phone_number)
+	if err != nil {
+		return "Mohon maaf kak, keranjang gagal dibaca: " + err.Error()
+	}
+	no := 0
+	if len(args) > 0 {
+		no, _ = strconv.Atoi(args[0])
+	}
+	removed, ok := keranjang.RemoveItem(&cart, no)
+	if !ok {
+		return "Nomor item tidak ada di keranjang kak\n\n" + cartReply(Pesan, cart, db)
+	}
+	if err = keranjang.Save(db, cart); err != nil {
+		return "Mohon maaf kak, keranjang gagal disimpan: " + err.Error()
+	}
+	return "🗑️ *" + removed.MenuName + "* dihapus dari keranjang\n\n" + cartReply(Pesan, cart, db)
+}
+
+// LihatKeranjang menampilkan isi keranjang
+func LihatKeranjang(Pesan itmodel.IteungMessage, db *mongo.Database) (reply string) {
+	cart, err := keranjang.Get(db, Pesan.Phone_number)
+	if err != nil {
+		return "Mohon maaf kak, keranjang gagal dibaca: " + err.Error()
+	}
+	return cartReply(Pesan, cart, db)
+}
+
+// Checkout menghitung ulang harga keranjang dari data menu dan meminta konfirmasi pelanggan
+func Checkout(Pesan itmodel.IteungMessage, db *mongo.Database) (reply string) {
+	cart, err := keranjang.Get(db, Pesan.Phone_number)
+	if err != nil {
+		return "Mohon maaf kak, keranjang gagal dibaca: " + err.Error()
+	}
+	if len(cart.Items) == 0 {
+		return cartReply(Pesan, cart, db)
+	}
+	priced, total, _, err := pesanan.PriceOrderItems(db, cart.Items)
+	if errors.Is(err, pesanan.ErrOrderItemInvalid) {
+		return "Mohon maaf kak, " + err.Error() + "\nSilakan ubah keranjang dulu\n\n" + cartReply(Pesan, cart, db)
+	} else if err != nil {
+		return "Mohon maaf kak, harga menu gagal diambil: " + err.Error()
+	}
+	// simpan harga terbaru supaya tampilan keranjang sama dengan total yang dikonfirmasi
+	cart.Items = priced
+	if err = keranjang.Save(db, cart); err != nil {
+		return "Mohon maaf kak, keranjang gagal disimpan: " + err.Error()
+	}
+
+	header := "🧾 *Konfirmasi Pesanan*\n" + keranjang.ItemLines(priced) + "\n*Total: " + rupiah.Format(total) + "*\nPembayaran: Cash di kasir\n\nPesanan sudah benar kak?"
+	list := []menu.MenuList{
+		{Keyword: Keyword + " konfirmasi", Konten: "✅ Ya, buat pesanan"},
+		{Keyword: Keyword + " keranjang", Konten: "✏️ Ubah keranjang"},
+		{Keyword: Keyword + " batal", Konten: "❌ Batalkan"},
+	}
+	return numberedReply(Pesan.Phone_number, header, list, db)
+}
+
+// Konfirmasi membuat order dari isi keranjang lalu mengosongkan keranjang
+func Konfirmasi(Pesan itmodel.IteungMessage, db *mongo.Database) (reply string) {
+	cart, err := keranjang.Get(db, Pesan.Phone_number)
+	if err != nil {
+		return "Mohon maaf kak, keranjang gagal dibaca: " + err.Error()
+	}
+	if len(cart.Items) == 0 {
+		return cartReply(Pesan, cart, db)
+	}
+	order, err := CreateOrder(Pesan, cart.Items, db)
+	if errors.Is(err, pesanan.ErrOrderItemInvalid) {
+		return "Mohon maaf kak, " + err.Error() + "\nSilakan ubah keranjang dulu\n\n" + cartReply(Pesan, cart, db)
+	} else if err != nil {
+		return "Mohon maaf kak, pesanan gagal dibuat: " + err.Error()
+	}
+	_ = keranjang.Clear(db, Pesan.Phone_number)
+
+	reply = "✅ *Pesanan diterima*\n"
+	reply += "Nomor pesanan: *" + order.OrderNumber + "*\n"
+	reply += "Nomor antrean: *" + strconv.Itoa(order.QueueNumber) + "*\n"
+	reply += keranjang.ItemLines(order.Orders)
+	reply += "\n*Total: " + rupiah.Format(order.Total) + "*\nPembayaran: Cash di kasir\n\n"
+	reply += "Kami akan mengabari lewat WhatsApp ini setiap status pesanan berubah. Ketik *" + Keyword + " status* untuk cek status pesanan."
+	return
+}
+
+// BatalPesan mengosongkan keranjang
+func BatalPesan(Pesan itmodel.IteungMessage, db *mongo.Database) (reply string) {
+	if err := keranjang.Clear(db, Pesan.Phone_number); err != nil {
+		return "Mohon maaf kak, keranjang gagal dikosongkan: " + err.Error()
+	}
+	return "Keranjang sudah dikosongkan kak. Ketik *" + Keyword + "* kalau mau pesan lagi ☕"
+}
+
+// StatusPesanan menampilkan pesanan pelanggan yang belum selesai
+func StatusPesanan(Pesan itmodel.IteungMessage, db *mongo.Database) (reply string) {
+	filter := bson.M{
+		"user_info.whatsapp": bson.M{"$in": phoneVariants(Pesan.Phone_number)},
+		"status":             bson.M{"$nin": []string{model.OrderStatusSelesai, model.OrderStatusDibatalkan, model.OrderStatusDitolak}},
+	}
+	orders, err := atdb.AggregateDocs[model.Order](db, "orders", mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.M{"orderDate": -1}}},
+		{{Key: "$limit", Value: 5}},
+	})
+	if err != nil {
+		return "Mohon maaf kak, status pesanan gagal diambil: " + err.Error()
+	}
+	if len(orders) == 0 {
+		return "Tidak ada pesanan yang sedang berjalan kak. Ketik *" + Keyword + "* untuk pesan kopi ☕"
+	}
+	reply = "📋 *Pesanan kakak yang sedang berjalan*\n"
+	for _, order := range orders {
+		reply += "\n*" + order.OrderNumber + "* (antrean " + strconv.Itoa(order.QueueNumber) + ")\n"
+		reply += "Status: " + order.Status + "\nPembayaran: " + order.PaymentStatus + "\nTotal: " + rupiah.Format(order.Total) + "\n"
+	}
+	return
+}
+
+// CreateOrder membuat order Cash dari keranjang WhatsApp lewat checkout.Submit yang sama dengan order dari web,
+// sehingga harga, stasiun dapur, nomor antrean dan notifikasi status ikut diproses. Nomor pengirim pesan sudah
+// terverifikasi oleh WhatsApp, jadi pesanan ini mendapat poin loyalitas.
+func CreateOrder(Pesan itmodel.IteungMessage, items []model.OrderItem, db *mongo.Database) (order model.Order, err error) {
+	name := Pesan.Alias_name
+	if name == "" {
+		name = Pesan.Phone_number
+	}
+	user := model.Userdomyikado{Name: name, Role: "whatsapp"}
+	// hubungkan ke akun web jika nomor WhatsApp sudah terdaftar
+	if account, errUser := atdb.GetOneDoc[model.Userdomyikado](db, "user", bson.M{"phonenumber": Pesan.Phone_number}); errUser == nil {
+		user.ID = account.ID
+	}
+	result, err := checkout.Submit(db, nil, checkout.Input{
+		Order: model.Order{
+			UserInfo:      model.UserInfo{Name: name, Whatsapp: Pesan.Phone_number},
+			Orders:        items,
+			PaymentMethod: "Cash",
+		},
+		User:     user,
+		Customer: phone.NormalizePhoneNumber(Pesan.Phone_number),
+		Actor:    audit.Actor{ID: user.ID, Name: user.Name, Role: user.Role},
+	})
+	// balasan chat cukup berisi penyebabnya, tanpa status HTTP
+	var failed *checkout.Error
+	if errors.As(err, &failed) {
+		err = failed.Err
+	}
+	return result.Order, err
+}
+
+// askModifier menanyakan grup modifier yang sedang aktif, opsi yang sudah dipilih tidak ditampilkan lagi
+func askModifier(Pesan itmodel.IteungMessage, cart keranjang.Cart, kopi model.Menu, db *mongo.Database) string {
+	group := kopi.Modifiers[cart.Pending.Group]
+	min, max := pesanan.ModifierLimits(group)
+	chosen := keranjang.ChosenOptions(cart.Pending.Modifiers, group.Name)
+
+	header := "Pilih *" + group.Name + "* untuk *" + kopi.Name + "*"
+	switch {
+	case group.Type == model.ModifierSingle && min > 0:
+		header += " (wajib pilih 1)"
+	case group.Type == model.ModifierSingle:
+		header += " (boleh dilewati)"
+	case min == 0 && max > 0:
+		header += " (maksimal " + strconv.Itoa(max) + ", boleh dilewati)"
+	case min == 0:
+		header += " (boleh lebih dari satu, boleh dilewati)"
+	case max > 0:
+		header += " (pilih " + strconv.Itoa(min) + " sampai " + strconv.Itoa(max) + ")"
+	default:
+		header += " (minimal " + strconv.Itoa(min) + ")"
+	}
+	var list []menu.MenuList
+	for i, option := range group.Options {
+		if chosen[option.Name] {
+			continue
+		}
+		konten := option.Name
+		if option.PriceDelta != 0 {
+			konten += " (+" + rupiah.Format(option.PriceDelta) + ")"
+		}
+		list = append(list, menu.MenuList{Keyword: Keyword + " opsi " + strconv.Itoa(i+1), Konten: konten})
+	}
+	if len(chosen) >= min {
+		konten := "Lewati"
+		if len(chosen) > 0 {
+			konten = "Selesai memilih " + group.Name
+		}
+		list = append(list, menu.MenuList{Keyword: Keyword + " opsi -", Konten: konten})
+	}
+	return numberedReply(Pesan.Phone_number, header, list, db)
+}
+
+// nextModifierGroup pindah ke grup modifier berikutnya atau memasukkan menu ke keranjang jika semua grup sudah ditanyakan
+func nextModifierGroup(Pesan itmodel.IteungMessage, cart *keranjang.Cart, kopi model.Menu, db *mongo.Database) string {
+	cart.Pending.Group++
+	if cart.Pending.Group < len(kopi.Modifiers) {
+		if err := keranjang.Save(db, *cart); err != nil {
+			return "Mohon maaf kak, keranjang gagal disimpan: " + err.Error()
+		}
+		return askModifier(Pesan, *cart, kopi, db)
+	}
+	return finishPending(Pesan, cart, kopi, db)
+}
+
+// finishPending memvalidasi opsi yang dipilih lalu memasukkan menu ke keranjang
+func finishPending(Pesan itmodel.IteungMessage, cart *keranjang.Cart, kopi model.Menu, db *mongo.Database) string {
+	pending := cart.Pending
+	cart.Pending = nil
+	modifiers, delta, err := pesanan.ApplyModifiers(kopi, pending.Modifiers)
+	if err != nil {
+		_ = keranjang.Save(db, *cart)
+		return "Mohon maaf kak, " + err.Error() + "\nSilakan pilih ulang menunya dengan ketik *" + Keyword + "*"
+	}
+	keranjang.AddItem(cart, model.OrderItem{
+		MenuID:    kopi.ID,
+		MenuName:  kopi.Name,
+		Price:     kopi.Price + delta,
+		Quantity:  pending.Quantity,
+		Modifiers: modifiers,
+	})
+	if err = keranjang.Save(db, *cart); err != nil {
+		return "Mohon maaf kak, keranjang gagal disimpan: " + err.Error()
+	}
+	return "✅ " + strconv.Itoa(pending.Quantity) + "x *" + kopi.Name + "* masuk keranjang\n\n" + cartReply(Pesan, *cart, db)
+}
+
+// cartReply isi keranjang beserta pilihan checkout, tambah menu dan hapus item
+func cartReply(Pesan itmodel.IteungMessage, cart keranjang.Cart, db *mongo.Database) string {
+	if len(cart.Items) == 0 {
+		return numberedReply(Pesan.Phone_number, "🛒 Keranjang kakak masih kosong", []menu.MenuList{
+			{Keyword: Keyword, Konten: "☕ Lihat menu"},
+		}, db)
+	}
+	header := "🛒 *Keranjang*\n" + keranjang.ItemLines(cart.Items) + "\n*Total: " + rupiah.Format(keranjang.Total(cart.Items)) + "*"
+	list := []menu.MenuList{
+		{Keyword: Keyword + " checkout", Konten: "✅ Checkout"},
+		{Keyword: Keyword, Konten: "➕ Tambah menu"},
+	}
+	for i, item := range cart.Items {
+		list = append(list, menu.MenuList{Keyword: Keyword + " hapus " + strconv.Itoa(i+1), Konten: "🗑️ Hapus " + item.MenuName})
+	}
+	list = append(list, menu.MenuList{Keyword: Keyword + " batal", Konten: "❌ Kosongkan keranjang"})
+	return numberedReply(Pesan.Phone_number, header, list, db)
+}
+
+// numberedReply menyusun pesan bernomor dan menyimpan daftarnya ke session menu supaya bisa dibalas dengan nomor
+func numberedReply(phonenumber, header string, list []menu.MenuList, db *mongo.Database) string {
+	msg := header + "\n"
+	for i := range list {
+		list[i].No = i + 1
+		msg += "\n" + strconv.Itoa(list[i].No) + ". " + list[i].Konten
+	}
+	if err := menu.InjectSessionMenu(list, phonenumber, db); err != nil {
+		return msg
+	}
+	return msg + "\n\nBalas dengan nomor pilihan kak"
+}
+
+// commandArgs mengambil kata-kata setelah keyword pesankopi
+func commandArgs(msg string) []string {
+	fields := strings.Fields(strings.ToLower(msg))
+	for i, field := range fields {
+		if field == Keyword {
+			return fields[i+1:]
+		}
+	}
+	return nil
+}
+
+func objectIDArg(args []string) (id primitive.ObjectID, err error) {
+	if len(args) == 0 {
+		return id, errors.New("id kosong")
+	}
+	return primitive.ObjectIDFromHex(args[0])
+}
+
+// phoneVariants nomor WhatsApp order dari web bisa tersimpan dengan format 08xx, +628xx atau 628xx
+func phoneVariants(phonenumber string) []string {
+	normalized := phone.NormalizePhoneNumber(phonenumber)
+	variants := []string{phonenumber, normalized, "+" + normalized}
+	if strings.HasPrefix(normalized, "62") {
+		variants = append(variants, "0"+normalized[2:])
+	}
+	return variants
+}
@@ -7,27 +7,140 @@ import (
 )
 
 type Category struct {
-	ID    primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name  string             `json:"name,omitempty" bson:"name,omitempty"`
-	Image string             `json:"image,omitempty" bson:"image,omitempty"`
+	ID            primitive.ObjectID      `json:"id" bson:"_id,omitempty"`
+	Name          string                  `json:"name,omitempty" bson:"name,omitempty"`
+	Image         string                  `json:"image,omitempty" bson:"image,omitempty"`
+	ImageKey      string                  `json:"image_key,omitempty" bson:"image_key,omitempty"`
+	ImageVariants map[string]ImageVariant `json:"image_variants,omitempty" bson:"image_variants,omitempty"`
+	Station       string                  `json:"station,omitempty" bson:"station,omitempty"`       // Stasiun yang menyiapkan menu kategori ini, kosong berarti bar
+	DeletedAt     time.Time               `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"` // Diisi saat soft delete, dokumen tidak tampil di daftar
+	DeletedBy     string                  `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
 
+// Stasiun persiapan pesanan (kitchen display), dipetakan dari kategori menu
+const (
+	StationBar     = "bar"
+	StationKitchen = "kitchen"
+	StationPastry  = "pastry"
+)
+
 type Banner struct {
-	ID    primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	Name  string             `json:"name,omitempty" bson:"name,omitempty"`
-	Image string             `json:"image,omitempty" bson:"image,omitempty"`
+	ID            primitive.ObjectID      `json:"id,omitempty" bson:"_id,omitempty"`
+	Name          string                  `json:"name,omitempty" bson:"name,omitempty"`
+	Image         string                  `json:"image,omitempty" bson:"image,omitempty"`
+	ImageKey      string                  `json:"image_key,omitempty" bson:"image_key,omitempty"`
+	ImageVariants map[string]ImageVariant `json:"image_variants,omitempty" bson:"image_variants,omitempty"`
+	DeletedAt     time.Time               `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"` // Diisi saat soft delete, dokumen tidak tampil di daftar
+	DeletedBy     string                  `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+}
+
+// ImageVariant gambar turunan (thumb, card, banner). Srcset berisi ukuran 1x dan 2x JPEG (PNG jika transparan)
+// dalam format atribut srcset, misalnya "https://.../abc_card.jpg 480w, https://.../abc_card@2x.jpg 960w".
+// WebPSrcset hanya diisi jika semua ukuran WebP lebih kecil dari JPEG-nya, untuk <source type="image/webp"> di <picture>.
+type ImageVariant struct {
+	URL        string   `json:"url" bson:"url"`
+	Srcset     string   `json:"srcset" bson:"srcset"`
+	WebPSrcset string   `json:"webp_srcset,omitempty" bson:"webp_srcset,omitempty"`
+	Width      int      `json:"width" bson:"width"`
+	Height     int      `json:"height" bson:"height"`
+	Keys       []string `json:"-" bson:"keys"` // Key semua file variant di storage, untuk dihapus saat gambar diganti
 }
 
 type Menu struct {
-	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	CategoryID  primitive.ObjectID `json:"category_id,omitempty" bson:"category_id,omitempty"`
-	Name        string             `json:"name,omitempty" bson:"name,omitempty"`
-	Description string             `json:"description,omitempty" bson:"description,omitempty"`
-	Image       string             `json:"image,omitempty" bson:"image,omitempty"`
-	Price       float64            `json:"price,omitempty" bson:"price,omitempty"`
-	Status      string             `json:"status,omitempty" bson:"status,omitempty"`
+	ID            primitive.ObjectID      `json:"id,omitempty" bson:"_id,omitempty"`
+	CategoryID    primitive.ObjectID      `json:"category_id,omitempty" bson:"category_id,omitempty"`
+	Name          string                  `json:"name,omitempty" bson:"name,omitempty"`
+	Description   string                  `json:"description,omitempty" bson:"description,omitempty"`
+	Image         string                  `json:"image,omitempty" bson:"image,omitempty"`
+	ImageKey      string                  `json:"image_key,omitempty" bson:"image_key,omitempty"` // Key file gambar di storage, untuk menghapus gambar lama saat diganti
+	ImageVariants map[string]ImageVariant `json:"image_variants,omitempty" bson:"image_variants,omitempty"`
+	Price         float64                 `json:"price,omitempty" bson:"price,omitempty"`
+	Status        string                  `json:"status,omitempty" bson:"status,omitempty"`
+	OutOfStock    bool                    `json:"out_of_stock,omitempty" bson:"out_of_stock,omitempty"` // Diatur otomatis dari stok bahan resep, terpisah dari Status yang diatur admin
+	Recipe        []RecipeItem            `json:"recipe,omitempty" bson:"recipe,omitempty"`             // Bahan yang dipakai untuk satu porsi
+	Modifiers     []ModifierGroup         `json:"modifiers,omitempty" bson:"modifiers,omitempty"`       // Pilihan tambahan: ukuran, gula, es, extra shot
+	PointsPrice   int                     `json:"points_price,omitempty" bson:"points_price,omitempty"` // Poin untuk menukar satu porsi gratis, 0 jika tidak bisa ditukar
+	DeletedAt     time.Time               `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"` // Diisi saat soft delete, dokumen tidak tampil di daftar
+	DeletedBy     string                  `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+}
+
+// Jenis grup modifier
+const (
+	ModifierSingle = "single" // hanya boleh pilih satu opsi, misalnya ukuran
+	ModifierMulti  = "multi"  // boleh pilih beberapa opsi, misalnya topping
+)
+
+// ModifierGroup struct untuk satu grup pilihan pada menu, misalnya "Ukuran" atau "Tambahan"
+type ModifierGroup struct {
+	Name     string           `json:"name" bson:"name"`
+	Type     string           `json:"type" bson:"type"`         // single atau multi
+	Required bool             `json:"required" bson:"required"` // wajib memilih minimal satu opsi
+	Min      int              `json:"min,omitempty" bson:"min,omitempty"`
+	Max      int              `json:"max,omitempty" bson:"max,omitempty"` // 0 berarti tidak dibatasi (untuk multi)
+	Options  []ModifierOption `json:"options" bson:"options"`
+}
+
+// ModifierOption struct untuk satu opsi dalam grup beserta selisih harganya
+type ModifierOption struct {
+	Name       string  `json:"name" bson:"name"`
+	PriceDelta float64 `json:"price_delta" bson:"price_delta"` // Tambahan harga per porsi, boleh 0
+}
+
+// SelectedModifier struct untuk opsi yang dipilih pada item pesanan
+type SelectedModifier struct {
+	Group      string  `json:"group" bson:"group"`
+	Option     string  `json:"option" bson:"option"`
+	PriceDelta float64 `json:"price_delta" bson:"price_delta"` // Diisi server dari data menu
+}
+
+// RecipeItem takaran satu bahan untuk satu porsi menu, misalnya 18 gram biji kopi
+type RecipeItem struct {
+	IngredientID primitive.ObjectID `json:"ingredient_id" bson:"ingredient_id"`
+	Quantity     float64            `json:"quantity" bson:"quantity"` // Dalam satuan bahan (Ingredient.Unit)
+}
+
+// Ingredient struct untuk bahan baku beserta stoknya
+type Ingredient struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Unit      string             `json:"unit" bson:"unit"`           // Satuan stok: gram, ml, pcs
+	Stock     float64            `json:"stock" bson:"stock"`         // Stok saat ini
+	MinStock  float64            `json:"min_stock" bson:"min_stock"` // Batas stok menipis untuk peringatan
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// StockMovement struct untuk mencatat setiap perubahan stok bahan beserta alasannya
+type StockMovement struct {
+	ID             primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	IngredientID   primitive.ObjectID `json:"ingredient_id" bson:"ingredient_id"`
+	IngredientName string             `json:"ingredient_name" bson:"ingredient_name"`
+	Change         float64            `json:"change" bson:"change"`           // Positif untuk penambahan, negatif untuk pemakaian
+	StockAfter     float64            `json:"stock_after" bson:"stock_after"` // Stok setelah perubahan
+	Reason         string             `json:"reason" bson:"reason"`
+	OrderID        primitive.ObjectID `json:"order_id,omitempty" bson:"order_id,omitempty"` // Diisi jika perubahan karena pesanan
+	By             string             `json:"by" bson:"by"`
+	At             time.Time          `json:"at" bson:"at"`
 }
 
+// Daftar status pesanan
+const (
+	OrderStatusTerkirim    = "terkirim"
+	OrderStatusDiproses    = "diproses"
+	OrderStatusSiapDiambil = "siap diambil"
+	OrderStatusSelesai     = "selesai"
+	OrderStatusDibatalkan  = "dibatalkan"
+	OrderStatusDitolak     = "ditolak"
+)
+
+// Daftar status pembayaran, terpisah dari status pesanan
+const (
+	PaymentStatusBelumDibayar = "belum dibayar"       // Cash, dibayar di kasir
+	PaymentStatusMenunggu     = "menunggu pembayaran" // QRIS sudah dibuat, belum dibayar
+	PaymentStatusLunas        = "lunas"
+	PaymentStatusKedaluwarsa  = "kedaluwarsa"
+	PaymentStatusPerluRefund  = "perlu refund" // dibayar setelah pesanan dibatalkan/ditolak, uang harus dikembalikan kasir
+)
+
 // Order struct untuk menyimpan informasi pesanan
 type Order struct {
 	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`                        // ID unik pesanan
@@ -39,13 +152,176 @@ type Order struct {
 	Orders        []OrderItem        `json:"orders,omitempty" bson:"orders,omitempty"`                 // Daftar item pesanan
 	Total         float64            `json:"total,omitempty" bson:"total,omitempty"`                   // Total harga pesanan (harga satuan * kuantitas per item)
 	PaymentMethod string             `json:"payment_method,omitempty" bson:"payment_method,omitempty"` // Metode pembayaran (Cash/QRIS)
-	// PaymentInfo       string             `json:"payment_info,omitempty" bson:"payment_info,omitempty"`
+	PaymentStatus string             `json:"payment_status,omitempty" bson:"payment_status,omitempty"` // Status pembayaran, terpisah dari status pesanan
+	PaymentInfo   *PaymentInfo       `json:"payment_info,omitempty" bson:"payment_info,omitempty"`     // Detail tagihan dari payment provider (QRIS)
 	Status        string    `json:"status,omitempty" bson:"status,omitempty"`
 	CreatedBy     string    `json:"created_by,omitempty" bson:"created_by,omitempty"`           // Nama siapa yang membuat pesanan (user atau kasir)
 	CreatedByRole string    `json:"created_by_role,omitempty" bson:"created_by_role,omitempty"` // Role siapa yang membuat pesanan
 	UpdatedBy     string    `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
 	UpdatedByRole string    `bson:"updated_by_role,omitempty" json:"updated_by_role,omitempty"`
 	UpdatedAt     time.Time `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+	StatusHistory []OrderStatusHistory `bson:"status_history,omitempty" json:"status_history,omitempty"` // Riwayat perubahan status pesanan
+	VoucherCode   string               `bson:"voucher_code,omitempty" json:"voucher_code,omitempty"` // Kode voucher dari client
+	Subtotal      float64              `bson:"subtotal,omitempty" json:"subtotal,omitempty"`         // Total sebelum potongan voucher
+	Discount      *OrderDiscount       `bson:"discount,omitempty" json:"discount,omitempty"`         // Rincian potongan voucher
+	RedeemPoints  int                  `bson:"-" json:"redeem_points,omitempty"`                      // Input: jumlah poin yang ingin ditukar sebagai potongan
+	RedeemMenuID  primitive.ObjectID   `bson:"-" json:"redeem_menu_id,omitempty"`                     // Input: menu di keranjang yang ingin digratiskan dengan poin
+	Points        *OrderPoints         `bson:"points,omitempty" json:"points,omitempty"`             // Rincian penukaran poin loyalitas
+	Customer      string               `bson:"customer,omitempty" json:"customer,omitempty"`         // Nomor WhatsApp terverifikasi lewat login WhatsAuth, penerima poin loyalitas
+	StockDeducted bool                 `bson:"stock_deducted,omitempty" json:"stock_deducted,omitempty"` // Stok bahan sudah dikurangi saat pesanan diproses
+	LegacyID      primitive.ObjectID   `bson:"legacy_id,omitempty" json:"legacy_id,omitempty"`           // ID dokumen koleksi order lama (jualin) untuk order hasil migrasi
+	TableID       primitive.ObjectID   `bson:"table_id,omitempty" json:"table_id,omitempty"`             // Meja asal pesanan dine-in (QR meja)
+	TableNumber   string               `bson:"table_number,omitempty" json:"table_number,omitempty"`     // Nomor meja saat pesanan dibuat
+	SettledAt     time.Time            `bson:"settled_at,omitempty" json:"settled_at,omitempty"`         // Waktu meja ditutup dan pesanan diselesaikan kasir
+	SettledBy     string               `bson:"settled_by,omitempty" json:"settled_by,omitempty"`         // Kasir yang menutup meja
+	PickupAt      time.Time            `bson:"pickup_at,omitempty" json:"pickup_at,omitempty"`           // Input dan hasil: awal slot waktu ambil untuk pesanan terjadwal
+	QueueAt       time.Time            `bson:"queue_at,omitempty" json:"queue_at,omitempty"`             // Pesanan terjadwal baru masuk antrean aktif mulai waktu ini
+	PickupDrinks  int                  `bson:"pickup_drinks,omitempty" json:"pickup_drinks,omitempty"`   // Kuota minuman slot yang dipakai, dikembalikan jika pesanan batal
+	Type          string               `bson:"type,omitempty" json:"type,omitempty"`                     // Jenis pesanan, kosong untuk ambil di toko atau dine-in, OrderTypeDelivery untuk diantar
+	Delivery      *OrderDelivery       `bson:"delivery,omitempty" json:"delivery,omitempty"`             // Input: alamat dan koordinat; hasil: zona, jarak, ongkir dan wilayah
+}
+
+// Jenis pesanan
+const (
+	OrderTypeDelivery = "delivery" // diantar ke alamat pelanggan
+)
+
+// OrderDelivery alamat antar pesanan delivery. Address, Note dan koordinat diisi pelanggan,
+// sisanya dihitung server saat pesanan dibuat dan tidak berubah walaupun zona atau tarif diubah kemudian.
+type OrderDelivery struct {
+	Address     string             `json:"address" bson:"address"`
+	Note        string             `json:"note,omitempty" bson:"note,omitempty"` // Patokan untuk kurir, misalnya pagar hitam
+	Longitude   float64            `json:"long" bson:"long"`
+	Latitude    float64            `json:"lat" bson:"lat"`
+	ZoneID      primitive.ObjectID `json:"zone_id,omitempty" bson:"zone_id,omitempty"`
+	ZoneName    string             `json:"zone_name,omitempty" bson:"zone_name,omitempty"`
+	DistanceKm  float64            `json:"distance_km" bson:"distance_km"` // Jarak garis lurus dari toko
+	Fee         float64            `json:"fee" bson:"fee"`                 // Ongkir, ditambahkan ke Total setelah potongan voucher dan poin
+	Province    string             `json:"province,omitempty" bson:"province,omitempty"`
+	District    string             `json:"district,omitempty" bson:"district,omitempty"`
+	SubDistrict string             `json:"sub_district,omitempty" bson:"sub_district,omitempty"`
+	Village     string             `json:"village,omitempty" bson:"village,omitempty"`
+}
+
+// PaymentInfo struct untuk menyimpan tagihan (payment intent) dari payment provider
+type PaymentInfo struct {
+	Provider  string    `json:"provider" bson:"provider"`
+	Reference string    `json:"reference" bson:"reference"`
+	Amount    float64   `json:"amount" bson:"amount"`
+	QRString  string    `json:"qr_string,omitempty" bson:"qr_string,omitempty"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	PaidAt    time.Time `json:"paid_at,omitempty" bson:"paid_at,omitempty"`
+}
+
+// Jenis voucher
+const (
+	VoucherPercentage = "percentage" // potongan persen dari item yang berlaku
+	VoucherFixed      = "fixed"      // potongan nominal
+	VoucherBuyXGetY   = "bxgy"       // beli X gratis Y, item termurah yang digratiskan
+)
+
+// Voucher struct untuk promo yang bisa dipakai saat checkout dengan kode voucher
+type Voucher struct {
+	ID           primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
+	Code         string               `json:"code" bson:"code"` // Kode voucher, selalu huruf besar
+	Name         string               `json:"name" bson:"name"`
+	Description  string               `json:"description,omitempty" bson:"description,omitempty"`
+	Type         string               `json:"type" bson:"type"`                 // percentage, fixed atau bxgy
+	Value        float64              `json:"value" bson:"value"`               // Persen (percentage) atau nominal rupiah (fixed)
+	MaxDiscount  float64              `json:"max_discount" bson:"max_discount"` // Batas potongan, 0 berarti tidak dibatasi
+	MinSpend     float64              `json:"min_spend" bson:"min_spend"`       // Minimal subtotal pesanan
+	BuyQuantity  int                  `json:"buy_quantity,omitempty" bson:"buy_quantity,omitempty"`
+	GetQuantity  int                  `json:"get_quantity,omitempty" bson:"get_quantity,omitempty"`
+	MenuIDs      []primitive.ObjectID `json:"menu_ids,omitempty" bson:"menu_ids,omitempty"`         // Menu yang berlaku, kosong berarti semua
+	CategoryIDs  []primitive.ObjectID `json:"category_ids,omitempty" bson:"category_ids,omitempty"` // Kategori yang berlaku
+	StartAt      time.Time            `json:"start_at" bson:"start_at"`
+	EndAt        time.Time            `json:"end_at" bson:"end_at"`
+	UsageLimit   int                  `json:"usage_limit" bson:"usage_limit"`       // Batas total pemakaian, 0 berarti tidak dibatasi
+	PerUserLimit int                  `json:"per_user_limit" bson:"per_user_limit"` // Batas pemakaian per pelanggan (nomor WhatsApp)
+	UsedCount    int                  `json:"used_count" bson:"used_count"`
+	Active       bool                 `json:"active" bson:"active"`
+	CreatedAt    time.Time            `json:"created_at" bson:"created_at"`
+	DeletedAt    time.Time            `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"` // Diisi saat soft delete, voucher tidak bisa dipakai lagi
+	DeletedBy    string               `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+}
+
+// OrderDiscount struct untuk rincian potongan voucher yang disimpan pada order
+type OrderDiscount struct {
+	VoucherID primitive.ObjectID `json:"voucher_id" bson:"voucher_id"`
+	Code      string             `json:"code" bson:"code"`
+	Type      string             `json:"type" bson:"type"`
+	Amount    float64            `json:"amount" bson:"amount"`                         // Total potongan
+	Items     []DiscountLine     `json:"items" bson:"items"`                           // Potongan per item pesanan
+	Customer  string             `json:"customer,omitempty" bson:"customer,omitempty"` // Nomor WhatsApp terverifikasi pemakai kuota per pelanggan
+	Released  bool               `json:"released,omitempty" bson:"released,omitempty"` // Kuota sudah dikembalikan karena pesanan dibatalkan/ditolak
+}
+
+// DiscountLine struct untuk potongan pada satu item pesanan
+type DiscountLine struct {
+	MenuID       primitive.ObjectID `json:"menu_id" bson:"menu_id"`
+	MenuName     string             `json:"menu_name" bson:"menu_name"`
+	FreeQuantity int                `json:"free_quantity,omitempty" bson:"free_quantity,omitempty"` // Jumlah item gratis (bxgy)
+	Amount       float64            `json:"amount" bson:"amount"`
+}
+
+// VoucherRedemption struct untuk mencatat setiap pemakaian voucher
+type VoucherRedemption struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	VoucherID   primitive.ObjectID `json:"voucher_id" bson:"voucher_id"`
+	Code        string             `json:"code" bson:"code"`
+	OrderID     primitive.ObjectID `json:"order_id" bson:"order_id"`
+	OrderNumber string             `json:"order_number" bson:"order_number"`
+	Customer    string             `json:"customer" bson:"customer"` // Nomor WhatsApp terverifikasi pelanggan (format 628xx), kosong untuk pesanan tanpa login
+	Discount    float64            `json:"discount" bson:"discount"`
+	At          time.Time          `json:"at" bson:"at"`
+	Cancelled   bool               `json:"cancelled" bson:"cancelled"` // true jika pesanan dibatalkan/ditolak dan kuota dikembalikan
+}
+
+// Jenis entri ledger poin loyalitas
+const (
+	PointsEarn    = "earn"    // poin didapat dari pesanan selesai
+	PointsBurn    = "burn"    // poin ditukar saat checkout
+	PointsReverse = "reverse" // pembalikan poin pesanan yang dibatalkan/ditolak
+)
+
+// LoyaltyAccount struct untuk saldo poin pelanggan, _id adalah nomor WhatsApp format 628xx
+type LoyaltyAccount struct {
+	Phone     string    `json:"phone" bson:"_id"`
+	Balance   int       `json:"balance" bson:"balance"`   // Poin yang bisa ditukar
+	Lifetime  int       `json:"lifetime" bson:"lifetime"` // Total poin yang pernah didapat, menentukan tier
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// PointsLedger struct untuk setiap perubahan poin, hanya ditambah (append-only) tidak pernah diubah
+type PointsLedger struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Phone        string             `json:"phone" bson:"phone"`
+	Type         string             `json:"type" bson:"type"`     // earn, burn atau reverse
+	Points       int                `json:"points" bson:"points"` // Positif untuk penambahan, negatif untuk pengurangan
+	BalanceAfter int                `json:"balance_after" bson:"balance_after"`
+	OrderID      primitive.ObjectID `json:"order_id,omitempty" bson:"order_id,omitempty"`
+	OrderNumber  string             `json:"order_number,omitempty" bson:"order_number,omitempty"`
+	Tier         string             `json:"tier,omitempty" bson:"tier,omitempty"`
+	Multiplier   float64            `json:"multiplier,omitempty" bson:"multiplier,omitempty"`
+	Description  string             `json:"description" bson:"description"`
+	At           time.Time          `json:"at" bson:"at"`
+}
+
+// OrderPoints struct untuk ringkasan poin pada order
+type OrderPoints struct {
+	Redeemed     int                `json:"redeemed,omitempty" bson:"redeemed,omitempty"`             // Poin yang ditukar
+	Discount     float64            `json:"discount,omitempty" bson:"discount,omitempty"`             // Potongan dari penukaran poin
+	RewardMenuID primitive.ObjectID `json:"reward_menu_id,omitempty" bson:"reward_menu_id,omitempty"` // Menu gratis yang ditukar dengan poin
+}
+
+// OrderStatusHistory struct untuk mencatat setiap perpindahan status pesanan
+type OrderStatusHistory struct {
+	From   string    `json:"from,omitempty" bson:"from,omitempty"` // Status sebelumnya (kosong saat order dibuat)
+	To     string    `json:"to" bson:"to"`                         // Status baru
+	By     string    `json:"by,omitempty" bson:"by,omitempty"`     // Nama yang mengubah status
+	Role   string    `json:"role,omitempty" bson:"role,omitempty"` // Role yang mengubah status
+	At     time.Time `json:"at" bson:"at"`
+	Reason string    `json:"reason,omitempty" bson:"reason,omitempty"` // Alasan pembatalan/penolakan
 }
 
 // UserInfo struct untuk menyimpan informasi pengguna
@@ -57,9 +333,130 @@ type UserInfo struct {
 
 // OrderItem struct untuk menyimpan detail setiap item dalam pesanan
 type OrderItem struct {
-	MenuID   primitive.ObjectID `json:"menu_id,omitempty" bson:"menu_id,omitempty"`
-	MenuName string             `json:"menu_name,omitempty" bson:"menu_name,omitempty"`
-	Price    float64            `json:"price,omitempty" bson:"price,omitempty"`
-	Quantity int                `json:"quantity,omitempty" bson:"quantity,omitempty"` // Kuantitas item
+	MenuID    primitive.ObjectID `json:"menu_id,omitempty" bson:"menu_id,omitempty"`
+	MenuName  string             `json:"menu_name,omitempty" bson:"menu_name,omitempty"`
+	Price     float64            `json:"price,omitempty" bson:"price,omitempty"`
+	Quantity  int                `json:"quantity,omitempty" bson:"quantity,omitempty"`   // Kuantitas item
+	Modifiers []SelectedModifier `json:"modifiers,omitempty" bson:"modifiers,omitempty"` // Opsi yang dipilih, harga item sudah termasuk selisih harganya
+	Station   string             `json:"station,omitempty" bson:"station,omitempty"`     // Stasiun yang menyiapkan item, dari kategori menu saat order dibuat
+	ReadyAt   time.Time          `json:"ready_at,omitempty" bson:"ready_at,omitempty"`   // Diisi saat item ditandai siap di layar stasiun
+	ReadyBy   string             `json:"ready_by,omitempty" bson:"ready_by,omitempty"`
 	// PriceFormatted string  `json:"price_formatted,omitempty" bson:"-"`
 }
+
+// PriceDiscrepancy struct untuk melaporkan harga item dari client yang berbeda dengan harga menu di database
+type PriceDiscrepancy struct {
+	MenuID      primitive.ObjectID `json:"menu_id"`
+	MenuName    string             `json:"menu_name"`
+	ClientPrice float64            `json:"client_price"` // Harga yang dikirim oleh frontend
+	ServerPrice float64            `json:"server_price"` // Harga menu yang berlaku saat order dibuat
+}
+
+// RolePermission daftar permission sebuah role, disimpan di koleksi role_permission dengan _id nama role.
+// Permission berbentuk "resource:aksi" (misalnya "menu:write"), "resource:*" atau "*" untuk semua.
+type RolePermission struct {
+	Role        string    `json:"role" bson:"_id"`
+	Permissions []string  `json:"permissions" bson:"permissions"`
+	UpdatedBy   string    `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
+
+// Jenis aksi pada audit log
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+)
+
+// AuditLog catatan satu perubahan data, disimpan di koleksi audit_log
+type AuditLog struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ActorID   primitive.ObjectID `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	Actor     string             `json:"actor" bson:"actor"` // Nama user yang melakukan perubahan
+	Role      string             `json:"role" bson:"role"`
+	Action    string             `json:"action" bson:"action"`       // create, update, delete atau restore
+	Entity    string             `json:"entity" bson:"entity"`       // Nama koleksi: menu, category, banner, orders
+	EntityID  primitive.ObjectID `json:"entity_id" bson:"entity_id"` // _id dokumen yang berubah
+	EntityKey string             `json:"entity_key,omitempty" bson:"entity_key,omitempty"` // _id string untuk dokumen tanpa ObjectID (role, pengaturan)
+	Changes   []AuditChange      `json:"changes,omitempty" bson:"changes,omitempty"`
+	IP        string             `json:"ip,omitempty" bson:"ip,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// AuditChange nilai satu field sebelum dan sesudah perubahan, Before kosong saat create dan After kosong saat delete
+type AuditChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before,omitempty" bson:"before,omitempty"`
+	After  interface{} `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// Table meja dine-in yang dikelola admin, disimpan di koleksi tables.
+// QR code meja berisi token bertanda tangan, TokenVersion dinaikkan untuk membatalkan QR lama.
+type Table struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Number       string             `json:"number" bson:"number"`                 // Nomor meja yang tercetak, unik
+	Area         string             `json:"area,omitempty" bson:"area,omitempty"` // Misalnya indoor, outdoor, lantai 2
+	Seats        int                `json:"seats,omitempty" bson:"seats,omitempty"`
+	Active       bool               `json:"active" bson:"active"` // Meja nonaktif tidak bisa menerima pesanan
+	TokenVersion int                `json:"token_version" bson:"token_version"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+	DeletedAt    time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"` // Diisi saat soft delete, QR meja ikut tidak berlaku
+	DeletedBy    string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+}
+
+// TableToken isi token QR meja, ditandatangani dengan watoken
+type TableToken struct {
+	TableID primitive.ObjectID `json:"table_id"`
+	Number  string             `json:"number"`
+	Version int                `json:"version"`
+}
+
+// PickupDay jam buka dan slot waktu ambil untuk satu hari dalam seminggu
+type PickupDay struct {
+	Weekday     int    `json:"weekday" bson:"weekday"` // 0 Minggu sampai 6 Sabtu
+	Closed      bool   `json:"closed" bson:"closed"`
+	Open        string `json:"open" bson:"open"`                 // Jam buka, format 15:04 (Asia/Jakarta)
+	Close       string `json:"close" bson:"close"`               // Jam tutup, slot terakhir harus selesai sebelum jam ini
+	SlotMinutes int    `json:"slot_minutes" bson:"slot_minutes"` // Panjang satu slot, misalnya 15 menit
+	MaxDrinks   int    `json:"max_drinks" bson:"max_drinks"`     // Maksimal minuman (item stasiun bar) per slot, 0 berarti tidak dibatasi
+}
+
+// PickupSchedule pengaturan pesanan terjadwal, satu dokumen di koleksi pickup_schedule
+type PickupSchedule struct {
+	ID           string      `json:"-" bson:"_id"`
+	Days         []PickupDay `json:"days" bson:"days"`
+	LeadMinutes  int         `json:"lead_minutes" bson:"lead_minutes"`     // Pesanan masuk antrean aktif sekian menit sebelum waktu ambil
+	MaxDaysAhead int         `json:"max_days_ahead" bson:"max_days_ahead"` // Batas hari ke depan untuk memesan, 0 berarti hanya hari ini
+	UpdatedBy    string      `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+	UpdatedAt    time.Time   `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
+
+// DeliveryZone area layanan antar yang digambar admin, disimpan di koleksi delivery_zone.
+// Area berupa GeoJSON Polygon ([long, lat]), titik pelanggan dicek dengan $geoIntersects.
+type DeliveryZone struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Area      Location           `json:"area" bson:"area"`
+	Active    bool               `json:"active" bson:"active"` // Zona nonaktif tidak menerima pesanan antar
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+	DeletedAt time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+}
+
+// DeliveryBand tarif ongkir sampai jarak tertentu dari toko
+type DeliveryBand struct {
+	MaxKm float64 `json:"max_km" bson:"max_km"`
+	Fee   float64 `json:"fee" bson:"fee"`
+}
+
+// DeliverySettings titik toko dan tarif ongkir per jarak, satu dokumen di koleksi delivery_settings
+type DeliverySettings struct {
+	ID        string         `json:"-" bson:"_id"`
+	Origin    LongLat        `json:"origin" bson:"origin"` // Lokasi toko, max_distance tidak dipakai
+	Bands     []DeliveryBand `json:"bands" bson:"bands"`   // Diurutkan dari jarak terdekat, di luar band terjauh ditolak
+	UpdatedBy string         `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+	UpdatedAt time.Time      `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
@@ -56,6 +56,7 @@ type Project struct {
 	RepoOrg              string             `bson:"repoorg,omitempty" json:"repoorg,omitempty"`
 	RepoLogName          string             `bson:"repologname,omitempty" json:"repologname,omitempty"`
 	CoverBuku            string             `bson:"coverbuku,omitempty" json:"coverbuku,omitempty"`
+	CoverBukuKey         string             `bson:"coverbukukey,omitempty" json:"coverbukukey,omitempty"` // Key file cover di storage, untuk menghapus cover lama saat diganti
 	DraftBuku            string             `bson:"draftbuku,omitempty" json:"draftbuku,omitempty"`
 	DraftPDFBuku         string             `bson:"draftpdfbuku,omitempty" json:"draftpdfbuku,omitempty"`
 	SampulPDFBuku        string             `bson:"sampulpdfbuku,omitempty" json:"sampulpdfbuku,omitempty"`
@@ -104,6 +105,7 @@ type Userdomyikado struct {
 	Poin                 float64            `bson:"poin,omitempty" json:"poin,omitempty"`
 	GoogleProfilePicture string             `bson:"googleprofilepicture,omitempty" json:"picture,omitempty"`
 	ProfilePicture       string             `bson:"profpic,omitempty" json:"profpic,omitempty"`
+	ProfilePictureKey    string             `bson:"profpickey,omitempty" json:"profpickey,omitempty"` // Key file foto profil di storage
 	Bio                  string             `bson:"bio,omitempty" json:"bio,omitempty"`
 	URLBio               string             `bson:"urlbio,omitempty" json:"urlbio,omitempty"`
 	PATHBio              string             `bson:"pathbio,omitempty" json:"pathbio,omitempty"`
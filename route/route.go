@@ -2,270 +2,274 @@ package route
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gocroot/config"
 	"github.com/gocroot/controller"
-	"github.com/gocroot/helper/at"
+	"github.com/gocroot/helper/rbac"
+	"github.com/gocroot/helper/router"
 )
 
+// Router tabel route aplikasi, route yang bentrok membuat panic saat package ini dimuat
+var Router = NewRouter()
+
 func URL(w http.ResponseWriter, r *http.Request) {
 	if config.SetAccessControlHeaders(w, r) {
 		return // If it's a preflight request, return early.
 	}
 	config.SetEnv()
 
-	var method, path string = r.Method, r.URL.Path
-	switch {
-	case method == "GET" && path == "/":
-		controller.GetHome(w, r)
+	Router.ServeHTTP(w, r)
+}
+
+// role membatasi route hanya untuk role tertentu
+func role(roles ...string) router.Middleware {
+	return controller.RoleMiddleware(roles)
+}
+
+// can membatasi route untuk role yang memiliki permission tersebut (lihat helper/rbac)
+func can(permissions ...string) router.Middleware {
+	return controller.PermissionMiddleware(permissions...)
+}
+
+func NewRouter() *router.Router {
+	r := router.New()
+	r.NotFound = controller.NotFound
+	r.MethodNotAllowed = controller.MethodNotAllowed
+
+	// batas request untuk endpoint publik yang rawan disalahgunakan (kirim OTP, buat order)
+	authLimit := router.RateLimit(10, time.Minute)
+	orderLimit := router.RateLimit(30, time.Minute)
+	// login saja, pengecekan kepemilikan data dilakukan di handler
+	auth := router.Middleware(controller.AuthMiddleware)
+	// login atau token QR meja (header Table) untuk pesanan dine-in tanpa daftar
+	tableOrAuth := router.Middleware(controller.TableOrAuthMiddleware)
+	// token login dari cookie atau query untuk EventSource yang tidak bisa mengirim header
+	streamLogin := router.Middleware(controller.StreamLoginMiddleware)
+
+	r.GET("/", controller.GetHome)
+	//file gambar yang disimpan di backend storage local
+	r.GET("/files/*key", controller.ServeStoredFile)
 	//chat bot inbox
-	case method == "POST" && at.URLParam(path, "/webhook/nomor/:nomorwa"):
-		controller.PostInboxNomor(w, r)
+	r.POST("/webhook/nomor/:nomorwa", controller.PostInboxNomor)
 	//masking list nmor official
-	case method == "GET" && path == "/data/phone/all":
-		controller.GetBotList(w, r)
+	r.GET("/data/phone/all", controller.GetBotList)
 	//akses data helpdesk layanan user
-	case method == "GET" && path == "/data/user/helpdesk/all":
-		controller.GetHelpdeskAll(w, r)
-	case method == "GET" && path == "/data/user/helpdesk/masuk":
-		controller.GetLatestHelpdeskMasuk(w, r)
-	case method == "GET" && path == "/data/user/helpdesk/selesai":
-		controller.GetLatestHelpdeskSelesai(w, r)
+	r.GET("/data/user/helpdesk/all", controller.GetHelpdeskAll)
+	r.GET("/data/user/helpdesk/masuk", controller.GetLatestHelpdeskMasuk)
+	r.GET("/data/user/helpdesk/selesai", controller.GetLatestHelpdeskSelesai)
 	//pamong desa data from api
-	case method == "GET" && path == "/data/lms/user":
-		controller.GetDataUserFromApi(w, r)
+	r.GET("/data/lms/user", controller.GetDataUserFromApi)
 	//simpan testimoni dari pamong desa lms api
-	case method == "POST" && path == "/data/lms/testi":
-		controller.PostTestimoni(w, r)
-		//get random 4 testi
-	case method == "GET" && path == "/data/lms/random/testi":
-		controller.GetRandomTesti4(w, r)
+	r.POST("/data/lms/testi", controller.PostTestimoni)
+	//get random 4 testi
+	r.GET("/data/lms/random/testi", controller.GetRandomTesti4)
 	//mendapatkan data sent item
-	case method == "GET" && at.URLParam(path, "/data/peserta/sent/:id"):
-		controller.GetSentItem(w, r)
+	r.GET("/data/peserta/sent/:id", controller.GetSentItem)
 	//simpan feedback unsubs user
-	case method == "POST" && path == "/data/peserta/unsubscribe":
-		controller.PostUnsubscribe(w, r)
+	r.POST("/data/peserta/unsubscribe", controller.PostUnsubscribe)
 	//generate token linked device
-	case method == "PUT" && path == "/data/user":
-		controller.PutTokenDataUser(w, r)
+	r.PUT("/data/user", controller.PutTokenDataUser)
 	//Menambhahkan data nomor sender untuk broadcast
-	case method == "PUT" && path == "/data/sender":
-		controller.PutNomorBlast(w, r)
+	r.PUT("/data/sender", controller.PutNomorBlast)
 	//mendapatkan data list nomor sender untuk broadcast
-	case method == "GET" && path == "/data/sender":
-		controller.GetDataSenders(w, r)
+	r.GET("/data/sender", controller.GetDataSenders)
 	//mendapatkan data list nomor sender yang kena blokir dari broadcast
-	case method == "GET" && path == "/data/blokir":
-		controller.GetDataSendersTerblokir(w, r)
+	r.GET("/data/blokir", controller.GetDataSendersTerblokir)
 	//mendapatkan data rekap pengiriman wa blast
-	case method == "GET" && path == "/data/rekap":
-		controller.GetRekapBlast(w, r)
+	r.GET("/data/rekap", controller.GetRekapBlast)
 	//mendapatkan data faq
-	case method == "GET" && at.URLParam(path, "/data/faq/:id"):
-		controller.GetFAQ(w, r)
+	r.GET("/data/faq/:id", controller.GetFAQ)
 	//legacy
-	case method == "PUT" && path == "/data/user/task/doing":
-		controller.PutTaskUser(w, r)
-	case method == "GET" && path == "/data/user/task/done":
-		controller.GetTaskDone(w, r)
-	case method == "POST" && path == "/data/user/task/done":
-		controller.PostTaskUser(w, r)
-	case method == "GET" && path == "/data/pushrepo/kemarin":
-		controller.GetYesterdayDistincWAGroup(w, r)
+	r.PUT("/data/user/task/doing", controller.PutTaskUser)
+	r.GET("/data/user/task/done", controller.GetTaskDone)
+	r.POST("/data/user/task/done", controller.PostTaskUser)
+	r.GET("/data/pushrepo/kemarin", controller.GetYesterdayDistincWAGroup)
 
 	//helpdesk
 	//mendapatkan data tiket
-	case method == "GET" && at.URLParam(path, "/data/tiket/closed/:id"):
-		controller.GetClosedTicket(w, r)
+	r.GET("/data/tiket/closed/:id", controller.GetClosedTicket)
 	//simpan feedback tiket user
-	case method == "POST" && path == "/data/tiket/rate":
-		controller.PostMasukanTiket(w, r)
+	r.POST("/data/tiket/rate", controller.PostMasukanTiket)
 
-		// orders.go
-	case method == "POST" && at.URLParam(path, "/data/order/:namalapak"):
-		controller.HandleOrder(w, r)
+	//callback payment gateway
+	r.POST("/webhook/payment/:provider", controller.PaymentCallback)
+
+	// orders.go, order dari halaman lapak format lama
+	r.POST("/data/order/:namalapak", controller.HandleOrder, orderLimit)
 
 	//user data
-	case method == "GET" && path == "/data/user":
-		controller.GetDataUser(w, r)
-	case method == "GET" && path == "/data/users":
-		controller.GetAllDataUsers(w, r)
+	r.GET("/data/user", controller.GetDataUser)
+	r.GET("/data/users", controller.GetAllDataUsers, can(rbac.UserRead))
+	r.GET("/data/user/points", controller.GetUserPoints, auth)
 
 	//user pendaftaran
-	case method == "POST" && path == "/auth/register/users": //mendapatkan email gmail
-		controller.RegisterGmailAuth(w, r)
-	case method == "POST" && path == "/data/user":
-		controller.PostDataUser(w, r)
-	case method == "POST" && path == "/upload/profpic": //upload gambar profile
-		controller.UploadProfilePictureHandler(w, r)
-	case method == "POST" && path == "/data/user/bio":
-		controller.PostDataBioUser(w, r)
-		/* 	case method == "POST" && at.URLParam(path, "/data/user/wa/:nomorwa"):
-		controller.PostDataUserFromWA(w, r) */
-
-		// pendaftaran user secara manual
-	case method == "POST" && path == "/auth/register":
-		controller.RegisterUser(w, r)
-	// case method == "POST" && path == "/auth/login":
-	// 	controller.LoginUser(w, r)
+	r.POST("/auth/register/users", controller.RegisterGmailAuth) //mendapatkan email gmail
+	r.POST("/data/user", controller.PostDataUser)
+	r.POST("/upload/profpic", controller.UploadProfilePictureHandler) //upload gambar profile
+	r.POST("/data/user/bio", controller.PostDataBioUser)
+	// r.POST("/data/user/wa/:nomorwa", controller.PostDataUserFromWA)
+
+	// pendaftaran user secara manual
+	r.POST("/auth/register", controller.RegisterUser, authLimit)
+	// r.POST("/auth/login", controller.LoginUser)
 
 	//data proyek
-	case method == "GET" && path == "/data/proyek":
-		controller.GetDataProject(w, r)
-	case method == "GET" && path == "/data/proyek/approved": //akses untuk manager
-		controller.GetEditorApprovedProject(w, r)
-	case method == "POST" && path == "/data/proyek":
-		controller.PostDataProject(w, r)
-	case method == "PUT" && path == "/data/metadatabuku":
-		controller.PutMetaDataProject(w, r)
-	case method == "PUT" && path == "/data/proyek/publishbuku": //publish buku isbn by manager
-		controller.PutPublishProject(w, r)
-	case method == "PUT" && path == "/data/proyek":
-		controller.PutDataProject(w, r)
-	case method == "DELETE" && path == "/data/proyek":
-		controller.DeleteDataProject(w, r)
-	case method == "GET" && path == "/data/proyek/anggota":
-		controller.GetDataMemberProject(w, r)
-	case method == "GET" && path == "/data/proyek/editor":
-		controller.GetDataEditorProject(w, r)
-	case method == "DELETE" && path == "/data/proyek/anggota":
-		controller.DeleteDataMemberProject(w, r)
-	case method == "POST" && path == "/data/proyek/anggota":
-		controller.PostDataMemberProject(w, r)
-	case method == "POST" && path == "/data/proyek/editor": //set editor oleh owner
-		controller.PostDataEditorProject(w, r)
-	case method == "PUT" && path == "/data/proyek/editor": //set approved oleh editor
-		controller.PUtApprovedEditorProject(w, r)
+	r.GET("/data/proyek", controller.GetDataProject)
+	r.GET("/data/proyek/approved", controller.GetEditorApprovedProject) //akses untuk manager
+	r.POST("/data/proyek", controller.PostDataProject)
+	r.PUT("/data/metadatabuku", controller.PutMetaDataProject)
+	r.PUT("/data/proyek/publishbuku", controller.PutPublishProject) //publish buku isbn by manager
+	r.PUT("/data/proyek", controller.PutDataProject)
+	r.DELETE("/data/proyek", controller.DeleteDataProject)
+	r.GET("/data/proyek/anggota", controller.GetDataMemberProject)
+	r.GET("/data/proyek/editor", controller.GetDataEditorProject)
+	r.DELETE("/data/proyek/anggota", controller.DeleteDataMemberProject)
+	r.POST("/data/proyek/anggota", controller.PostDataMemberProject)
+	r.POST("/data/proyek/editor", controller.PostDataEditorProject)   //set editor oleh owner
+	r.PUT("/data/proyek/editor", controller.PUtApprovedEditorProject) //set approved oleh editor
 	//upload cover,draft,pdf,sampul buku project
-	case method == "POST" && at.URLParam(path, "/upload/coverbuku/:projectid"):
-		controller.UploadCoverBukuWithParamFileHandler(w, r)
-	case method == "POST" && at.URLParam(path, "/upload/draftbuku/:projectid"):
-		controller.UploadDraftBukuWithParamFileHandler(w, r)
-	case method == "POST" && at.URLParam(path, "/upload/draftpdfbuku/:projectid"):
-		controller.UploadDraftBukuPDFWithParamFileHandler(w, r)
-	case method == "POST" && at.URLParam(path, "/upload/sampulpdfbuku/:projectid"):
-		controller.UploadSampulBukuPDFWithParamFileHandler(w, r)
-	case method == "POST" && at.URLParam(path, "/upload/spk/:projectid"):
-		controller.UploadSPKPDFWithParamFileHandler(w, r)
-	case method == "POST" && at.URLParam(path, "/upload/spi/:projectid"):
-		controller.UploadSPIPDFWithParamFileHandler(w, r)
-	case method == "GET" && at.URLParam(path, "/download/draft/:path"): //downoad file draft
-		controller.AksesFileRepoDraft(w, r)
-	case method == "POST" && path == "/data/proyek/katalog": //post blog katalog
-		controller.PostKatalogBuku(w, r)
-	case method == "GET" && at.URLParam(path, "/download/dokped/spk/:namaproject"): //base64 namaproject
-		controller.GetFileDraftSPK(w, r)
-	case method == "GET" && at.URLParam(path, "/download/dokped/spkt/:namaproject"): //base64 namaproject
-		controller.GetFileDraftSPKT(w, r)
-	case method == "GET" && at.URLParam(path, "/download/dokped/spi/:path"): //base64 path sampul
-		controller.GetFileDraftSPI(w, r)
-
-	case method == "POST" && path == "/data/proyek/menu":
-		controller.PostDataMenuProject(w, r)
-	case method == "POST" && path == "/approvebimbingan":
-		controller.ApproveBimbinganbyPoin(w, r)
-	case method == "DELETE" && path == "/data/proyek/menu":
-		controller.DeleteDataMenuProject(w, r)
-	case method == "POST" && path == "/notif/ux/postlaporan":
-		controller.PostLaporan(w, r)
-	case method == "POST" && path == "/notif/ux/postfeedback":
-		controller.PostFeedback(w, r)
-
-	case method == "POST" && path == "/notif/ux/postmeeting":
-		controller.PostMeeting(w, r)
-	case method == "POST" && at.URLParam(path, "/notif/ux/postpresensi/:id"):
-		controller.PostPresensi(w, r)
-	case method == "POST" && at.URLParam(path, "/notif/ux/posttasklists/:id"):
-		controller.PostTaskList(w, r)
-	case method == "POST" && at.URLParam(path, "/webhook/nomor/:nomorwa"):
-		controller.PostInboxNomor(w, r)
+	r.POST("/upload/coverbuku/:projectid", controller.UploadCoverBukuWithParamFileHandler)
+	r.POST("/upload/draftbuku/:projectid", controller.UploadDraftBukuWithParamFileHandler)
+	r.POST("/upload/draftpdfbuku/:projectid", controller.UploadDraftBukuPDFWithParamFileHandler)
+	r.POST("/upload/sampulpdfbuku/:projectid", controller.UploadSampulBukuPDFWithParamFileHandler)
+	r.POST("/upload/spk/:projectid", controller.UploadSPKPDFWithParamFileHandler)
+	r.POST("/upload/spi/:projectid", controller.UploadSPIPDFWithParamFileHandler)
+	r.GET("/download/draft/:path", controller.AksesFileRepoDraft)            //downoad file draft
+	r.POST("/data/proyek/katalog", controller.PostKatalogBuku)               //post blog katalog
+	r.GET("/download/dokped/spk/:namaproject", controller.GetFileDraftSPK)   //base64 namaproject
+	r.GET("/download/dokped/spkt/:namaproject", controller.GetFileDraftSPKT) //base64 namaproject
+	r.GET("/download/dokped/spi/:path", controller.GetFileDraftSPI)          //base64 path sampul
+
+	r.POST("/data/proyek/menu", controller.PostDataMenuProject)
+	r.POST("/approvebimbingan", controller.ApproveBimbinganbyPoin)
+	r.DELETE("/data/proyek/menu", controller.DeleteDataMenuProject)
+	r.POST("/notif/ux/postlaporan", controller.PostLaporan)
+	r.POST("/notif/ux/postfeedback", controller.PostFeedback)
+
+	r.POST("/notif/ux/postmeeting", controller.PostMeeting)
+	r.POST("/notif/ux/postpresensi/:id", controller.PostPresensi)
+	r.POST("/notif/ux/posttasklists/:id", controller.PostTaskList)
 	// LMS
-	case method == "GET" && path == "/lms/refresh/cookie":
-		controller.RefreshLMSCookie(w, r)
-	case method == "GET" && path == "/lms/count/user":
-		controller.GetCountDocUser(w, r)
+	r.GET("/lms/refresh/cookie", controller.RefreshLMSCookie)
+	r.GET("/lms/count/user", controller.GetCountDocUser)
 	// Google Auth
-	case method == "POST" && path == "/auth/users":
-		controller.Auth(w, r)
-	case method == "POST" && path == "/auth/signin":
-		controller.GeneratePasswordHandler(w, r)
-	case method == "POST" && path == "/auth/verify":
-		controller.VerifyPasswordHandler(w, r)
-	case method == "POST" && path == "/auth/resend":
-		controller.ResendPasswordHandler(w, r)
+	r.POST("/auth/users", controller.Auth)
+	r.POST("/auth/signin", controller.GeneratePasswordHandler, authLimit)
+	r.POST("/auth/verify", controller.VerifyPasswordHandler, authLimit)
+	r.POST("/auth/resend", controller.ResendPasswordHandler, authLimit)
 
 	// middleware
-	case method == "POST" && path == "/menu":
-		controller.RoleMiddleware([]string{"user", "dosen"})(controller.MenuHandler)(w, r)
-	case method == "POST" && path == "/dashboard-admin":
-		controller.RoleMiddleware([]string{"admin"})(controller.AdminHandler)(w, r)
-	case method == "POST" && path == "/dashboard-cashier":
-		controller.RoleMiddleware([]string{"cashier"})(controller.CashierHandler)(w, r)
-
-		// Update user role
-	case method == "PUT" && path == "/updateUserRole":
-		controller.UpdateUserRole(w, r)
-
-		// Category routes
-	case method == "GET" && path == "/data/category":
-		controller.GetAllCategory(w, r)
-	case method == "GET" && at.URLParam(path, "/data/category/:id"):
-		controller.GetCategoryByID(w, r)
-	case method == "POST" && path == "/data/category":
-		controller.CreateCategory(w, r)
-	case method == "PUT" && at.URLParam(path, "/data/category/:id"):
-		controller.UpdateCategory(w, r)
-	case method == "DELETE" && at.URLParam(path, "/data/category/:id"):
-		controller.DeleteCategory(w, r)
-
-		// Menu routes
-	case method == "GET" && path == "/data/menu":
-		controller.GetAllMenu(w, r)
-	case method == "GET" && at.URLParam(path, "/data/menu/:id"):
-		controller.GetMenuByID(w, r)
-	case method == "POST" && path == "/data/menu":
-		controller.CreateMenu(w, r)
-	case method == "PUT" && at.URLParam(path, "/data/menu/:id"):
-		controller.UpdateMenu(w, r)
-	case method == "DELETE" && at.URLParam(path, "/data/menu/:id"):
-		controller.DeleteMenu(w, r)
-
-		// Order routes
-	case method == "GET" && path == "/data/orders":
-		controller.GetAllOrder(w, r)
-	case method == "GET" && at.URLParam(path, "/data/order/:id"):
-		controller.GetOrderByID(w, r)
-	case method == "GET" && path == "/data/order":
-		controller.GetOrderByUserID(w, r)	
-	case method == "POST" && path == "/data/order":
-		controller.CreateOrder(w, r)
-	case method == "PUT" && at.URLParam(path, "/data/order/:id"):
-		controller.UpdateOrder(w, r)
-	case method == "DELETE" && at.URLParam(path, "/data/order/:id"):
-		controller.DeleteOrder(w, r)
-
-		// gis
-	case method == "POST" && path == "/data/roads":
-		controller.GetRoads(w, r)
-	case method == "POST" && path == "/data/region":
-		controller.GetRegion(w, r)
+	r.POST("/menu", controller.MenuHandler, role("user", "dosen"))
+	r.POST("/dashboard-admin", controller.AdminHandler, role("admin"))
+	r.POST("/dashboard-cashier", controller.CashierHandler, role("cashier"))
+
+	// Update user role dan permission role
+	r.PUT("/updateUserRole", controller.UpdateUserRole, can(rbac.UserRole))
+	r.GET("/data/roles", controller.GetRolePermissions, can(rbac.UserRole))
+	r.PUT("/data/role/:role", controller.UpdateRolePermissions, can(rbac.UserRole))
+
+	// Category routes
+	r.GET("/data/category", controller.GetAllCategory)
+	r.GET("/data/category/:id", controller.GetCategoryByID)
+	r.POST("/data/category", controller.CreateCategory, can(rbac.CategoryWrite))
+	r.PUT("/data/category/:id", controller.UpdateCategory, can(rbac.CategoryWrite))
+	r.POST("/data/category/:id/image", controller.UploadCategoryImage, can(rbac.CategoryWrite))
+	r.DELETE("/data/category/:id", controller.DeleteCategory, can(rbac.CategoryWrite))
+	r.POST("/data/category/:id/restore", controller.RestoreCategory, can(rbac.CategoryWrite))
+
+	// Menu routes
+	r.GET("/data/menu", controller.GetAllMenu)
+	r.GET("/data/menu/search", controller.SearchMenu)
+	r.GET("/data/menu/:id", controller.GetMenuByID)
+	r.POST("/data/menu", controller.CreateMenu, can(rbac.MenuWrite))
+	r.PUT("/data/menu/:id/modifiers", controller.UpdateMenuModifiers, can(rbac.MenuWrite))
+	r.PUT("/data/menu/:id/recipe", controller.UpdateMenuRecipe, can(rbac.MenuWrite))
+	r.PUT("/data/menu/:id", controller.UpdateMenu, can(rbac.MenuWrite))
+	r.DELETE("/data/menu/:id", controller.DeleteMenu, can(rbac.MenuWrite))
+	r.POST("/data/menu/:id/restore", controller.RestoreMenu, can(rbac.MenuWrite))
+
+	// Inventory routes
+	r.GET("/data/ingredients", controller.GetAllIngredient, can(rbac.InventoryRead))
+	r.POST("/data/ingredient", controller.CreateIngredient, can(rbac.InventoryWrite))
+	r.POST("/data/ingredient/:id/adjust", controller.AdjustIngredientStock, can(rbac.InventoryAdjust))
+	r.GET("/data/ingredient/:id/movements", controller.GetIngredientMovements, can(rbac.InventoryRead))
+	r.PUT("/data/ingredient/:id", controller.UpdateIngredient, can(rbac.InventoryWrite))
+
+	// Order routes
+	r.GET("/data/orders", controller.GetAllOrder, can(rbac.OrderRead))
+	r.GET("/data/orders/stream", controller.StreamOrders, streamLogin, can(rbac.OrderRead))
+	r.GET("/data/order/:id/history", controller.GetOrderHistory, auth)
+	r.GET("/data/order/:id/payment", controller.GetOrderPayment, auth)
+	r.GET("/data/order/:id/receipt", controller.GetOrderReceipt, auth)
+	r.GET("/data/order/number/:ordernumber", controller.GetOrderByNumber, auth)
+	r.GET("/data/order/:id", controller.GetOrderByID, auth)
+	r.GET("/data/order", controller.GetOrderByUserID, auth)
+	r.POST("/data/order", controller.CreateOrder, orderLimit, tableOrAuth)
+	r.PUT("/data/order/:id", controller.UpdateOrder, auth)
+	r.DELETE("/data/order/:id", controller.DeleteOrder, can(rbac.OrderDelete))
+
+	// voucher & promo
+	r.GET("/data/vouchers", controller.GetAllVoucher, can(rbac.VoucherManage))
+	r.POST("/data/voucher", controller.CreateVoucher, can(rbac.VoucherManage))
+	r.GET("/data/voucher/:id/stats", controller.GetVoucherStats, can(rbac.VoucherManage))
+	r.GET("/data/voucher/:id", controller.GetVoucherByID, can(rbac.VoucherManage))
+	r.PUT("/data/voucher/:id", controller.UpdateVoucher, can(rbac.VoucherManage))
+	r.DELETE("/data/voucher/:id", controller.DeleteVoucher, can(rbac.VoucherManage))
+	r.POST("/data/voucher/:id/restore", controller.RestoreVoucher, can(rbac.VoucherManage))
+
+	// laporan penjualan
+	r.GET("/data/report/sales", controller.GetSalesReport, can(rbac.ReportRead))
+	r.GET("/data/report/sales/export", controller.ExportSalesReport, can(rbac.ReportRead))
+
+	// gis
+	r.POST("/data/roads", controller.GetRoads)
+	r.POST("/data/region", controller.GetRegion)
 
 	// Banner routes
-	case method == "GET" && path == "/data/banner":
-		controller.GetAllBanner(w, r)
-	case method == "GET" && at.URLParam(path, "/data/banner/:id"):
-		controller.GetBannerByID(w, r)
-	case method == "POST" && path == "/data/banner":
-		controller.CreateBanner(w, r)
-	case method == "PUT" && path == "/data/banner":
-		controller.UpdateBanner(w, r)
-	case method == "DELETE" && at.URLParam(path, "/data/banner/:id"):
-		controller.DeleteBanner(w, r)
+	r.GET("/data/banner", controller.GetAllBanner)
+	r.GET("/data/banner/:id", controller.GetBannerByID)
+	r.POST("/data/banner", controller.CreateBanner, can(rbac.BannerWrite))
+	r.PUT("/data/banner", controller.UpdateBanner, can(rbac.BannerWrite))
+	r.POST("/data/banner/:id/image", controller.UploadBannerImage, can(rbac.BannerWrite))
+	r.DELETE("/data/banner/:id", controller.DeleteBanner, can(rbac.BannerWrite))
+	r.POST("/data/banner/:id/restore", controller.RestoreBanner, can(rbac.BannerWrite))
 
-	// Google Auth
-	default:
-		controller.NotFound(w, r)
-	}
+	// pesanan terjadwal: slot waktu ambil untuk pelanggan dan pengaturan jadwal untuk admin
+	r.GET("/data/pickup/slots", controller.GetPickupSlots)
+	r.GET("/data/pickup/schedule", controller.GetPickupSchedule, can(rbac.PickupManage))
+	r.PUT("/data/pickup/schedule", controller.UpdatePickupSchedule, can(rbac.PickupManage))
+
+	// pesanan antar: cek area dan ongkir untuk pelanggan, zona dan tarif untuk admin
+	r.POST("/data/delivery/quote", controller.QuoteDelivery)
+	r.GET("/data/delivery/settings", controller.GetDeliverySettings, can(rbac.DeliveryManage))
+	r.PUT("/data/delivery/settings", controller.UpdateDeliverySettings, can(rbac.DeliveryManage))
+	r.GET("/data/delivery/zones", controller.GetAllDeliveryZone, can(rbac.DeliveryManage))
+	r.POST("/data/delivery/zone", controller.CreateDeliveryZone, can(rbac.DeliveryManage))
+	r.GET("/data/delivery/zone/:id", controller.GetDeliveryZoneByID, can(rbac.DeliveryManage))
+	r.PUT("/data/delivery/zone/:id", controller.UpdateDeliveryZone, can(rbac.DeliveryManage))
+	r.DELETE("/data/delivery/zone/:id", controller.DeleteDeliveryZone, can(rbac.DeliveryManage))
+	r.POST("/data/delivery/zone/:id/restore", controller.RestoreDeliveryZone, can(rbac.DeliveryManage))
+
+	// layar stasiun (kitchen display) dan tanda siap per item pesanan
+	r.GET("/data/kitchen/:station", controller.GetStationTickets, can(rbac.KitchenRead))
+	r.POST("/data/order/:id/item/:index/ready", controller.BumpOrderItem, can(rbac.KitchenBump))
+	r.DELETE("/data/order/:id/item/:index/ready", controller.UnbumpOrderItem, can(rbac.KitchenBump))
+
+	// meja dine-in dan QR meja untuk pesan tanpa login
+	r.GET("/data/tables", controller.GetAllTable, can(rbac.TableManage))
+	r.GET("/data/tables/open", controller.GetOpenTables, can(rbac.OrderRead))
+	r.POST("/data/table", controller.CreateTable, can(rbac.TableManage))
+	r.GET("/data/table/:id", controller.GetTableByID, can(rbac.TableManage))
+	r.PUT("/data/table/:id", controller.UpdateTable, can(rbac.TableManage))
+	r.DELETE("/data/table/:id", controller.DeleteTable, can(rbac.TableManage))
+	r.POST("/data/table/:id/restore", controller.RestoreTable, can(rbac.TableManage))
+	r.GET("/data/table/:id/qr", controller.GetTableQR, can(rbac.TableManage))
+	r.POST("/data/table/:id/qr", controller.RegenerateTableQR, can(rbac.TableManage))
+	r.POST("/data/table/:id/close", controller.CloseTable, can(rbac.OrderAdvance))
+
+	// audit log perubahan menu, kategori, banner dan pesanan
+	r.GET("/data/audit", controller.GetAuditLogs, can(rbac.AuditRead))
 
+	return r
 }
@@ -0,0 +1,35 @@
+// Command migrateorder menyalin order format lama (koleksi order, dari endpoint jualin) ke koleksi orders
+// supaya laporan dan dashboard hanya membaca satu sumber data. Aman dijalankan ulang.
+//
+//	MONGOSTRING=... go run ./run/migrateorder -dry-run
+//	MONGOSTRING=... go run ./run/migrateorder
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/jualin"
+	"github.com/gocroot/model"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "hanya hitung order yang akan disalin tanpa menulis ke database")
+	status := flag.String("status", model.OrderStatusSelesai, "status untuk order lama, format lama tidak menyimpan status")
+	paymentStatus := flag.String("payment-status", model.PaymentStatusLunas, "status pembayaran untuk order lama")
+	flag.Parse()
+
+	if config.ErrorMongoconn != nil {
+		log.Fatal("Gagal koneksi MongoDB: ", config.ErrorMongoconn)
+	}
+	migrated, skipped, err := jualin.Migrate(config.Mongoconn, *status, *paymentStatus, *dryRun)
+	if err != nil {
+		log.Fatalf("Migrasi berhenti setelah %d order disalin: %v", migrated, err)
+	}
+	if *dryRun {
+		log.Printf("Dry run: %d order akan disalin, %d sudah pernah disalin", migrated, skipped)
+		return
+	}
+	log.Printf("Migrasi selesai: %d order disalin, %d sudah pernah disalin", migrated, skipped)
+}